@@ -7,15 +7,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"coffee-shop-pos/configs"
 	httpdelivery "coffee-shop-pos/internal/delivery/http"
 	"coffee-shop-pos/internal/delivery/http/handler"
+	"coffee-shop-pos/internal/domain"
 	"coffee-shop-pos/internal/repository/postgres"
 	"coffee-shop-pos/internal/usecase"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -36,20 +39,161 @@ func main() {
 	// Initialize Repository
 	menuRepo := postgres.NewMenuItemRepository(db)
 	orderRepo := postgres.NewOrderRepository(db)
+	inventoryRepo := postgres.NewInventoryRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	customerRepo := postgres.NewCustomerRepository(db)
+	loyaltyRepo := postgres.NewLoyaltyRepository(db)
+	stampRepo := postgres.NewStampRepository(db)
+	giftCardRepo := postgres.NewGiftCardRepository(db)
+	paymentRepo := postgres.NewPaymentRepository(db)
+	staffRepo := postgres.NewStaffRepository(db)
+	terminalRepo := postgres.NewTerminalRepository(db)
+	overrideRepo := postgres.NewOverrideRepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+
+	loyaltyConfig, err := usecase.ParseLoyaltyConfig(cfg.LoyaltyPointsPerUnit, cfg.LoyaltyPointValue, cfg.LoyaltyExcludedCategories)
+	if err != nil {
+		log.Fatalf("Invalid loyalty configuration: %v", err)
+	}
+	giftCardValidityDays, err := strconv.Atoi(cfg.GiftCardValidityDays)
+	if err != nil || giftCardValidityDays < 0 {
+		log.Fatalf("Invalid GIFT_CARD_VALIDITY_DAYS %q", cfg.GiftCardValidityDays)
+	}
+	if len(cfg.JWTSecret) < 32 {
+		log.Fatalf("JWT_SECRET must be set to at least 32 bytes")
+	}
+	accessTTL, err := time.ParseDuration(cfg.JWTAccessTTL)
+	if err != nil || accessTTL <= 0 {
+		log.Fatalf("Invalid JWT_ACCESS_TTL %q", cfg.JWTAccessTTL)
+	}
+	refreshTTL, err := time.ParseDuration(cfg.JWTRefreshTTL)
+	if err != nil || refreshTTL <= 0 {
+		log.Fatalf("Invalid JWT_REFRESH_TTL %q", cfg.JWTRefreshTTL)
+	}
+	sessionIdleTimeout, err := time.ParseDuration(cfg.TerminalSessionIdleTimeout)
+	if err != nil || sessionIdleTimeout <= 0 {
+		log.Fatalf("Invalid TERMINAL_SESSION_IDLE_TIMEOUT %q", cfg.TerminalSessionIdleTimeout)
+	}
+	sessionMaxAge, err := time.ParseDuration(cfg.TerminalSessionMaxAge)
+	if err != nil || sessionMaxAge <= 0 {
+		log.Fatalf("Invalid TERMINAL_SESSION_MAX_AGE %q", cfg.TerminalSessionMaxAge)
+	}
+	pinMaxAttempts, err := strconv.Atoi(cfg.PINMaxAttempts)
+	if err != nil || pinMaxAttempts <= 0 {
+		log.Fatalf("Invalid PIN_MAX_ATTEMPTS %q", cfg.PINMaxAttempts)
+	}
+	pinLockout, err := time.ParseDuration(cfg.PINLockout)
+	if err != nil || pinLockout <= 0 {
+		log.Fatalf("Invalid PIN_LOCKOUT %q", cfg.PINLockout)
+	}
+	approvalTokenTTL, err := time.ParseDuration(cfg.ApprovalTokenTTL)
+	if err != nil || approvalTokenTTL <= 0 {
+		log.Fatalf("Invalid APPROVAL_TOKEN_TTL %q", cfg.ApprovalTokenTTL)
+	}
+	largeDiscountThreshold, err := decimal.NewFromString(cfg.LargeDiscountThreshold)
+	if err != nil || largeDiscountThreshold.IsNegative() || largeDiscountThreshold.GreaterThan(decimal.NewFromInt(1)) {
+		log.Fatalf("Invalid LARGE_DISCOUNT_THRESHOLD %q", cfg.LargeDiscountThreshold)
+	}
+	orderNumbering, err := usecase.ParseOrderNumberConfig(cfg.StoreCode, cfg.StoreTimezone, cfg.BusinessDayStart)
+	if err != nil {
+		log.Fatalf("Invalid order numbering configuration: %v", err)
+	}
+	orderTypeRules := make(map[string]usecase.OrderTypeRule)
+	for orderType, rates := range map[string][2]string{
+		domain.OrderTypeDineIn:   {cfg.TaxRateDineIn, cfg.ServiceChargeDineIn},
+		domain.OrderTypeTakeaway: {cfg.TaxRateTakeaway, cfg.ServiceChargeTakeaway},
+		domain.OrderTypeDelivery: {cfg.TaxRateDelivery, cfg.ServiceChargeDelivery},
+	} {
+		rule, err := usecase.ParseOrderTypeRule(rates[0], rates[1])
+		if err != nil {
+			log.Fatalf("Invalid %s charges: %v", orderType, err)
+		}
+		orderTypeRules[orderType] = rule
+	}
+	preorders, err := usecase.ParsePreorderConfig(cfg.StoreOpeningHours, cfg.PreorderSlotCapacity, cfg.PreorderSlotCategories, cfg.PreorderLeadTime)
+	if err != nil {
+		log.Fatalf("Invalid pre-order configuration: %v", err)
+	}
+	idempotencyKeyTTL, err := time.ParseDuration(cfg.IdempotencyKeyTTL)
+	if err != nil || idempotencyKeyTTL <= 0 {
+		log.Fatalf("Invalid IDEMPOTENCY_KEY_TTL %q", cfg.IdempotencyKeyTTL)
+	}
 
 	// Initialize Usecase
-	menuUsecase := usecase.NewMenuUsecase(menuRepo)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo, menuRepo)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	menuUsecase := usecase.NewMenuUsecase(menuRepo, usecase.WithMenuAudit(auditUsecase))
+	loyaltyUsecase := usecase.NewLoyaltyUsecase(loyaltyRepo, customerRepo, menuRepo, loyaltyConfig)
+	stampUsecase := usecase.NewStampUsecase(stampRepo, customerRepo, menuRepo)
+	giftCardUsecase := usecase.NewGiftCardUsecase(giftCardRepo, menuRepo, time.Duration(giftCardValidityDays)*24*time.Hour)
+	overrideUsecase := usecase.NewOverrideUsecase(overrideRepo, staffRepo, usecase.OverrideConfig{
+		TokenTTL:       approvalTokenTTL,
+		PINMaxAttempts: pinMaxAttempts,
+		PINLockout:     pinLockout,
+	})
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, menuRepo,
+		usecase.WithInventoryRepository(inventoryRepo),
+		usecase.WithCustomerRepository(customerRepo),
+		usecase.WithLoyaltyUsecase(loyaltyUsecase),
+		usecase.WithStampUsecase(stampUsecase),
+		usecase.WithGiftCardUsecase(giftCardUsecase),
+		usecase.WithPaymentRepository(paymentRepo),
+		usecase.WithOverrideUsecase(overrideUsecase, largeDiscountThreshold),
+		usecase.WithAuditUsecase(auditUsecase),
+		usecase.WithOrderNumbering(orderNumbering),
+		usecase.WithOrderTypeRules(orderTypeRules),
+		usecase.WithPreorders(preorders),
+	)
+	paymentUsecase := usecase.NewPaymentUsecase(paymentRepo, orderRepo, giftCardRepo, orderUsecase)
+	inventoryUsecase := usecase.NewInventoryUsecase(inventoryRepo, menuRepo)
+	reportUsecase := usecase.NewReportUsecase(reportRepo)
+	customerUsecase := usecase.NewCustomerUsecase(customerRepo, orderRepo)
+	staffUsecase := usecase.NewStaffUsecase(staffRepo)
+	authUsecase := usecase.NewAuthUsecase(staffRepo, usecase.AuthConfig{
+		Secret:     []byte(cfg.JWTSecret),
+		AccessTTL:  accessTTL,
+		RefreshTTL: refreshTTL,
+	})
+	terminalUsecase := usecase.NewTerminalUsecase(terminalRepo, staffRepo, usecase.TerminalConfig{
+		SessionIdleTimeout: sessionIdleTimeout,
+		SessionMaxAge:      sessionMaxAge,
+		PINMaxAttempts:     pinMaxAttempts,
+		PINLockout:         pinLockout,
+	})
+
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(idempotencyRepo, idempotencyKeyTTL)
+
+	// Seed the first staff account so a fresh install can log in.
+	if cfg.BootstrapAdminUsername != "" {
+		if err := staffUsecase.EnsureAdmin(context.Background(), cfg.BootstrapAdminUsername, cfg.BootstrapAdminPassword); err != nil {
+			log.Fatalf("Could not create bootstrap admin: %v", err)
+		}
+	}
 
 	// Initialize Handler
 	menuHandler := handler.NewMenuHandler(menuUsecase)
 	orderHandler := handler.NewOrderHandler(orderUsecase)
+	inventoryHandler := handler.NewInventoryHandler(inventoryUsecase)
+	reportHandler := handler.NewReportHandler(reportUsecase)
+	customerHandler := handler.NewCustomerHandler(customerUsecase)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyUsecase)
+	stampHandler := handler.NewStampHandler(stampUsecase)
+	paymentHandler := handler.NewPaymentHandler(paymentUsecase)
+	giftCardHandler := handler.NewGiftCardHandler(giftCardUsecase)
+	authHandler := handler.NewAuthHandler(authUsecase)
+	staffHandler := handler.NewStaffHandler(staffUsecase)
+	terminalHandler := handler.NewTerminalHandler(terminalUsecase)
+	overrideHandler := handler.NewOverrideHandler(overrideUsecase)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUsecase)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
 
 	// Initialize Gin Engine
 	r := gin.Default()
 
 	// Setup Router (also registers global middleware)
-	httpdelivery.NewRouter(r, menuHandler, orderHandler)
+	httpdelivery.NewRouter(r, menuHandler, orderHandler, inventoryHandler, reportHandler, customerHandler, loyaltyHandler, stampHandler, paymentHandler, giftCardHandler, authHandler, staffHandler, terminalHandler, overrideHandler, apiKeyHandler, auditHandler, authUsecase, terminalUsecase, apiKeyUsecase, idempotencyUsecase)
 
 	// Use a custom http.Server with timeouts to protect against slow-loris
 	// and other slow-connection attacks.
@@ -62,6 +206,25 @@ func main() {
 		IdleTimeout:       60 * time.Second,
 	}
 
+	// Release pre-orders into the preparation queue as their lead time comes
+	// up. Checking every minute keeps well inside a pickup slot.
+	releaseCtx, stopReleases := context.WithCancel(context.Background())
+	defer stopReleases()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-releaseCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := orderUsecase.ReleaseDue(releaseCtx); err != nil {
+					log.Printf("Could not release pre-orders: %v", err)
+				}
+			}
+		}
+	}()
+
 	// Start server in a goroutine so we can listen for shutdown signals.
 	go func() {
 		log.Printf("Server starting on port %s", cfg.ServerPort)
@@ -75,6 +238,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopReleases()
 
 	// Give in-flight requests up to 10 seconds to complete.
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
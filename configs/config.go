@@ -16,6 +16,44 @@ type Config struct {
 	DBSSLMode  string
 	ServerPort string
 	GinMode    string
+
+	LoyaltyPointsPerUnit      string
+	LoyaltyPointValue         string
+	LoyaltyExcludedCategories string
+
+	GiftCardValidityDays string
+
+	JWTSecret              string
+	JWTAccessTTL           string
+	JWTRefreshTTL          string
+	BootstrapAdminUsername string
+	BootstrapAdminPassword string
+
+	TerminalSessionIdleTimeout string
+	TerminalSessionMaxAge      string
+	PINMaxAttempts             string
+	PINLockout                 string
+
+	ApprovalTokenTTL       string
+	LargeDiscountThreshold string
+
+	IdempotencyKeyTTL string
+
+	StoreCode        string
+	StoreTimezone    string
+	BusinessDayStart string
+
+	TaxRateDineIn         string
+	TaxRateTakeaway       string
+	TaxRateDelivery       string
+	ServiceChargeDineIn   string
+	ServiceChargeTakeaway string
+	ServiceChargeDelivery string
+
+	StoreOpeningHours      string
+	PreorderSlotCapacity   string
+	PreorderSlotCategories string
+	PreorderLeadTime       string
 }
 
 func LoadConfig() *Config {
@@ -33,6 +71,44 @@ func LoadConfig() *Config {
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
 		GinMode:    getEnv("GIN_MODE", "release"),
+
+		LoyaltyPointsPerUnit:      getEnv("LOYALTY_POINTS_PER_UNIT", "1"),
+		LoyaltyPointValue:         getEnv("LOYALTY_POINT_VALUE", "0.01"),
+		LoyaltyExcludedCategories: getEnv("LOYALTY_EXCLUDED_CATEGORIES", ""),
+
+		GiftCardValidityDays: getEnv("GIFT_CARD_VALIDITY_DAYS", "365"),
+
+		JWTSecret:              getEnv("JWT_SECRET", ""),
+		JWTAccessTTL:           getEnv("JWT_ACCESS_TTL", "15m"),
+		JWTRefreshTTL:          getEnv("JWT_REFRESH_TTL", "168h"),
+		BootstrapAdminUsername: getEnv("BOOTSTRAP_ADMIN_USERNAME", ""),
+		BootstrapAdminPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+
+		TerminalSessionIdleTimeout: getEnv("TERMINAL_SESSION_IDLE_TIMEOUT", "10m"),
+		TerminalSessionMaxAge:      getEnv("TERMINAL_SESSION_MAX_AGE", "12h"),
+		PINMaxAttempts:             getEnv("PIN_MAX_ATTEMPTS", "5"),
+		PINLockout:                 getEnv("PIN_LOCKOUT", "15m"),
+
+		ApprovalTokenTTL:       getEnv("APPROVAL_TOKEN_TTL", "2m"),
+		LargeDiscountThreshold: getEnv("LARGE_DISCOUNT_THRESHOLD", "0.20"),
+
+		IdempotencyKeyTTL: getEnv("IDEMPOTENCY_KEY_TTL", "24h"),
+
+		StoreCode:        getEnv("STORE_CODE", "A"),
+		StoreTimezone:    getEnv("STORE_TIMEZONE", "UTC"),
+		BusinessDayStart: getEnv("BUSINESS_DAY_START", "0h"),
+
+		TaxRateDineIn:         getEnv("TAX_RATE_DINE_IN", "0.10"),
+		TaxRateTakeaway:       getEnv("TAX_RATE_TAKEAWAY", "0.10"),
+		TaxRateDelivery:       getEnv("TAX_RATE_DELIVERY", "0.10"),
+		ServiceChargeDineIn:   getEnv("SERVICE_CHARGE_DINE_IN", "0"),
+		ServiceChargeTakeaway: getEnv("SERVICE_CHARGE_TAKEAWAY", "0"),
+		ServiceChargeDelivery: getEnv("SERVICE_CHARGE_DELIVERY", "0"),
+
+		StoreOpeningHours:      getEnv("STORE_OPENING_HOURS", "07:00-19:00"),
+		PreorderSlotCapacity:   getEnv("PREORDER_SLOT_CAPACITY", "10"),
+		PreorderSlotCategories: getEnv("PREORDER_SLOT_CATEGORIES", ""),
+		PreorderLeadTime:       getEnv("PREORDER_LEAD_TIME", "15m"),
 	}
 }
 
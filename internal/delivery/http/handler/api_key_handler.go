@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	APIKeyUsecase domain.APIKeyUsecase
+}
+
+type apiKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func NewAPIKeyHandler(u domain.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{APIKeyUsecase: u}
+}
+
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	key := &domain.APIKey{Name: req.Name, Scopes: req.Scopes}
+	if err := h.APIKeyUsecase.Create(c.Request.Context(), key); err != nil {
+		writeAPIKeyError(c, err, "Failed to create API key")
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.APIKeyUsecase.List(c.Request.Context())
+	if err != nil {
+		writeAPIKeyError(c, err, "Failed to fetch API keys")
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.APIKeyUsecase.Revoke(c.Request.Context(), id); err != nil {
+		writeAPIKeyError(c, err, "Failed to revoke API key")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeAPIKeyError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidAPIKeyName), errors.Is(err, usecase.ErrInvalidAPIKeyScope),
+		errors.Is(err, usecase.ErrAPIKeyNeedsScope):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyUsecase struct{ mock.Mock }
+
+func (m *mockAPIKeyUsecase) Authenticate(ctx context.Context, token string) (*domain.Identity, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Identity), args.Error(1)
+}
+func (m *mockAPIKeyUsecase) Create(ctx context.Context, key *domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+func (m *mockAPIKeyUsecase) List(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+func (m *mockAPIKeyUsecase) Revoke(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAPIKeyUsecase)
+	h := NewAPIKeyHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/api-keys", h.Create)
+
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(key *domain.APIKey) bool {
+		return key.Name == "Kiosk"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.APIKey).Key = "key_secret"
+	}).Return(nil)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(key *domain.APIKey) bool {
+		return key.Name == "Sync"
+	})).Return(usecase.ErrInvalidAPIKeyScope)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "Kiosk", "scopes": []string{"menu:read", "orders:write"}})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "key_secret")
+
+	body, _ = json.Marshal(map[string]interface{}{"name": "Sync", "scopes": []string{"everything"}})
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIKeyHandler_Revoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAPIKeyUsecase)
+	h := NewAPIKeyHandler(mockUsecase)
+	r := gin.Default()
+	r.DELETE("/api/v1/api-keys/:id", h.Revoke)
+
+	id := uuid.New()
+	missing := uuid.New()
+	mockUsecase.On("Revoke", mock.Anything, id).Return(nil)
+	mockUsecase.On("Revoke", mock.Anything, missing).Return(domain.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/api-keys/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/api-keys/"+missing.String(), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
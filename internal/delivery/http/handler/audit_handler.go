@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	AuditUsecase domain.AuditUsecase
+}
+
+func NewAuditHandler(u domain.AuditUsecase) *AuditHandler {
+	return &AuditHandler{AuditUsecase: u}
+}
+
+// List returns audit entries, newest first. from and to are inclusive dates
+// (YYYY-MM-DD).
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := domain.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor_id format"})
+			return
+		}
+		filter.ActorID = &id
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		id, err := uuid.Parse(entityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity_id format"})
+			return
+		}
+		filter.EntityID = &id
+	}
+	if from := c.Query("from"); from != "" {
+		day, err := time.Parse(reportDateLayout, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		filter.From = &day
+	}
+	if to := c.Query("to"); to != "" {
+		day, err := time.Parse(reportDateLayout, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		end := day.AddDate(0, 0, 1)
+		filter.To = &end
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := h.AuditUsecase.List(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
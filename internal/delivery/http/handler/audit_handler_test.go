@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAuditUsecase struct{ mock.Mock }
+
+func (m *mockAuditUsecase) Entry(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, action, entityType, entityID, before, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+func (m *mockAuditUsecase) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAuditUsecase)
+	h := NewAuditHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/audit", h.List)
+
+	entityID := uuid.New()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	mockUsecase.On("List", mock.Anything, domain.AuditFilter{
+		EntityType: domain.AuditEntityMenuItem,
+		EntityID:   &entityID,
+		From:       &from,
+		To:         &to,
+		Limit:      20,
+	}).Return([]domain.AuditEntry{{ID: uuid.New(), Action: domain.AuditMenuItemUpdate, EntityID: entityID}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?entity_type=menu_item&entity_id="+entityID.String()+"&from=2026-03-01&to=2026-03-01&limit=20", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), domain.AuditMenuItemUpdate)
+}
+
+func TestAuditHandler_List_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAuditHandler(new(mockAuditUsecase))
+	r := gin.Default()
+	r.GET("/api/v1/audit", h.List)
+
+	for _, query := range []string{"actor_id=nope", "from=yesterday", "limit=-1"} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
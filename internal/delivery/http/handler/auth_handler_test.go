@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAuthUsecase struct{ mock.Mock }
+
+func (m *mockAuthUsecase) Login(ctx context.Context, username, password string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, username, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *mockAuthUsecase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *mockAuthUsecase) Authenticate(ctx context.Context, accessToken string) (*domain.Identity, error) {
+	args := m.Called(ctx, accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Identity), args.Error(1)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAuthUsecase)
+	h := NewAuthHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", h.Login)
+
+	mockUsecase.On("Login", mock.Anything, "sam", "password1").Return(&domain.TokenPair{AccessToken: "a", RefreshToken: "r", TokenType: "Bearer"}, nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "sam", "password": "password1"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tokens domain.TokenPair
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	assert.Equal(t, "a", tokens.AccessToken)
+}
+
+func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAuthUsecase)
+	h := NewAuthHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", h.Login)
+
+	mockUsecase.On("Login", mock.Anything, "sam", "wrong").Return(nil, domain.ErrInvalidCredentials)
+
+	body, _ := json.Marshal(map[string]string{"username": "sam", "password": "wrong"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthHandler_Refresh_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockAuthUsecase)
+	h := NewAuthHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/auth/refresh", h.Refresh)
+
+	mockUsecase.On("Refresh", mock.Anything, "expired").Return(nil, domain.ErrInvalidToken)
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": "expired"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthHandler_Me(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAuthHandler(new(mockAuthUsecase))
+	r := gin.Default()
+	identity := &domain.Identity{StaffID: uuid.New(), Username: "sam"}
+	r.GET("/api/v1/auth/me", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(domain.WithIdentity(c.Request.Context(), identity))
+		h.Me(c)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), identity.StaffID.String())
+}
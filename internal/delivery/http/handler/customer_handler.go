@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerHandler struct {
+	CustomerUsecase domain.CustomerUsecase
+}
+
+type customerRequest struct {
+	Name             string `json:"name"`
+	Phone            string `json:"phone"`
+	Email            string `json:"email"`
+	MarketingConsent bool   `json:"marketing_consent"`
+}
+
+func NewCustomerHandler(u domain.CustomerUsecase) *CustomerHandler {
+	return &CustomerHandler{CustomerUsecase: u}
+}
+
+func (h *CustomerHandler) Create(c *gin.Context) {
+	var req customerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	customer := req.toCustomer()
+	if err := h.CustomerUsecase.Create(c.Request.Context(), customer); err != nil {
+		writeCustomerError(c, err, "Failed to create customer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, customer)
+}
+
+func (h *CustomerHandler) Search(c *gin.Context) {
+	filter := domain.CustomerFilter{
+		Phone: c.Query("phone"),
+		Email: c.Query("email"),
+	}
+
+	customers, err := h.CustomerUsecase.Search(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch customers"})
+		return
+	}
+	c.JSON(http.StatusOK, customers)
+}
+
+func (h *CustomerHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	customer, err := h.CustomerUsecase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve customer"})
+		return
+	}
+	if customer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+func (h *CustomerHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req customerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	customer := req.toCustomer()
+	customer.ID = id
+	if err := h.CustomerUsecase.Update(c.Request.Context(), customer); err != nil {
+		writeCustomerError(c, err, "Failed to update customer")
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+func (h *CustomerHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.CustomerUsecase.Delete(c.Request.Context(), id); err != nil {
+		writeCustomerError(c, err, "Failed to delete customer")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CustomerHandler) OrderHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	orders, err := h.CustomerUsecase.OrderHistory(c.Request.Context(), id)
+	if err != nil {
+		writeCustomerError(c, err, "Failed to fetch customer orders")
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (r customerRequest) toCustomer() *domain.Customer {
+	return &domain.Customer{
+		Name:             r.Name,
+		Phone:            r.Phone,
+		Email:            r.Email,
+		MarketingConsent: r.MarketingConsent,
+	}
+}
+
+func writeCustomerError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCustomerName),
+		errors.Is(err, usecase.ErrInvalidCustomerEmail),
+		errors.Is(err, usecase.ErrInvalidCustomerPhone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "A customer with this phone or email already exists"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCustomerUsecase struct{ mock.Mock }
+
+func (m *mockCustomerUsecase) Create(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+func (m *mockCustomerUsecase) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+func (m *mockCustomerUsecase) Search(ctx context.Context, filter domain.CustomerFilter) ([]domain.Customer, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Customer), args.Error(1)
+}
+func (m *mockCustomerUsecase) Update(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+func (m *mockCustomerUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *mockCustomerUsecase) OrderHistory(ctx context.Context, customerID uuid.UUID) ([]domain.Order, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func TestCustomerHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mockCustomerUsecase)
+		h := NewCustomerHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/customers", h.Create)
+
+		mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.Customer) bool {
+			return c.Name == "Sam" && c.MarketingConsent
+		})).Return(nil)
+
+		body, _ := json.Marshal(map[string]any{"name": "Sam", "phone": "5550102030", "marketing_consent": true})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/customers", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		mockUsecase := new(mockCustomerUsecase)
+		h := NewCustomerHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/customers", h.Create)
+
+		mockUsecase.On("Create", mock.Anything, mock.Anything).Return(domain.ErrAlreadyExists)
+
+		body, _ := json.Marshal(map[string]any{"name": "Sam", "phone": "5550102030"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/customers", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		mockUsecase := new(mockCustomerUsecase)
+		h := NewCustomerHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/customers", h.Create)
+
+		mockUsecase.On("Create", mock.Anything, mock.Anything).Return(usecase.ErrInvalidCustomerEmail)
+
+		body, _ := json.Marshal(map[string]any{"name": "Sam", "email": "nope"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/customers", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCustomerHandler_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockCustomerUsecase)
+	h := NewCustomerHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/customers", h.Search)
+
+	mockUsecase.On("Search", mock.Anything, domain.CustomerFilter{Email: "sam@example.com"}).Return([]domain.Customer{{Name: "Sam"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers?email=sam@example.com", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_OrderHistory_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockCustomerUsecase)
+	h := NewCustomerHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/customers/:id/orders", h.OrderHistory)
+
+	id := uuid.New()
+	mockUsecase.On("OrderHistory", mock.Anything, id).Return(nil, domain.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers/"+id.String()+"/orders", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
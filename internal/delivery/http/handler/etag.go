@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// A resource's ETag is its version number in quotes.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// listETag identifies a listing by the ID and version of every row in it, so it
+// changes when a row is added, removed, reordered or updated.
+func listETag(rows []string) string {
+	h := sha256.New()
+	for _, row := range rows {
+		h.Write([]byte(row))
+		h.Write([]byte{'\n'})
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+func splitETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// notModified sets the response's ETag and answers 304 Not Modified if the
+// request's If-None-Match already names it.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	for _, tag := range splitETags(c.GetHeader("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns the request context carrying the versions named in
+// If-Match. If the header names no version this server could have issued, it
+// answers 412 Precondition Failed itself and returns false.
+func ifMatch(c *gin.Context) (context.Context, bool) {
+	ctx := c.Request.Context()
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return ctx, true
+	}
+
+	var versions []int64
+	for _, tag := range splitETags(header) {
+		if tag == "*" {
+			return ctx, true
+		}
+		// If-Match compares strongly, so weak tags never match.
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": domain.ErrPreconditionFailed.Error()})
+		return nil, false
+	}
+	return domain.WithIfMatch(ctx, versions), true
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GiftCardHandler struct {
+	GiftCardUsecase domain.GiftCardUsecase
+}
+
+func NewGiftCardHandler(u domain.GiftCardUsecase) *GiftCardHandler {
+	return &GiftCardHandler{GiftCardUsecase: u}
+}
+
+func (h *GiftCardHandler) GetByCode(c *gin.Context) {
+	card, err := h.GiftCardUsecase.GetByCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrGiftCardNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve gift card"})
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *GiftCardHandler) ListForOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	cards, err := h.GiftCardUsecase.ListForOrder(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift cards"})
+		return
+	}
+	c.JSON(http.StatusOK, cards)
+}
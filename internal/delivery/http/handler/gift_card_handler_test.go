@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGiftCardUsecase struct{ mock.Mock }
+
+func (m *mockGiftCardUsecase) GetByCode(ctx context.Context, code string) (*domain.GiftCard, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GiftCard), args.Error(1)
+}
+func (m *mockGiftCardUsecase) ListForOrder(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCard, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.GiftCard), args.Error(1)
+}
+func (m *mockGiftCardUsecase) IssueForOrder(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockGiftCardUsecase) RefundOrder(ctx context.Context, orderID uuid.UUID) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func TestGiftCardHandler_GetByCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mockGiftCardUsecase)
+		h := NewGiftCardHandler(mockUsecase)
+		r := gin.Default()
+		r.GET("/api/v1/gift-cards/:code", h.GetByCode)
+
+		mockUsecase.On("GetByCode", mock.Anything, "ABCD-EFGH-JKLM-NP23").Return(&domain.GiftCard{Code: "ABCD-EFGH-JKLM-NP23"}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/gift-cards/ABCD-EFGH-JKLM-NP23", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUsecase := new(mockGiftCardUsecase)
+		h := NewGiftCardHandler(mockUsecase)
+		r := gin.Default()
+		r.GET("/api/v1/gift-cards/:code", h.GetByCode)
+
+		mockUsecase.On("GetByCode", mock.Anything, "NOPE").Return(nil, usecase.ErrGiftCardNotFound)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/gift-cards/NOPE", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type InventoryHandler struct {
+	InventoryUsecase domain.InventoryUsecase
+}
+
+type createIngredientRequest struct {
+	Name             string          `json:"name"`
+	Unit             string          `json:"unit"`
+	UnitCost         decimal.Decimal `json:"unit_cost"`
+	BaselineQuantity decimal.Decimal `json:"baseline_quantity"`
+}
+
+type recipeItemRequest struct {
+	IngredientID uuid.UUID       `json:"ingredient_id"`
+	Quantity     decimal.Decimal `json:"quantity"`
+}
+
+type setRecipeRequest struct {
+	Items []recipeItemRequest `json:"items"`
+}
+
+type createMovementRequest struct {
+	IngredientID uuid.UUID       `json:"ingredient_id"`
+	Type         string          `json:"type"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	UnitCost     decimal.Decimal `json:"unit_cost"`
+	Note         string          `json:"note"`
+	OccurredAt   *time.Time      `json:"occurred_at"`
+}
+
+type startStockCountRequest struct {
+	Note string `json:"note"`
+}
+
+type countLineRequest struct {
+	IngredientID    uuid.UUID       `json:"ingredient_id"`
+	CountedQuantity decimal.Decimal `json:"counted_quantity"`
+}
+
+type recordCountLinesRequest struct {
+	Lines []countLineRequest `json:"lines"`
+}
+
+func NewInventoryHandler(u domain.InventoryUsecase) *InventoryHandler {
+	return &InventoryHandler{InventoryUsecase: u}
+}
+
+func (h *InventoryHandler) CreateIngredient(c *gin.Context) {
+	var req createIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ingredient := &domain.Ingredient{
+		Name:             req.Name,
+		Unit:             req.Unit,
+		UnitCost:         req.UnitCost,
+		BaselineQuantity: req.BaselineQuantity,
+	}
+	if err := h.InventoryUsecase.CreateIngredient(c.Request.Context(), ingredient); err != nil {
+		if errors.Is(err, usecase.ErrInvalidIngredient) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ingredient"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ingredient)
+}
+
+func (h *InventoryHandler) ListIngredients(c *gin.Context) {
+	ingredients, err := h.InventoryUsecase.ListIngredients(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ingredients"})
+		return
+	}
+	c.JSON(http.StatusOK, ingredients)
+}
+
+func (h *InventoryHandler) GetRecipe(c *gin.Context) {
+	menuItemID, err := uuid.Parse(c.Param("menu_item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	items, err := h.InventoryUsecase.GetRecipe(c.Request.Context(), menuItemID)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recipe"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *InventoryHandler) SetRecipe(c *gin.Context) {
+	menuItemID, err := uuid.Parse(c.Param("menu_item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req setRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	items := make([]domain.RecipeItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = domain.RecipeItem{IngredientID: item.IngredientID, Quantity: item.Quantity}
+	}
+
+	if err := h.InventoryUsecase.SetRecipe(c.Request.Context(), menuItemID, items); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidStockQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Menu item or ingredient not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *InventoryHandler) RecordMovement(c *gin.Context) {
+	var req createMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	movement := &domain.StockMovement{
+		IngredientID: req.IngredientID,
+		Type:         req.Type,
+		Quantity:     req.Quantity,
+		UnitCost:     req.UnitCost,
+		Note:         req.Note,
+	}
+	if req.OccurredAt != nil {
+		movement.OccurredAt = *req.OccurredAt
+	}
+
+	if err := h.InventoryUsecase.RecordMovement(c.Request.Context(), movement); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidMovementType), errors.Is(err, usecase.ErrInvalidStockQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record stock movement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, movement)
+}
+
+func (h *InventoryHandler) StartStockCount(c *gin.Context) {
+	// The note is optional, so an empty body is accepted.
+	var req startStockCountRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	count := &domain.StockCount{Note: req.Note}
+	if err := h.InventoryUsecase.StartStockCount(c.Request.Context(), count); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start stock count"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, count)
+}
+
+func (h *InventoryHandler) GetStockCount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	count, err := h.InventoryUsecase.GetStockCount(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stock count"})
+		return
+	}
+	if count == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stock count not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, count)
+}
+
+func (h *InventoryHandler) RecordCountedQuantities(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req recordCountLinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	lines := make([]domain.StockCountLine, len(req.Lines))
+	for i, line := range req.Lines {
+		lines[i] = domain.StockCountLine{IngredientID: line.IngredientID, CountedQuantity: line.CountedQuantity}
+	}
+
+	if err := h.InventoryUsecase.RecordCountedQuantities(c.Request.Context(), id, lines); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidCountQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrStockCountApplied):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stock count or ingredient not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record counted quantities"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InventoryHandler) VarianceReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	report, err := h.InventoryUsecase.VarianceReport(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stock count not found"})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build variance report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *InventoryHandler) ApplyStockCount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.InventoryUsecase.ApplyStockCount(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrEmptyStockCount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrStockCountApplied), errors.Is(err, usecase.ErrStaleStockCount):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stock count not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply stock count"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
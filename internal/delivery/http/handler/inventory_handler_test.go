@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockInventoryUsecase struct{ mock.Mock }
+
+func (m *mockInventoryUsecase) CreateIngredient(ctx context.Context, ingredient *domain.Ingredient) error {
+	args := m.Called(ctx, ingredient)
+	return args.Error(0)
+}
+func (m *mockInventoryUsecase) ListIngredients(ctx context.Context) ([]domain.Ingredient, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Ingredient), args.Error(1)
+}
+func (m *mockInventoryUsecase) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]domain.RecipeItem, error) {
+	args := m.Called(ctx, menuItemID)
+	return args.Get(0).([]domain.RecipeItem), args.Error(1)
+}
+func (m *mockInventoryUsecase) SetRecipe(ctx context.Context, menuItemID uuid.UUID, items []domain.RecipeItem) error {
+	args := m.Called(ctx, menuItemID, items)
+	return args.Error(0)
+}
+func (m *mockInventoryUsecase) RecordMovement(ctx context.Context, movement *domain.StockMovement) error {
+	args := m.Called(ctx, movement)
+	return args.Error(0)
+}
+func (m *mockInventoryUsecase) StartStockCount(ctx context.Context, count *domain.StockCount) error {
+	args := m.Called(ctx, count)
+	return args.Error(0)
+}
+func (m *mockInventoryUsecase) GetStockCount(ctx context.Context, id uuid.UUID) (*domain.StockCount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockCount), args.Error(1)
+}
+func (m *mockInventoryUsecase) RecordCountedQuantities(ctx context.Context, countID uuid.UUID, lines []domain.StockCountLine) error {
+	args := m.Called(ctx, countID, lines)
+	return args.Error(0)
+}
+func (m *mockInventoryUsecase) VarianceReport(ctx context.Context, countID uuid.UUID) (*domain.StockVarianceReport, error) {
+	args := m.Called(ctx, countID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockVarianceReport), args.Error(1)
+}
+func (m *mockInventoryUsecase) ApplyStockCount(ctx context.Context, countID uuid.UUID) error {
+	args := m.Called(ctx, countID)
+	return args.Error(0)
+}
+
+func TestInventoryHandler_StartStockCount_EmptyBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockInventoryUsecase)
+	h := NewInventoryHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/inventory/counts", h.StartStockCount)
+
+	mockUsecase.On("StartStockCount", mock.Anything, mock.AnythingOfType("*domain.StockCount")).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/inventory/counts", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestInventoryHandler_RecordCountedQuantities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockInventoryUsecase)
+	h := NewInventoryHandler(mockUsecase)
+	r := gin.Default()
+	r.PUT("/api/v1/inventory/counts/:id/lines", h.RecordCountedQuantities)
+
+	countID := uuid.New()
+	ingredientID := uuid.New()
+	body, _ := json.Marshal(map[string]any{"lines": []map[string]any{{"ingredient_id": ingredientID, "counted_quantity": "4.5"}}})
+	mockUsecase.On("RecordCountedQuantities", mock.Anything, countID, mock.MatchedBy(func(lines []domain.StockCountLine) bool {
+		return len(lines) == 1 && lines[0].IngredientID == ingredientID && lines[0].CountedQuantity.Equal(decimal.NewFromFloat(4.5))
+	})).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/inventory/counts/"+countID.String()+"/lines", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestInventoryHandler_VarianceReport_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockInventoryUsecase)
+	h := NewInventoryHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/inventory/counts/:id/variance", h.VarianceReport)
+
+	countID := uuid.New()
+	mockUsecase.On("VarianceReport", mock.Anything, countID).Return(nil, domain.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/inventory/counts/"+countID.String()+"/variance", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInventoryHandler_ApplyStockCount_AlreadyApplied(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockInventoryUsecase)
+	h := NewInventoryHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/inventory/counts/:id/apply", h.ApplyStockCount)
+
+	countID := uuid.New()
+	mockUsecase.On("ApplyStockCount", mock.Anything, countID).Return(usecase.ErrStockCountApplied)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/inventory/counts/"+countID.String()+"/apply", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
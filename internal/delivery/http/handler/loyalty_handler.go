@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LoyaltyHandler struct {
+	LoyaltyUsecase domain.LoyaltyUsecase
+}
+
+func NewLoyaltyHandler(u domain.LoyaltyUsecase) *LoyaltyHandler {
+	return &LoyaltyHandler{LoyaltyUsecase: u}
+}
+
+func (h *LoyaltyHandler) GetAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	account, err := h.LoyaltyUsecase.GetAccount(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve loyalty account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLoyaltyUsecase struct{ mock.Mock }
+
+func (m *mockLoyaltyUsecase) GetAccount(ctx context.Context, customerID uuid.UUID) (*domain.LoyaltyAccount, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoyaltyAccount), args.Error(1)
+}
+func (m *mockLoyaltyUsecase) RedemptionValue(points int64) decimal.Decimal {
+	args := m.Called(points)
+	return args.Get(0).(decimal.Decimal)
+}
+func (m *mockLoyaltyUsecase) RedeemForOrder(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockLoyaltyUsecase) EarnForOrder(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockLoyaltyUsecase) ReverseLine(ctx context.Context, order *domain.Order, itemID uuid.UUID) (*domain.LoyaltyEntry, error) {
+	args := m.Called(ctx, order, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoyaltyEntry), args.Error(1)
+}
+func (m *mockLoyaltyUsecase) ReverseOrder(ctx context.Context, orderID uuid.UUID) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func TestLoyaltyHandler_GetAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mockLoyaltyUsecase)
+		h := NewLoyaltyHandler(mockUsecase)
+		r := gin.Default()
+		r.GET("/api/v1/customers/:id/loyalty", h.GetAccount)
+
+		id := uuid.New()
+		mockUsecase.On("GetAccount", mock.Anything, id).Return(&domain.LoyaltyAccount{
+			CustomerID: id,
+			Balance:    42,
+			Entries:    []domain.LoyaltyEntry{{Type: domain.LoyaltyEntryEarn, Points: 42}},
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers/"+id.String()+"/loyalty", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var account domain.LoyaltyAccount
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &account))
+		assert.Equal(t, int64(42), account.Balance)
+		assert.Len(t, account.Entries, 1)
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		mockUsecase := new(mockLoyaltyUsecase)
+		h := NewLoyaltyHandler(mockUsecase)
+		r := gin.Default()
+		r.GET("/api/v1/customers/:id/loyalty", h.GetAccount)
+
+		id := uuid.New()
+		mockUsecase.On("GetAccount", mock.Anything, id).Return(nil, domain.ErrNotFound)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers/"+id.String()+"/loyalty", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"coffee-shop-pos/internal/domain"
@@ -43,6 +44,10 @@ func (h *MenuHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.MenuUsecase.Create(c.Request.Context(), &item); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create menu item"})
 		return
 	}
@@ -68,6 +73,9 @@ func (h *MenuHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
 		return
 	}
+	if notModified(c, versionETag(item.Version)) {
+		return
+	}
 
 	c.JSON(http.StatusOK, item)
 }
@@ -79,6 +87,14 @@ func (h *MenuHandler) Fetch(c *gin.Context) {
 		return
 	}
 
+	rows := make([]string, len(items))
+	for i, item := range items {
+		rows[i] = fmt.Sprintf("%s:%d", item.ID, item.Version)
+	}
+	if notModified(c, listETag(rows)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, items)
 }
 
@@ -101,16 +117,22 @@ func (h *MenuHandler) Update(c *gin.Context) {
 		return
 	}
 
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	item.ID = id
-	if err := h.MenuUsecase.Update(c.Request.Context(), &item); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
+	if err := h.MenuUsecase.Update(ctx, &item); err != nil {
+		if msg, status, ok := menuWriteError(err); ok {
+			c.JSON(status, gin.H{"error": msg})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update menu item"})
 		return
 	}
 
+	c.Header("ETag", versionETag(item.Version))
 	c.JSON(http.StatusOK, item)
 }
 
@@ -122,10 +144,34 @@ func (h *MenuHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.MenuUsecase.Delete(c.Request.Context(), id); err != nil {
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.MenuUsecase.Delete(ctx, id); err != nil {
+		if msg, status, ok := menuWriteError(err); ok {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete menu item"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// menuWriteError maps the errors shared by menu item updates and deletes.
+func menuWriteError(err error) (string, int, bool) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return "Menu item not found", http.StatusNotFound, true
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return "Menu item has been modified", http.StatusPreconditionFailed, true
+	case errors.Is(err, domain.ErrConflict):
+		return "Menu item was changed by another request", http.StatusConflict, true
+	case errors.Is(err, domain.ErrForbidden):
+		return "Permission denied", http.StatusForbidden, true
+	}
+	return "", 0, false
+}
@@ -147,6 +147,29 @@ func TestMenuHandler_GetByID(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		mockUsecase.AssertExpectations(t)
 	})
+
+	t.Run("not modified", func(t *testing.T) {
+		mockUsecase := new(MockMenuItemUsecase)
+		handler := NewMenuHandler(mockUsecase)
+		r := gin.Default()
+		r.GET("/api/v1/menu/:id", handler.GetByID)
+
+		id := uuid.New()
+		mockUsecase.On("GetByID", mock.Anything, id).Return(&domain.MenuItem{ID: id, Version: 7}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/menu/"+id.String(), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/menu/"+id.String(), nil)
+		req.Header.Set("If-None-Match", `"6", "7"`)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
 }
 
 func TestMenuHandler_Fetch(t *testing.T) {
@@ -174,6 +197,12 @@ func TestMenuHandler_Fetch(t *testing.T) {
 		json.Unmarshal(w.Body.Bytes(), &response)
 		assert.Len(t, response, 2)
 		mockUsecase.AssertExpectations(t)
+
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/menu", nil)
+		req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
 	})
 }
 
@@ -229,6 +258,44 @@ func TestMenuHandler_Update(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		mockUsecase.AssertExpectations(t)
 	})
+
+	t.Run("if-match", func(t *testing.T) {
+		mockUsecase := new(MockMenuItemUsecase)
+		handler := NewMenuHandler(mockUsecase)
+		r := gin.Default()
+		r.PUT("/api/v1/menu/:id", handler.Update)
+
+		id := uuid.New()
+		body, _ := json.Marshal(domain.MenuItem{Name: "Updated Coffee", Price: decimal.NewFromFloat(5.00), Category: "Coffee"})
+		mockUsecase.On("Update", mock.MatchedBy(func(ctx context.Context) bool {
+			versions, ok := domain.IfMatchFromContext(ctx)
+			return ok && len(versions) == 1 && versions[0] == 2
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.MenuItem).Version = 3
+		}).Return(nil).Once()
+		mockUsecase.On("Update", mock.Anything, mock.Anything).Return(domain.ErrPreconditionFailed).Once()
+
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/menu/"+id.String(), bytes.NewBuffer(body))
+		req.Header.Set("If-Match", `"2"`)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		req, _ = http.NewRequest(http.MethodPut, "/api/v1/menu/"+id.String(), bytes.NewBuffer(body))
+		req.Header.Set("If-Match", `"2"`)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		// A weak or foreign tag can never match, so the usecase is not called.
+		req, _ = http.NewRequest(http.MethodPut, "/api/v1/menu/"+id.String(), bytes.NewBuffer(body))
+		req.Header.Set("If-Match", `W/"2"`)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
 }
 
 func TestMenuHandler_Delete(t *testing.T) {
@@ -267,4 +334,20 @@ func TestMenuHandler_Delete(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 		mockUsecase.AssertExpectations(t)
 	})
+	t.Run("forbidden", func(t *testing.T) {
+		mockUsecase := new(MockMenuItemUsecase)
+		handler := NewMenuHandler(mockUsecase)
+		r := gin.Default()
+		r.DELETE("/api/v1/menu/:id", handler.Delete)
+
+		id := uuid.New()
+		mockUsecase.On("Delete", mock.Anything, id).Return(domain.ErrForbidden)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/menu/"+id.String(), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
 }
@@ -2,12 +2,16 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"coffee-shop-pos/internal/domain"
 	"coffee-shop-pos/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type OrderHandler struct {
@@ -15,16 +19,68 @@ type OrderHandler struct {
 }
 
 type createOrderRequest struct {
-	Items []createOrderItemRequest `json:"items"`
+	OrderType      string                   `json:"order_type"`
+	TableNumber    string                   `json:"table_number"`
+	CallOutName    string                   `json:"call_out_name"`
+	Note           string                   `json:"note"`
+	PickupAt       *time.Time               `json:"pickup_at"`
+	CustomerID     *uuid.UUID               `json:"customer_id"`
+	RedeemPoints   int64                    `json:"redeem_points"`
+	ManualDiscount decimal.Decimal          `json:"manual_discount"`
+	Items          []createOrderItemRequest `json:"items"`
 }
 
 type createOrderItemRequest struct {
-	MenuItemID uuid.UUID `json:"menu_item_id"`
-	Quantity   int       `json:"quantity"`
+	MenuItemID   uuid.UUID `json:"menu_item_id"`
+	Quantity     int       `json:"quantity"`
+	GiftCardCode string    `json:"gift_card_code"`
+	Note         string    `json:"note"`
+}
+
+type addRoundRequest struct {
+	Items []createOrderItemRequest `json:"items"`
+}
+
+type openTabRequest struct {
+	Name string `json:"name"`
+}
+
+// splitOrderRequest splits a bill either into Shares equal parts or by the
+// lines given in Splits.
+type splitOrderRequest struct {
+	Shares int            `json:"shares"`
+	Splits []splitRequest `json:"splits"`
+}
+
+type splitRequest struct {
+	Items []splitItemRequest `json:"items"`
+}
+
+type splitItemRequest struct {
+	ItemID   uuid.UUID       `json:"item_id"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+type mergeOrdersRequest struct {
+	OrderIDs []uuid.UUID `json:"order_ids"`
+}
+
+type transferOrderRequest struct {
+	TableNumber string `json:"table_number"`
+}
+
+type updateOrderItemRequest struct {
+	Quantity int     `json:"quantity"`
+	Note     *string `json:"note"`
+}
+
+type voidOrderItemRequest struct {
+	Reason string `json:"reason"`
 }
 
 type updateStatusRequest struct {
 	Status string `json:"status"`
+	Reason string `json:"reason"`
 }
 
 func NewOrderHandler(u domain.OrderUsecase) *OrderHandler {
@@ -38,20 +94,56 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
-	order := &domain.Order{Items: make([]domain.OrderItem, len(req.Items))}
+	order := &domain.Order{
+		OrderType:      req.OrderType,
+		TableNumber:    req.TableNumber,
+		CallOutName:    req.CallOutName,
+		Note:           req.Note,
+		PickupAt:       req.PickupAt,
+		CustomerID:     req.CustomerID,
+		RedeemedPoints: req.RedeemPoints,
+		ManualDiscount: req.ManualDiscount,
+		Items:          make([]domain.OrderItem, len(req.Items)),
+	}
 	for i, item := range req.Items {
 		order.Items[i] = domain.OrderItem{
-			MenuItemID: item.MenuItemID,
-			Quantity:   item.Quantity,
+			MenuItemID:   item.MenuItemID,
+			Quantity:     item.Quantity,
+			GiftCardCode: item.GiftCardCode,
+			Note:         item.Note,
 		}
 	}
 
 	if err := h.OrderUsecase.Create(c.Request.Context(), order); err != nil {
 		switch {
-		case errors.Is(err, usecase.ErrEmptyOrderItems), errors.Is(err, usecase.ErrInvalidOrderQuantity):
+		case errors.Is(err, usecase.ErrEmptyOrderItems), errors.Is(err, usecase.ErrInvalidOrderQuantity),
+			errors.Is(err, usecase.ErrInvalidOrderType), errors.Is(err, usecase.ErrInvalidTableNumber),
+			errors.Is(err, usecase.ErrInvalidCallOutName), errors.Is(err, usecase.ErrNoteTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrPreordersDisabled), errors.Is(err, usecase.ErrInvalidPickupTime),
+			errors.Is(err, usecase.ErrOutsideOpeningHours):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPickupSlotFull):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrInvalidRedeemPoints), errors.Is(err, usecase.ErrRedeemNeedsCustomer),
+			errors.Is(err, usecase.ErrRedeemExceedsTotal), errors.Is(err, usecase.ErrLoyaltyDisabled),
+			errors.Is(err, domain.ErrInsufficientPoints), errors.Is(err, usecase.ErrGiftCardCodeNotAllowed),
+			errors.Is(err, usecase.ErrInvalidManualDiscount), errors.Is(err, usecase.ErrDiscountExceedsTotal):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrCustomerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		case errors.Is(err, usecase.ErrGiftCardNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
 		case errors.Is(err, domain.ErrNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
+		case errors.Is(err, domain.ErrOverrideRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Manager approval required"})
+		case errors.Is(err, domain.ErrInvalidOverride):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid manager approval"})
+		case errors.Is(err, domain.ErrPINLocked):
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
 		}
@@ -77,16 +169,66 @@ func (h *OrderHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
+	if notModified(c, versionETag(order.Version)) {
+		return
+	}
 
 	c.JSON(http.StatusOK, order)
 }
 
 func (h *OrderHandler) List(c *gin.Context) {
-	orders, err := h.OrderUsecase.List(c.Request.Context())
+	var filter domain.OrderFilter
+	if customerID := c.Query("customer_id"); customerID != "" {
+		id, err := uuid.Parse(customerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer_id format"})
+			return
+		}
+		filter.CustomerID = &id
+	}
+	if orderType := c.Query("order_type"); orderType != "" {
+		if !domain.IsOrderType(orderType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order_type"})
+			return
+		}
+		filter.OrderType = orderType
+	}
+	if tab := c.Query("tab"); tab != "" {
+		if tab != "open" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tab filter, only open is supported"})
+			return
+		}
+		filter.OpenTabs = true
+	}
+	if queue := c.Query("queue"); queue != "" {
+		inQueue, err := strconv.ParseBool(queue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid queue filter"})
+			return
+		}
+		filter.Queue = inQueue
+	}
+	if scheduled := c.Query("scheduled"); scheduled != "" {
+		if scheduled != "held" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scheduled filter, only held is supported"})
+			return
+		}
+		filter.Held = true
+	}
+
+	orders, err := h.OrderUsecase.List(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
 		return
 	}
+
+	rows := make([]string, len(orders))
+	for i, order := range orders {
+		rows[i] = fmt.Sprintf("%s:%d", order.ID, order.Version)
+	}
+	if notModified(c, listETag(rows)) {
+		return
+	}
 	c.JSON(http.StatusOK, orders)
 }
 
@@ -103,12 +245,30 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.OrderUsecase.UpdateStatus(c.Request.Context(), id, req.Status); err != nil {
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.OrderUsecase.UpdateStatus(ctx, id, req.Status, req.Reason); err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-		case errors.Is(err, usecase.ErrInvalidOrderStatus), errors.Is(err, usecase.ErrInvalidStatusMove):
+		case errors.Is(err, usecase.ErrInvalidOrderStatus), errors.Is(err, usecase.ErrInvalidStatusMove),
+			errors.Is(err, usecase.ErrStatusReasonTooLong):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrOverrideRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Manager approval required"})
+		case errors.Is(err, domain.ErrInvalidOverride):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid manager approval"})
+		case errors.Is(err, domain.ErrPINLocked):
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
 		}
@@ -117,3 +277,435 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *OrderHandler) AddItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req createOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.AddItem(ctx, id, &domain.OrderItem{
+		MenuItemID:   req.MenuItemID,
+		Quantity:     req.Quantity,
+		GiftCardCode: req.GiftCardCode,
+		Note:         req.Note,
+	})
+	if err != nil {
+		writeOrderEditError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) AddRound(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req addRoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	items := make([]domain.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = domain.OrderItem{
+			MenuItemID:   item.MenuItemID,
+			Quantity:     item.Quantity,
+			GiftCardCode: item.GiftCardCode,
+			Note:         item.Note,
+		}
+	}
+	order, err := h.OrderUsecase.AddRound(ctx, id, items)
+	if err != nil {
+		writeOrderEditError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) UpdateItem(c *gin.Context) {
+	id, itemID, ok := orderItemParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if (req.Quantity == 0) == (req.Note == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give either quantity or note"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var order *domain.Order
+	var err error
+	if req.Note != nil {
+		order, err = h.OrderUsecase.UpdateItemNote(ctx, id, itemID, *req.Note)
+	} else {
+		order, err = h.OrderUsecase.UpdateItemQuantity(ctx, id, itemID, req.Quantity)
+	}
+	if err != nil {
+		writeOrderEditError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) RemoveItem(c *gin.Context) {
+	id, itemID, ok := orderItemParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.RemoveItem(ctx, id, itemID)
+	if err != nil {
+		writeOrderEditError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) VoidItem(c *gin.Context) {
+	id, itemID, ok := orderItemParams(c)
+	if !ok {
+		return
+	}
+
+	var req voidOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.VoidItem(ctx, id, itemID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrOrderItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, usecase.ErrVoidReasonRequired), errors.Is(err, usecase.ErrVoidReasonTooLong),
+			errors.Is(err, usecase.ErrGiftCardLineNotVoid), errors.Is(err, usecase.ErrVoidLastLine),
+			errors.Is(err, usecase.ErrRedeemExceedsTotal), errors.Is(err, usecase.ErrDiscountExceedsTotal),
+			errors.Is(err, usecase.ErrLoyaltyDisabled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOrderNotVoidable), errors.Is(err, usecase.ErrLineAlreadyVoided):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrOverrideRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Manager approval required"})
+		case errors.Is(err, domain.ErrInvalidOverride):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid manager approval"})
+		case errors.Is(err, domain.ErrPINLocked):
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to void order item"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) OpenTab(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req openTabRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.OpenTab(ctx, id, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, usecase.ErrTabNameRequired), errors.Is(err, usecase.ErrInvalidTabName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOrderNotEditable), errors.Is(err, usecase.ErrTabAlreadyOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open tab"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) Split(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req splitOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if (req.Shares == 0) == (len(req.Splits) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give either shares or splits"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var orders []domain.Order
+	if req.Shares != 0 {
+		orders, err = h.OrderUsecase.SplitEqually(ctx, id, req.Shares)
+	} else {
+		splits := make([]domain.OrderSplit, len(req.Splits))
+		for i, split := range req.Splits {
+			for _, item := range split.Items {
+				splits[i].Items = append(splits[i].Items, domain.SplitItem{ItemID: item.ItemID, Quantity: item.Quantity})
+			}
+		}
+		orders, err = h.OrderUsecase.Split(ctx, id, splits)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrOrderItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, usecase.ErrInvalidSplitCount), errors.Is(err, usecase.ErrInvalidSplitQuantity),
+			errors.Is(err, usecase.ErrSplitIncomplete), errors.Is(err, usecase.ErrSplitGiftCardLine), errors.Is(err, usecase.ErrSplitRewardLine),
+			errors.Is(err, usecase.ErrSplitRedeemedPoints), errors.Is(err, usecase.ErrEmptyOrderItems):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOrderNotEditable), errors.Is(err, usecase.ErrSplitHasPayments):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrSplitUnbalanced):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to split order"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, orders)
+}
+
+func (h *OrderHandler) Merge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req mergeOrdersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.Merge(ctx, id, req.OrderIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, usecase.ErrInvalidMergeCount), errors.Is(err, usecase.ErrInvalidMergeSource),
+			errors.Is(err, usecase.ErrMergeRedeemedPoints), errors.Is(err, usecase.ErrMergeCustomerMismatch),
+			errors.Is(err, usecase.ErrMergeStampRewards), errors.Is(err, usecase.ErrDiscountExceedsTotal),
+			errors.Is(err, usecase.ErrLoyaltyDisabled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOverrideRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Manager approval required"})
+		case errors.Is(err, domain.ErrInvalidOverride):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid manager approval"})
+		case errors.Is(err, usecase.ErrOrderNotEditable), errors.Is(err, usecase.ErrMergeHasPayments),
+			errors.Is(err, domain.ErrPickupSlotFull):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge orders"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) Transfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req transferOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderUsecase.Transfer(ctx, id, req.TableNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, usecase.ErrTableNumberRequired), errors.Is(err, usecase.ErrInvalidTableNumber):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOrderNotEditable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer order"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(order.Version))
+	c.JSON(http.StatusOK, order)
+}
+
+func orderItemParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, itemID, true
+}
+
+// writeOrderEditError maps the errors of adding, changing and removing order
+// lines.
+func writeOrderEditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrMenuItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
+	case errors.Is(err, usecase.ErrOrderItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+	case errors.Is(err, usecase.ErrGiftCardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, usecase.ErrEmptyOrderItems), errors.Is(err, usecase.ErrInvalidOrderQuantity),
+		errors.Is(err, usecase.ErrRedeemExceedsTotal), errors.Is(err, usecase.ErrDiscountExceedsTotal),
+		errors.Is(err, usecase.ErrGiftCardCodeNotAllowed), errors.Is(err, usecase.ErrRewardLineNotEditable),
+		errors.Is(err, usecase.ErrLoyaltyDisabled), errors.Is(err, usecase.ErrNoteTooLong):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrOrderNotEditable), errors.Is(err, usecase.ErrOrderHasPayments),
+		errors.Is(err, domain.ErrPickupSlotFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order has been modified"})
+	case errors.Is(err, domain.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+	case errors.Is(err, domain.ErrOverrideRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Manager approval required"})
+	case errors.Is(err, domain.ErrInvalidOverride):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid manager approval"})
+	case errors.Is(err, domain.ErrPINLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order items"})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"coffee-shop-pos/internal/domain"
 	"coffee-shop-pos/internal/usecase"
@@ -30,18 +32,110 @@ func (m *mockOrderUsecase) GetByID(ctx context.Context, id uuid.UUID) (*domain.O
 	}
 	return args.Get(0).(*domain.Order), args.Error(1)
 }
-func (m *mockOrderUsecase) List(ctx context.Context) ([]domain.Order, error) {
-	args := m.Called(ctx)
+func (m *mockOrderUsecase) List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]domain.Order), args.Error(1)
 }
-func (m *mockOrderUsecase) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
-	args := m.Called(ctx, id, status)
+func (m *mockOrderUsecase) UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) error {
+	args := m.Called(ctx, id, status, reason)
 	return args.Error(0)
 }
 
+func (m *mockOrderUsecase) AddItem(ctx context.Context, orderID uuid.UUID, item *domain.OrderItem) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, item)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) AddRound(ctx context.Context, orderID uuid.UUID, items []domain.OrderItem) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, items)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) OpenTab(ctx context.Context, orderID uuid.UUID, name string) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) Split(ctx context.Context, orderID uuid.UUID, splits []domain.OrderSplit) ([]domain.Order, error) {
+	args := m.Called(ctx, orderID, splits)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) SplitEqually(ctx context.Context, orderID uuid.UUID, shares int) ([]domain.Order, error) {
+	args := m.Called(ctx, orderID, shares)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) Merge(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) (*domain.Order, error) {
+	args := m.Called(ctx, targetID, sourceIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) Transfer(ctx context.Context, orderID uuid.UUID, tableNumber string) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, tableNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) ReleaseDue(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockOrderUsecase) UpdateItemNote(ctx context.Context, orderID, itemID uuid.UUID, note string) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, itemID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+func (m *mockOrderUsecase) UpdateItemQuantity(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, itemID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) RemoveItem(ctx context.Context, orderID, itemID uuid.UUID) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *mockOrderUsecase) VoidItem(ctx context.Context, orderID, itemID uuid.UUID, reason string) (*domain.Order, error) {
+	args := m.Called(ctx, orderID, itemID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
 func TestOrderHandler_Create(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mockOrderUsecase)
@@ -50,9 +144,11 @@ func TestOrderHandler_Create(t *testing.T) {
 	r.POST("/api/v1/orders", h.Create)
 
 	menuID := uuid.New()
-	payload := map[string]any{"items": []map[string]any{{"menu_item_id": menuID, "quantity": 2}}}
+	payload := map[string]any{"note": "one bill", "items": []map[string]any{{"menu_item_id": menuID, "quantity": 2, "note": "extra hot"}}}
 	body, _ := json.Marshal(payload)
-	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(order *domain.Order) bool {
+		return order.Note == "one bill" && order.Items[0].Note == "extra hot"
+	})).Return(nil)
 
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
@@ -70,13 +166,21 @@ func TestOrderHandler_GetByID(t *testing.T) {
 	r.GET("/api/v1/orders/:id", h.GetByID)
 
 	id := uuid.New()
-	mockUsecase.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id}, nil)
+	mockUsecase.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Version: 2}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/"+id.String(), nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders/"+id.String(), nil)
+	req.Header.Set("If-None-Match", `"2"`)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
 }
 
 func TestOrderHandler_List(t *testing.T) {
@@ -86,7 +190,7 @@ func TestOrderHandler_List(t *testing.T) {
 	r := gin.Default()
 	r.GET("/api/v1/orders", h.List)
 
-	mockUsecase.On("List", mock.Anything).Return([]domain.Order{{ID: uuid.New()}}, nil)
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{}).Return([]domain.Order{{ID: uuid.New()}}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
 	w := httptest.NewRecorder()
@@ -104,7 +208,7 @@ func TestOrderHandler_UpdateStatus(t *testing.T) {
 
 	id := uuid.New()
 	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusPaid})
-	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusPaid).Return(nil)
+	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusPaid, "").Return(nil)
 
 	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
@@ -122,7 +226,7 @@ func TestOrderHandler_UpdateStatus_InvalidTransition(t *testing.T) {
 
 	id := uuid.New()
 	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusCompleted})
-	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusCompleted).Return(usecase.ErrInvalidStatusMove)
+	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusCompleted, "").Return(usecase.ErrInvalidStatusMove)
 
 	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
@@ -131,6 +235,47 @@ func TestOrderHandler_UpdateStatus_InvalidTransition(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestOrderHandler_UpdateStatus_Conflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.PATCH("/api/v1/orders/:id/status", h.UpdateStatus)
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusCancelled})
+	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusCancelled, "").Return(domain.ErrConflict)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestOrderHandler_UpdateStatus_PreconditionFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.PATCH("/api/v1/orders/:id/status", h.UpdateStatus)
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusCancelled})
+	mockUsecase.On("UpdateStatus", mock.MatchedBy(func(ctx context.Context) bool {
+		versions, ok := domain.IfMatchFromContext(ctx)
+		return ok && len(versions) == 1 && versions[0] == 1
+	}), id, domain.OrderStatusCancelled, "").Return(domain.ErrPreconditionFailed)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
 func TestOrderHandler_Create_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mockOrderUsecase)
@@ -150,6 +295,32 @@ func TestOrderHandler_Create_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestOrderHandler_Create_InsufficientPoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders", h.Create)
+
+	customerID := uuid.New()
+	payload := map[string]any{
+		"customer_id":   customerID,
+		"redeem_points": 500,
+		"items":         []map[string]any{{"menu_item_id": uuid.New(), "quantity": 1}},
+	}
+	body, _ := json.Marshal(payload)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(o *domain.Order) bool {
+		return o.RedeemedPoints == 500 && *o.CustomerID == customerID
+	})).Return(domain.ErrInsufficientPoints)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
 func TestOrderHandler_List_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mockOrderUsecase)
@@ -157,7 +328,7 @@ func TestOrderHandler_List_Error(t *testing.T) {
 	r := gin.Default()
 	r.GET("/api/v1/orders", h.List)
 
-	mockUsecase.On("List", mock.Anything).Return(nil, errors.New("db error"))
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{}).Return(nil, errors.New("db error"))
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
 	w := httptest.NewRecorder()
@@ -165,3 +336,331 @@ func TestOrderHandler_List_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestOrderHandler_List_ByCustomer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/orders", h.List)
+
+	customerID := uuid.New()
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{CustomerID: &customerID}).Return([]domain.Order{}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?customer_id="+customerID.String(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_List_ByOrderType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/orders", h.List)
+
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{OrderType: domain.OrderTypeDineIn}).Return([]domain.Order{}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?order_type=dine_in", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders?order_type=drive_thru", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_Preorders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders", h.Create)
+	r.GET("/api/v1/orders", h.List)
+
+	pickup := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(order *domain.Order) bool {
+		return order.PickupAt != nil && order.PickupAt.Equal(pickup)
+	})).Return(domain.ErrPickupSlotFull)
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{Queue: true}).Return([]domain.Order{}, nil)
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{Held: true}).Return([]domain.Order{}, nil)
+
+	body, _ := json.Marshal(map[string]any{"pickup_at": "2026-10-19T08:30:00Z", "items": []map[string]any{{"menu_item_id": uuid.New(), "quantity": 1}}})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for query, code := range map[string]int{
+		"queue=true":      http.StatusOK,
+		"scheduled=held":  http.StatusOK,
+		"queue=maybe":     http.StatusBadRequest,
+		"scheduled=later": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, query)
+	}
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_UpdateStatus_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.PATCH("/api/v1/orders/:id/status", h.UpdateStatus)
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusCancelled})
+	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusCancelled, "").Return(domain.ErrForbidden)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOrderHandler_UpdateStatus_OverrideRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.PATCH("/api/v1/orders/:id/status", h.UpdateStatus)
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]string{"status": domain.OrderStatusCancelled})
+	mockUsecase.On("UpdateStatus", mock.Anything, id, domain.OrderStatusCancelled, "").Return(domain.ErrOverrideRequired)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/status", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Manager approval required")
+}
+
+func TestOrderHandler_Tabs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/orders", h.List)
+	r.POST("/api/v1/orders/:id/tab", h.OpenTab)
+	r.POST("/api/v1/orders/:id/rounds", h.AddRound)
+
+	id, menuID := uuid.New(), uuid.New()
+	tab := &domain.Order{ID: id, Status: domain.OrderStatusPending, TabName: "Jo", Version: 3}
+	mockUsecase.On("OpenTab", mock.Anything, id, "Jo").Return(tab, nil).Once()
+	mockUsecase.On("OpenTab", mock.Anything, id, "Jo").Return(nil, usecase.ErrTabAlreadyOpen).Once()
+	mockUsecase.On("AddRound", mock.Anything, id, []domain.OrderItem{{MenuItemID: menuID, Quantity: 2}}).Return(tab, nil)
+	mockUsecase.On("List", mock.Anything, domain.OrderFilter{OpenTabs: true}).Return([]domain.Order{*tab}, nil)
+
+	body, _ := json.Marshal(map[string]string{"name": "Jo"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/tab", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/tab", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	body, _ = json.Marshal(map[string]interface{}{"items": []map[string]interface{}{{"menu_item_id": menuID, "quantity": 2}}})
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/rounds", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders?tab=open", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders?tab=closed", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_Split(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders/:id/split", h.Split)
+
+	id, itemID := uuid.New(), uuid.New()
+	children := []domain.Order{{ID: uuid.New(), ParentOrderID: &id}, {ID: uuid.New(), ParentOrderID: &id}}
+	mockUsecase.On("SplitEqually", mock.Anything, id, 2).Return(children, nil)
+	mockUsecase.On("Split", mock.Anything, id, mock.MatchedBy(func(splits []domain.OrderSplit) bool {
+		return len(splits) == 2 && splits[1].Items[0].ItemID == itemID && splits[1].Items[0].Quantity.String() == "0.5"
+	})).Return(nil, usecase.ErrSplitIncomplete)
+
+	send := func(body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/split", bytes.NewBuffer(payload))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(map[string]int{"shares": 2})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got []domain.Order
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 2)
+
+	item := map[string]interface{}{"item_id": itemID, "quantity": "0.5"}
+	w = send(map[string]interface{}{"splits": []interface{}{
+		map[string]interface{}{"items": []interface{}{item}},
+		map[string]interface{}{"items": []interface{}{item}},
+	}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_MergeAndTransfer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders/:id/merge", h.Merge)
+	r.POST("/api/v1/orders/:id/transfer", h.Transfer)
+
+	id, sourceID, paidID := uuid.New(), uuid.New(), uuid.New()
+	merged := &domain.Order{ID: id, Status: domain.OrderStatusPending, TableNumber: "4", Version: 3}
+	moved := &domain.Order{ID: id, Status: domain.OrderStatusPending, TableNumber: "7", Version: 4}
+	mockUsecase.On("Merge", mock.Anything, id, []uuid.UUID{sourceID}).Return(merged, nil)
+	mockUsecase.On("Merge", mock.Anything, id, []uuid.UUID{paidID}).Return(nil, usecase.ErrMergeHasPayments)
+	mockUsecase.On("Transfer", mock.Anything, id, "7").Return(moved, nil)
+	mockUsecase.On("Transfer", mock.Anything, id, "").Return(nil, usecase.ErrTableNumberRequired)
+
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+path, bytes.NewBuffer(payload))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/merge", map[string]interface{}{"order_ids": []uuid.UUID{sourceID}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = send("/merge", map[string]interface{}{"order_ids": []uuid.UUID{paidID}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send("/transfer", map[string]string{"table_number": "7"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	w = send("/transfer", map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestOrderHandler_EditItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders/:id/items", h.AddItem)
+	r.PATCH("/api/v1/orders/:id/items/:item_id", h.UpdateItem)
+	r.DELETE("/api/v1/orders/:id/items/:item_id", h.RemoveItem)
+
+	id, itemID, menuID := uuid.New(), uuid.New(), uuid.New()
+	repriced := &domain.Order{ID: id, Status: domain.OrderStatusPending, Version: 3}
+	mockUsecase.On("AddItem", mock.Anything, id, mock.MatchedBy(func(item *domain.OrderItem) bool {
+		return item.MenuItemID == menuID && item.Quantity == 2
+	})).Return(repriced, nil)
+	mockUsecase.On("UpdateItemQuantity", mock.Anything, id, itemID, 3).Return(nil, usecase.ErrOrderNotEditable)
+	mockUsecase.On("UpdateItemNote", mock.Anything, id, itemID, "no lid").Return(repriced, nil)
+	mockUsecase.On("UpdateItemNote", mock.Anything, id, itemID, "").Return(repriced, nil)
+	mockUsecase.On("UpdateItemNote", mock.Anything, id, itemID, strings.Repeat("x", 201)).Return(nil, usecase.ErrNoteTooLong)
+	mockUsecase.On("RemoveItem", mock.Anything, id, itemID).Return(nil, usecase.ErrOrderItemNotFound)
+	paidItemID := uuid.New()
+	mockUsecase.On("RemoveItem", mock.Anything, id, paidItemID).Return(nil, usecase.ErrOrderHasPayments)
+
+	body, _ := json.Marshal(map[string]interface{}{"menu_item_id": menuID, "quantity": 2})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/items", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	body, _ = json.Marshal(map[string]int{"quantity": 3})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/items/"+itemID.String(), bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"note": "no lid"}`, http.StatusOK},
+		{`{"note": ""}`, http.StatusOK},
+		{`{"note": "` + strings.Repeat("x", 201) + `"}`, http.StatusBadRequest},
+		{`{"quantity": 2, "note": "no lid"}`, http.StatusBadRequest},
+		{`{}`, http.StatusBadRequest},
+	} {
+		req, _ = http.NewRequest(http.MethodPatch, "/api/v1/orders/"+id.String()+"/items/"+itemID.String(), bytes.NewBufferString(tc.body))
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, tc.body)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/orders/"+id.String()+"/items/"+itemID.String(), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/orders/"+id.String()+"/items/"+paidItemID.String(), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/orders/"+id.String()+"/items/not-a-uuid", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOrderHandler_VoidItem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOrderUsecase)
+	h := NewOrderHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/orders/:id/items/:item_id/void", h.VoidItem)
+
+	id, itemID, otherID := uuid.New(), uuid.New(), uuid.New()
+	mockUsecase.On("VoidItem", mock.Anything, id, itemID, "wrong milk").Return(&domain.Order{ID: id, Version: 4}, nil)
+	mockUsecase.On("VoidItem", mock.Anything, id, otherID, "wrong milk").Return(nil, domain.ErrOverrideRequired)
+
+	body, _ := json.Marshal(map[string]string{"reason": "wrong milk"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/items/"+itemID.String()+"/void", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/items/"+otherID.String()+"/void", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
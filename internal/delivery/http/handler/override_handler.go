@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OverrideHandler struct {
+	OverrideUsecase domain.OverrideUsecase
+}
+
+type approvalTokenRequest struct {
+	Action   string     `json:"action" binding:"required"`
+	EntityID *uuid.UUID `json:"entity_id"`
+}
+
+func NewOverrideHandler(u domain.OverrideUsecase) *OverrideHandler {
+	return &OverrideHandler{OverrideUsecase: u}
+}
+
+// IssueToken lets a manager hand out a single-use approval for an action,
+// optionally tied to one order.
+func (h *OverrideHandler) IssueToken(c *gin.Context) {
+	var req approvalTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	token, err := h.OverrideUsecase.IssueToken(c.Request.Context(), req.Action, req.EntityID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownOverrideAction):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue approval token"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, token)
+}
+
+func (h *OverrideHandler) List(c *gin.Context) {
+	filter := domain.ApprovalFilter{Action: c.Query("action")}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		id, err := uuid.Parse(entityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity_id format"})
+			return
+		}
+		filter.EntityID = &id
+	}
+
+	approvals, err := h.OverrideUsecase.List(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approvals"})
+		return
+	}
+	c.JSON(http.StatusOK, approvals)
+}
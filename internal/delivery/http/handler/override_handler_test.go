@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOverrideUsecase struct{ mock.Mock }
+
+func (m *mockOverrideUsecase) IssueToken(ctx context.Context, action string, entityID *uuid.UUID) (*domain.ApprovalToken, error) {
+	args := m.Called(ctx, action, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApprovalToken), args.Error(1)
+}
+func (m *mockOverrideUsecase) Approve(ctx context.Context, action string, entityID uuid.UUID) (*domain.Approval, error) {
+	args := m.Called(ctx, action, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Approval), args.Error(1)
+}
+func (m *mockOverrideUsecase) Record(ctx context.Context, approval *domain.Approval) error {
+	args := m.Called(ctx, approval)
+	return args.Error(0)
+}
+func (m *mockOverrideUsecase) List(ctx context.Context, filter domain.ApprovalFilter) ([]domain.Approval, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Approval), args.Error(1)
+}
+
+func TestOverrideHandler_IssueToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOverrideUsecase)
+	h := NewOverrideHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/approvals", h.IssueToken)
+
+	orderID := uuid.New()
+	mockUsecase.On("IssueToken", mock.Anything, domain.OverrideCancelPaidOrder, &orderID).
+		Return(&domain.ApprovalToken{Token: "apr_secret", Action: domain.OverrideCancelPaidOrder, EntityID: &orderID}, nil)
+	mockUsecase.On("IssueToken", mock.Anything, "order.unknown", (*uuid.UUID)(nil)).Return(nil, usecase.ErrUnknownOverrideAction)
+
+	body, _ := json.Marshal(map[string]string{"action": domain.OverrideCancelPaidOrder, "entity_id": orderID.String()})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/approvals", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "apr_secret")
+
+	body, _ = json.Marshal(map[string]string{"action": "order.unknown"})
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/approvals", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOverrideHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockOverrideUsecase)
+	h := NewOverrideHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/approvals", h.List)
+
+	orderID := uuid.New()
+	mockUsecase.On("List", mock.Anything, domain.ApprovalFilter{Action: domain.OverrideLargeDiscount, EntityID: &orderID}).
+		Return([]domain.Approval{{ID: uuid.New(), Action: domain.OverrideLargeDiscount, EntityID: orderID}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/approvals?action="+domain.OverrideLargeDiscount+"&entity_id="+orderID.String(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), orderID.String())
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/approvals?entity_id=nope", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
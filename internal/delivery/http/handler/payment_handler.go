@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PaymentHandler struct {
+	PaymentUsecase domain.PaymentUsecase
+}
+
+type paymentRequest struct {
+	Tender       string          `json:"tender"`
+	Amount       decimal.Decimal `json:"amount"`
+	GiftCardCode string          `json:"gift_card_code"`
+}
+
+func NewPaymentHandler(u domain.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{PaymentUsecase: u}
+}
+
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req paymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	payment := &domain.Payment{Tender: req.Tender, Amount: req.Amount, GiftCardCode: req.GiftCardCode}
+	balance, err := h.PaymentUsecase.Pay(c.Request.Context(), id, payment)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidTender), errors.Is(err, usecase.ErrInvalidPaymentAmount),
+			errors.Is(err, usecase.ErrGiftCardCodeRequired), errors.Is(err, domain.ErrPaymentExceedsDue),
+			errors.Is(err, domain.ErrGiftCardExpired), errors.Is(err, domain.ErrInsufficientGiftCardBalance):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOrderNotPayable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Order was changed by another request"})
+		case errors.Is(err, usecase.ErrGiftCardNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record payment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, balance)
+}
+
+func (h *PaymentHandler) GetBalance(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	balance, err := h.PaymentUsecase.GetBalance(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
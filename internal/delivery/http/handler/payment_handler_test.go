@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPaymentUsecase struct{ mock.Mock }
+
+func (m *mockPaymentUsecase) Pay(ctx context.Context, orderID uuid.UUID, payment *domain.Payment) (*domain.OrderBalance, error) {
+	args := m.Called(ctx, orderID, payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OrderBalance), args.Error(1)
+}
+func (m *mockPaymentUsecase) GetBalance(ctx context.Context, orderID uuid.UUID) (*domain.OrderBalance, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OrderBalance), args.Error(1)
+}
+
+func TestPaymentHandler_Pay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mockPaymentUsecase)
+		h := NewPaymentHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/orders/:id/payments", h.Pay)
+
+		id := uuid.New()
+		mockUsecase.On("Pay", mock.Anything, id, mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Tender == domain.TenderGiftCard && p.Amount.Equal(decimal.NewFromFloat(4.5)) && p.GiftCardCode == "ABCD"
+		})).Return(&domain.OrderBalance{OrderID: id, Status: domain.OrderStatusPending, Due: decimal.NewFromInt(2)}, nil)
+
+		body, _ := json.Marshal(map[string]any{"tender": "gift_card", "amount": "4.50", "gift_card_code": "ABCD"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/payments", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("order already paid", func(t *testing.T) {
+		mockUsecase := new(mockPaymentUsecase)
+		h := NewPaymentHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/orders/:id/payments", h.Pay)
+
+		id := uuid.New()
+		mockUsecase.On("Pay", mock.Anything, id, mock.Anything).Return(nil, domain.ErrOrderNotPayable)
+
+		body, _ := json.Marshal(map[string]any{"tender": "cash", "amount": 1})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/payments", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("insufficient gift card balance", func(t *testing.T) {
+		mockUsecase := new(mockPaymentUsecase)
+		h := NewPaymentHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/orders/:id/payments", h.Pay)
+
+		id := uuid.New()
+		mockUsecase.On("Pay", mock.Anything, id, mock.Anything).Return(nil, domain.ErrInsufficientGiftCardBalance)
+
+		body, _ := json.Marshal(map[string]any{"tender": "gift_card", "amount": 100, "gift_card_code": "ABCD"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/"+id.String()+"/payments", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
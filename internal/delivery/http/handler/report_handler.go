@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type ReportHandler struct {
+	ReportUsecase domain.ReportUsecase
+}
+
+func NewReportHandler(u domain.ReportUsecase) *ReportHandler {
+	return &ReportHandler{ReportUsecase: u}
+}
+
+func (h *ReportHandler) MenuItemCosts(c *gin.Context) {
+	costs, err := h.ReportUsecase.MenuItemCosts(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute menu item costs"})
+		return
+	}
+	c.JSON(http.StatusOK, costs)
+}
+
+func (h *ReportHandler) Margins(c *gin.Context) {
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be dates in YYYY-MM-DD format"})
+		return
+	}
+
+	report, err := h.ReportUsecase.MarginReport(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidDateRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build margin report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseReportRange reads the inclusive from/to dates of a report query and
+// returns them as a half-open [from, to) range.
+func parseReportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, err := time.Parse(reportDateLayout, c.Query("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse(reportDateLayout, c.Query("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to.AddDate(0, 0, 1), true
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportUsecase struct{ mock.Mock }
+
+func (m *mockReportUsecase) MenuItemCosts(ctx context.Context) ([]domain.MenuItemCost, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.MenuItemCost), args.Error(1)
+}
+func (m *mockReportUsecase) MarginReport(ctx context.Context, from, to time.Time) (*domain.MarginReport, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MarginReport), args.Error(1)
+}
+
+func TestReportHandler_Margins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockReportUsecase)
+	h := NewReportHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/reports/margins", h.Margins)
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	mockUsecase.On("MarginReport", mock.Anything, from, to).Return(&domain.MarginReport{From: from, To: to}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/reports/margins?from=2026-03-01&to=2026-03-31", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestReportHandler_Margins_InvalidDates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockReportUsecase)
+	h := NewReportHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/reports/margins", h.Margins)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/reports/margins?from=yesterday", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertNotCalled(t, "MarginReport")
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StaffHandler struct {
+	StaffUsecase domain.StaffUsecase
+}
+
+type staffRequest struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type pinRequest struct {
+	PIN string `json:"pin"`
+}
+
+func NewStaffHandler(u domain.StaffUsecase) *StaffHandler {
+	return &StaffHandler{StaffUsecase: u}
+}
+
+func (h *StaffHandler) Create(c *gin.Context) {
+	var req staffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	staff := req.toStaff()
+	if err := h.StaffUsecase.Create(c.Request.Context(), staff, req.Password); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidUsername),
+			errors.Is(err, usecase.ErrInvalidStaffName),
+			errors.Is(err, usecase.ErrPasswordTooShort),
+			errors.Is(err, usecase.ErrPasswordTooLong),
+			errors.Is(err, usecase.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAlreadyExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Username is already taken"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create staff member"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, staff)
+}
+
+func (h *StaffHandler) List(c *gin.Context) {
+	staff, err := h.StaffUsecase.List(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staff"})
+		return
+	}
+	c.JSON(http.StatusOK, staff)
+}
+
+func (h *StaffHandler) SetPIN(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req pinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.StaffUsecase.SetPIN(c.Request.Context(), id, req.PIN); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidPIN):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Staff member not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set PIN"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (r staffRequest) toStaff() *domain.Staff {
+	return &domain.Staff{
+		Username: r.Username,
+		Name:     r.Name,
+		Role:     r.Role,
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStaffUsecase struct{ mock.Mock }
+
+func (m *mockStaffUsecase) Create(ctx context.Context, staff *domain.Staff, password string) error {
+	args := m.Called(ctx, staff, password)
+	return args.Error(0)
+}
+func (m *mockStaffUsecase) List(ctx context.Context) ([]domain.Staff, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Staff), args.Error(1)
+}
+func (m *mockStaffUsecase) SetPIN(ctx context.Context, id uuid.UUID, pin string) error {
+	args := m.Called(ctx, id, pin)
+	return args.Error(0)
+}
+func (m *mockStaffUsecase) EnsureAdmin(ctx context.Context, username, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func TestStaffHandler_Create_HidesPasswordHash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockStaffUsecase)
+	h := NewStaffHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/staff", h.Create)
+
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Staff) bool {
+		return s.Role == domain.RoleBarista
+	}), "password1").Run(func(args mock.Arguments) {
+		staff := args.Get(1).(*domain.Staff)
+		staff.ID = uuid.New()
+		staff.PasswordHash = "$2a$10$hash"
+	}).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "sam", "name": "Sam", "password": "password1", "role": domain.RoleBarista})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/staff", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "hash")
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestStaffHandler_Create_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockStaffUsecase)
+	h := NewStaffHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/staff", h.Create)
+
+	mockUsecase.On("Create", mock.Anything, mock.Anything, "short").Return(usecase.ErrPasswordTooShort)
+	mockUsecase.On("Create", mock.Anything, mock.Anything, "password1").Return(domain.ErrAlreadyExists)
+	mockUsecase.On("Create", mock.Anything, mock.Anything, "password2").Return(domain.ErrForbidden)
+
+	for password, status := range map[string]int{"short": http.StatusBadRequest, "password1": http.StatusConflict, "password2": http.StatusForbidden} {
+		body, _ := json.Marshal(map[string]string{"username": "sam", "name": "Sam", "password": password})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/staff", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func TestStaffHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockStaffUsecase)
+	h := NewStaffHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/staff", h.List)
+
+	mockUsecase.On("List", mock.Anything).Return([]domain.Staff{{ID: uuid.New(), Username: "sam"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/staff", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestStaffHandler_SetPIN(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockStaffUsecase)
+	h := NewStaffHandler(mockUsecase)
+	r := gin.Default()
+	r.PUT("/api/v1/staff/:id/pin", h.SetPIN)
+
+	id := uuid.New()
+	mockUsecase.On("SetPIN", mock.Anything, id, "4821").Return(nil)
+	mockUsecase.On("SetPIN", mock.Anything, id, "12").Return(usecase.ErrInvalidPIN)
+
+	for pin, status := range map[string]int{"4821": http.StatusNoContent, "12": http.StatusBadRequest} {
+		body, _ := json.Marshal(map[string]string{"pin": pin})
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/staff/"+id.String()+"/pin", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+}
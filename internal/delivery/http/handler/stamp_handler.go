@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StampHandler struct {
+	StampUsecase domain.StampUsecase
+}
+
+type stampProgramRequest struct {
+	Name           string   `json:"name"`
+	Categories     []string `json:"categories"`
+	StampsRequired int      `json:"stamps_required"`
+	Active         *bool    `json:"active"`
+}
+
+func NewStampHandler(u domain.StampUsecase) *StampHandler {
+	return &StampHandler{StampUsecase: u}
+}
+
+func (h *StampHandler) CreateProgram(c *gin.Context) {
+	var req stampProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	program := req.toProgram()
+	if err := h.StampUsecase.CreateProgram(c.Request.Context(), program); err != nil {
+		writeStampError(c, err, "Failed to create stamp program")
+		return
+	}
+
+	c.JSON(http.StatusCreated, program)
+}
+
+func (h *StampHandler) ListPrograms(c *gin.Context) {
+	programs, err := h.StampUsecase.ListPrograms(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stamp programs"})
+		return
+	}
+	c.JSON(http.StatusOK, programs)
+}
+
+func (h *StampHandler) UpdateProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req stampProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	program := req.toProgram()
+	program.ID = id
+	if err := h.StampUsecase.UpdateProgram(c.Request.Context(), program); err != nil {
+		writeStampError(c, err, "Failed to update stamp program")
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+func (h *StampHandler) GetCards(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	cards, err := h.StampUsecase.GetCards(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stamp cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+// toProgram builds a program from the request; programs are active unless the
+// request says otherwise.
+func (r stampProgramRequest) toProgram() *domain.StampProgram {
+	program := &domain.StampProgram{
+		Name:           r.Name,
+		Categories:     r.Categories,
+		StampsRequired: r.StampsRequired,
+		Active:         true,
+	}
+	if r.Active != nil {
+		program.Active = *r.Active
+	}
+	return program
+}
+
+func writeStampError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidStampProgramName),
+		errors.Is(err, usecase.ErrInvalidStampsRequired),
+		errors.Is(err, usecase.ErrEmptyStampCategories):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stamp program not found"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
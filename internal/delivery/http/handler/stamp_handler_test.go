@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStampUsecase struct{ mock.Mock }
+
+func (m *mockStampUsecase) CreateProgram(ctx context.Context, program *domain.StampProgram) error {
+	args := m.Called(ctx, program)
+	return args.Error(0)
+}
+func (m *mockStampUsecase) ListPrograms(ctx context.Context) ([]domain.StampProgram, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.StampProgram), args.Error(1)
+}
+func (m *mockStampUsecase) UpdateProgram(ctx context.Context, program *domain.StampProgram) error {
+	args := m.Called(ctx, program)
+	return args.Error(0)
+}
+func (m *mockStampUsecase) GetCards(ctx context.Context, customerID uuid.UUID) ([]domain.StampCard, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StampCard), args.Error(1)
+}
+func (m *mockStampUsecase) ApplyRewards(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockStampUsecase) RedeemRewards(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockStampUsecase) StampOrder(ctx context.Context, order *domain.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+func (m *mockStampUsecase) ReverseLine(ctx context.Context, order *domain.Order, itemID uuid.UUID) ([]domain.StampEntry, error) {
+	args := m.Called(ctx, order, itemID)
+	return args.Get(0).([]domain.StampEntry), args.Error(1)
+}
+func (m *mockStampUsecase) ReverseOrder(ctx context.Context, orderID uuid.UUID) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func TestStampHandler_CreateProgram(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("defaults to active", func(t *testing.T) {
+		mockUsecase := new(mockStampUsecase)
+		h := NewStampHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/stamp-programs", h.CreateProgram)
+
+		mockUsecase.On("CreateProgram", mock.Anything, mock.MatchedBy(func(p *domain.StampProgram) bool {
+			return p.Active && p.StampsRequired == 9 && len(p.Categories) == 1
+		})).Return(nil)
+
+		body, _ := json.Marshal(map[string]any{"name": "Hot coffee", "categories": []string{"Hot Coffee"}, "stamps_required": 9})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/stamp-programs", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		mockUsecase := new(mockStampUsecase)
+		h := NewStampHandler(mockUsecase)
+		r := gin.Default()
+		r.POST("/api/v1/stamp-programs", h.CreateProgram)
+
+		mockUsecase.On("CreateProgram", mock.Anything, mock.Anything).Return(usecase.ErrInvalidStampsRequired)
+
+		body, _ := json.Marshal(map[string]any{"name": "Hot coffee", "categories": []string{"Hot Coffee"}})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/stamp-programs", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStampHandler_GetCards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockStampUsecase)
+	h := NewStampHandler(mockUsecase)
+	r := gin.Default()
+	r.GET("/api/v1/customers/:id/stamp-cards", h.GetCards)
+
+	id := uuid.New()
+	mockUsecase.On("GetCards", mock.Anything, id).Return([]domain.StampCard{{ProgramName: "Hot coffee", Stamps: 9, StampsRequired: 9, Full: true}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers/"+id.String()+"/stamp-cards", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var cards []domain.StampCard
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cards))
+	assert.True(t, cards[0].Full)
+}
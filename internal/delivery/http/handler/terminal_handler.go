@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"coffee-shop-pos/internal/domain"
+	"coffee-shop-pos/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// terminalTokenHeader carries the device token of a registered POS terminal.
+const terminalTokenHeader = "X-Terminal-Token"
+
+type TerminalHandler struct {
+	TerminalUsecase domain.TerminalUsecase
+}
+
+type terminalRequest struct {
+	Name string `json:"name"`
+}
+
+type pinLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	PIN      string `json:"pin" binding:"required"`
+}
+
+func NewTerminalHandler(u domain.TerminalUsecase) *TerminalHandler {
+	return &TerminalHandler{TerminalUsecase: u}
+}
+
+func (h *TerminalHandler) Register(c *gin.Context) {
+	var req terminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	terminal := &domain.Terminal{Name: req.Name}
+	if err := h.TerminalUsecase.Register(c.Request.Context(), terminal); err != nil {
+		writeTerminalError(c, err, "Failed to register terminal")
+		return
+	}
+	c.JSON(http.StatusCreated, terminal)
+}
+
+func (h *TerminalHandler) List(c *gin.Context) {
+	terminals, err := h.TerminalUsecase.List(c.Request.Context())
+	if err != nil {
+		writeTerminalError(c, err, "Failed to fetch terminals")
+		return
+	}
+	c.JSON(http.StatusOK, terminals)
+}
+
+func (h *TerminalHandler) Deactivate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.TerminalUsecase.Deactivate(c.Request.Context(), id); err != nil {
+		writeTerminalError(c, err, "Failed to deactivate terminal")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TerminalHandler) PINLogin(c *gin.Context) {
+	deviceToken := c.GetHeader(terminalTokenHeader)
+	if deviceToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Terminal token required"})
+		return
+	}
+
+	var req pinLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	login, err := h.TerminalUsecase.PINLogin(c.Request.Context(), deviceToken, req.Username, req.PIN)
+	if err != nil {
+		writeTerminalError(c, err, "Failed to sign in")
+		return
+	}
+	c.JSON(http.StatusOK, login)
+}
+
+func (h *TerminalHandler) Logout(c *gin.Context) {
+	_, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if err := h.TerminalUsecase.Logout(c.Request.Context(), strings.TrimSpace(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeTerminalError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTerminalName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTerminal), errors.Is(err, domain.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Terminal not found"})
+	case errors.Is(err, domain.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Another sign-in on this terminal is in progress"})
+	case errors.Is(err, domain.ErrPINLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTerminalUsecase struct{ mock.Mock }
+
+func (m *mockTerminalUsecase) Authenticate(ctx context.Context, token string) (*domain.Identity, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Identity), args.Error(1)
+}
+func (m *mockTerminalUsecase) Register(ctx context.Context, terminal *domain.Terminal) error {
+	args := m.Called(ctx, terminal)
+	return args.Error(0)
+}
+func (m *mockTerminalUsecase) List(ctx context.Context) ([]domain.Terminal, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Terminal), args.Error(1)
+}
+func (m *mockTerminalUsecase) Deactivate(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *mockTerminalUsecase) PINLogin(ctx context.Context, deviceToken, username, pin string) (*domain.TerminalLogin, error) {
+	args := m.Called(ctx, deviceToken, username, pin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TerminalLogin), args.Error(1)
+}
+func (m *mockTerminalUsecase) Logout(ctx context.Context, sessionToken string) error {
+	args := m.Called(ctx, sessionToken)
+	return args.Error(0)
+}
+
+func TestTerminalHandler_Register(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockTerminalUsecase)
+	h := NewTerminalHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/terminals", h.Register)
+
+	mockUsecase.On("Register", mock.Anything, mock.MatchedBy(func(term *domain.Terminal) bool {
+		return term.Name == "Front counter"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Terminal).DeviceToken = "term_secret"
+	}).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"name": "Front counter"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/terminals", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "term_secret")
+}
+
+func TestTerminalHandler_PINLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockTerminalUsecase)
+	h := NewTerminalHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/terminal/login", h.PINLogin)
+
+	mockUsecase.On("PINLogin", mock.Anything, "term_device", "sam", "4821").Return(&domain.TerminalLogin{SessionToken: "pos_session"}, nil)
+	mockUsecase.On("PINLogin", mock.Anything, "term_device", "sam", "0000").Return(nil, domain.ErrPINLocked)
+
+	for pin, status := range map[string]int{"4821": http.StatusOK, "0000": http.StatusLocked} {
+		body, _ := json.Marshal(map[string]string{"username": "sam", "pin": pin})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/terminal/login", bytes.NewBuffer(body))
+		req.Header.Set("X-Terminal-Token", "term_device")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func TestTerminalHandler_PINLogin_RequiresTerminalToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockTerminalUsecase)
+	h := NewTerminalHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/terminal/login", h.PINLogin)
+
+	body, _ := json.Marshal(map[string]string{"username": "sam", "pin": "4821"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/terminal/login", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUsecase.AssertNotCalled(t, "PINLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTerminalHandler_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mockTerminalUsecase)
+	h := NewTerminalHandler(mockUsecase)
+	r := gin.Default()
+	r.POST("/api/v1/terminal/logout", h.Logout)
+
+	mockUsecase.On("Logout", mock.Anything, "pos_session").Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/terminal/logout", nil)
+	req.Header.Set("Authorization", "Bearer pos_session")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate returns a middleware that requires a valid "Authorization: Bearer"
+// token, accepted by the first of authenticators that recognises it. The
+// authenticated staff member is stored in the request context (see
+// domain.IdentityFromContext); requests without one are rejected with 401.
+func Authenticate(authenticators ...domain.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		identity, err := authenticate(c, authenticators, strings.TrimSpace(token))
+		if errors.Is(err, domain.ErrInvalidToken) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.WithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, authenticators []domain.Authenticator, token string) (*domain.Identity, error) {
+	for _, auth := range authenticators {
+		identity, err := auth.Authenticate(c.Request.Context(), token)
+		if errors.Is(err, domain.ErrInvalidToken) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+// RequirePermission returns a middleware that lets a request through only if the
+// authenticated staff member's role grants at least one of permissions. It must
+// run after Authenticate; anything else is rejected with 403 Forbidden.
+func RequirePermission(permissions ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := domain.IdentityFromContext(c.Request.Context())
+		if ok {
+			for _, permission := range permissions {
+				if identity.Can(permission) {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	}
+}
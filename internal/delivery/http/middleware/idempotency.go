@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader marks a response replayed from an earlier request.
+	IdempotentReplayHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the response headers stored with the body and sent
+// again on a replay.
+var replayedHeaders = []string{"Location", "ETag"}
+
+// responseRecorder keeps a copy of the body written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency returns a middleware that lets clients safely retry a request by
+// sending the same Idempotency-Key header. The first successful response is
+// stored and replayed for retries, along with its Location and ETag headers; a
+// key reused with a different method, path or body is rejected with 422. Failed
+// requests are not stored, so they can be retried with the same key.
+func Idempotency(idempotencyUsecase domain.IdempotencyUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		record, err := idempotencyUsecase.Begin(ctx, key, fingerprint)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrIdempotencyKeyReused):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrIdempotencyInProgress):
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrForbidden):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check idempotency key"})
+			}
+			return
+		}
+		if record.CompletedAt != nil {
+			var headers map[string]string
+			if len(record.Headers) > 0 {
+				if err := json.Unmarshal(record.Headers, &headers); err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check idempotency key"})
+					return
+				}
+			}
+			for name, value := range headers {
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayHeader, "true")
+			c.Data(*record.StatusCode, "application/json; charset=utf-8", record.Response)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusOK && status < http.StatusMultipleChoices {
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				if value := c.Writer.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			err = idempotencyUsecase.Complete(ctx, record, status, headers, recorder.body.Bytes())
+		} else {
+			err = idempotencyUsecase.Release(ctx, record)
+		}
+		if err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers a request uses to carry a manager's on-the-spot approval.
+const (
+	ManagerUsernameHeader = "X-Manager-Username"
+	ManagerPINHeader      = "X-Manager-PIN"
+	ApprovalTokenHeader   = "X-Approval-Token"
+)
+
+// ManagerOverride returns a middleware that passes manager approval headers to
+// the usecases (see domain.OverrideFromContext), which decide whether the
+// approval is needed and valid.
+func ManagerOverride() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credentials := &domain.OverrideCredentials{
+			ManagerUsername: c.GetHeader(ManagerUsernameHeader),
+			PIN:             c.GetHeader(ManagerPINHeader),
+			ApprovalToken:   c.GetHeader(ApprovalTokenHeader),
+		}
+		if credentials.ApprovalToken != "" || credentials.ManagerUsername != "" {
+			c.Request = c.Request.WithContext(domain.WithOverride(c.Request.Context(), credentials))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"regexp"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID that ties a request to its audit log entries.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID keeps client-supplied IDs short and printable.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,100}$`)
+
+// RequestID returns a middleware that gives every request an ID, reusing the
+// client's X-Request-ID when it is sensible, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(domain.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
@@ -3,30 +3,142 @@ package http
 import (
 	"coffee-shop-pos/internal/delivery/http/handler"
 	"coffee-shop-pos/internal/delivery/http/middleware"
+	"coffee-shop-pos/internal/domain"
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(r *gin.Engine, menuHandler *handler.MenuHandler, orderHandler *handler.OrderHandler) {
+func NewRouter(r *gin.Engine, menuHandler *handler.MenuHandler, orderHandler *handler.OrderHandler, inventoryHandler *handler.InventoryHandler, reportHandler *handler.ReportHandler, customerHandler *handler.CustomerHandler, loyaltyHandler *handler.LoyaltyHandler, stampHandler *handler.StampHandler, paymentHandler *handler.PaymentHandler, giftCardHandler *handler.GiftCardHandler, authHandler *handler.AuthHandler, staffHandler *handler.StaffHandler, terminalHandler *handler.TerminalHandler, overrideHandler *handler.OverrideHandler, apiKeyHandler *handler.APIKeyHandler, auditHandler *handler.AuditHandler, authUsecase domain.AuthUsecase, terminalUsecase domain.TerminalUsecase, apiKeyUsecase domain.APIKeyUsecase, idempotencyUsecase domain.IdempotencyUsecase) {
+	r.Use(middleware.RequestID())
 	r.Use(middleware.SecurityHeaders())
 	r.Use(middleware.BodySizeLimit())
 
 	api := r.Group("/api/v1")
 	{
-		menu := api.Group("/menu")
+		auth := api.Group("/auth")
 		{
-			menu.POST("", menuHandler.Create)
-			menu.GET("", menuHandler.Fetch)
-			menu.GET("/:id", menuHandler.GetByID)
-			menu.PUT("/:id", menuHandler.Update)
-			menu.DELETE("/:id", menuHandler.Delete)
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
 		}
 
-		orders := api.Group("/orders")
+		// PIN login is only accepted from a registered terminal.
+		api.POST("/terminal/login", terminalHandler.PINLogin)
+	}
+
+	// Everything else requires a logged-in staff member, either with a JWT or a
+	// terminal session, or an API key limited to its scopes. Any request may
+	// also carry a manager's approval for actions the caller cannot do alone.
+	protected := api.Group("", middleware.Authenticate(authUsecase, terminalUsecase, apiKeyUsecase), middleware.ManagerOverride())
+	{
+		protected.GET("/auth/me", authHandler.Me)
+		protected.POST("/terminal/logout", terminalHandler.Logout)
+
+		staff := protected.Group("/staff")
+		{
+			staff.POST("", middleware.RequirePermission(domain.PermStaffManage), staffHandler.Create)
+			staff.GET("", middleware.RequirePermission(domain.PermStaffManage), staffHandler.List)
+			// Staff can set their own PIN; the usecase checks whose it is.
+			staff.PUT("/:id/pin", staffHandler.SetPIN)
+		}
+
+		terminals := protected.Group("/terminals", middleware.RequirePermission(domain.PermTerminalsManage))
+		{
+			terminals.POST("", terminalHandler.Register)
+			terminals.GET("", terminalHandler.List)
+			terminals.DELETE("/:id", terminalHandler.Deactivate)
+		}
+
+		apiKeys := protected.Group("/api-keys", middleware.RequirePermission(domain.PermAPIKeysManage))
+		{
+			apiKeys.POST("", apiKeyHandler.Create)
+			apiKeys.GET("", apiKeyHandler.List)
+			apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+		}
+
+		protected.GET("/audit", middleware.RequirePermission(domain.PermAuditRead), auditHandler.List)
+
+		approvals := protected.Group("/approvals")
+		{
+			// The usecase checks the manager may approve the requested action.
+			approvals.POST("", overrideHandler.IssueToken)
+			approvals.GET("", middleware.RequirePermission(domain.PermReportsRead), overrideHandler.List)
+		}
+
+		menu := protected.Group("/menu")
+		{
+			menu.POST("", middleware.RequirePermission(domain.PermMenuWrite), menuHandler.Create)
+			menu.GET("", middleware.RequirePermission(domain.PermMenuRead), menuHandler.Fetch)
+			menu.GET("/:id", middleware.RequirePermission(domain.PermMenuRead), menuHandler.GetByID)
+			menu.PUT("/:id", middleware.RequirePermission(domain.PermMenuWrite), menuHandler.Update)
+			menu.DELETE("/:id", middleware.RequirePermission(domain.PermMenuWrite), menuHandler.Delete)
+		}
+
+		// Creating orders, taking payments and refunding (cancelling a paid order)
+		// can be retried safely with an Idempotency-Key.
+		idempotent := middleware.Idempotency(idempotencyUsecase)
+
+		orders := protected.Group("/orders")
+		{
+			orders.POST("", middleware.RequirePermission(domain.PermOrdersCreate), idempotent, orderHandler.Create)
+			orders.GET("", middleware.RequirePermission(domain.PermOrdersRead), orderHandler.List)
+			orders.GET("/:id", middleware.RequirePermission(domain.PermOrdersRead), orderHandler.GetByID)
+			orders.POST("/:id/items", middleware.RequirePermission(domain.PermOrdersCreate), idempotent, orderHandler.AddItem)
+			orders.POST("/:id/rounds", middleware.RequirePermission(domain.PermOrdersCreate), idempotent, orderHandler.AddRound)
+			orders.POST("/:id/tab", middleware.RequirePermission(domain.PermOrdersCreate), orderHandler.OpenTab)
+			orders.POST("/:id/split", middleware.RequirePermission(domain.PermOrdersCreate), idempotent, orderHandler.Split)
+			orders.POST("/:id/merge", middleware.RequirePermission(domain.PermOrdersCreate), idempotent, orderHandler.Merge)
+			orders.POST("/:id/transfer", middleware.RequirePermission(domain.PermOrdersCreate), orderHandler.Transfer)
+			orders.PATCH("/:id/items/:item_id", middleware.RequirePermission(domain.PermOrdersCreate), orderHandler.UpdateItem)
+			orders.DELETE("/:id/items/:item_id", middleware.RequirePermission(domain.PermOrdersCreate), orderHandler.RemoveItem)
+			// Voiding needs a manager, but cashiers may get one to approve it on
+			// the spot, so the usecase makes the final call.
+			orders.POST("/:id/items/:item_id/void", middleware.RequirePermission(domain.PermOrdersCreate, domain.PermOrdersRefund), idempotent, orderHandler.VoidItem)
+			// Which status change is allowed depends on the order, so the usecase
+			// makes the final call.
+			orders.PATCH("/:id/status", middleware.RequirePermission(domain.PermPaymentsTake, domain.PermOrdersPrepare, domain.PermOrdersCancel), idempotent, orderHandler.UpdateStatus)
+			orders.POST("/:id/payments", middleware.RequirePermission(domain.PermPaymentsTake), idempotent, paymentHandler.Pay)
+			orders.GET("/:id/payments", middleware.RequirePermission(domain.PermOrdersRead), paymentHandler.GetBalance)
+			orders.GET("/:id/gift-cards", middleware.RequirePermission(domain.PermOrdersRead), giftCardHandler.ListForOrder)
+		}
+
+		customers := protected.Group("/customers")
+		{
+			customers.POST("", middleware.RequirePermission(domain.PermCustomersWrite), customerHandler.Create)
+			customers.GET("", middleware.RequirePermission(domain.PermCustomersRead), customerHandler.Search)
+			customers.GET("/:id", middleware.RequirePermission(domain.PermCustomersRead), customerHandler.GetByID)
+			customers.PUT("/:id", middleware.RequirePermission(domain.PermCustomersWrite), customerHandler.Update)
+			customers.DELETE("/:id", middleware.RequirePermission(domain.PermCustomersWrite), customerHandler.Delete)
+			customers.GET("/:id/orders", middleware.RequirePermission(domain.PermCustomersRead), customerHandler.OrderHistory)
+			customers.GET("/:id/loyalty", middleware.RequirePermission(domain.PermCustomersRead), loyaltyHandler.GetAccount)
+			customers.GET("/:id/stamp-cards", middleware.RequirePermission(domain.PermCustomersRead), stampHandler.GetCards)
+		}
+
+		protected.GET("/gift-cards/:code", middleware.RequirePermission(domain.PermOrdersRead), giftCardHandler.GetByCode)
+
+		stampPrograms := protected.Group("/stamp-programs")
+		{
+			stampPrograms.POST("", middleware.RequirePermission(domain.PermLoyaltyManage), stampHandler.CreateProgram)
+			stampPrograms.GET("", middleware.RequirePermission(domain.PermCustomersRead), stampHandler.ListPrograms)
+			stampPrograms.PUT("/:id", middleware.RequirePermission(domain.PermLoyaltyManage), stampHandler.UpdateProgram)
+		}
+
+		inventory := protected.Group("/inventory")
+		{
+			inventory.POST("/ingredients", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.CreateIngredient)
+			inventory.GET("/ingredients", middleware.RequirePermission(domain.PermInventoryRead), inventoryHandler.ListIngredients)
+			inventory.GET("/recipes/:menu_item_id", middleware.RequirePermission(domain.PermInventoryRead), inventoryHandler.GetRecipe)
+			inventory.PUT("/recipes/:menu_item_id", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.SetRecipe)
+			inventory.POST("/movements", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.RecordMovement)
+			inventory.POST("/counts", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.StartStockCount)
+			inventory.GET("/counts/:id", middleware.RequirePermission(domain.PermInventoryRead), inventoryHandler.GetStockCount)
+			inventory.PUT("/counts/:id/lines", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.RecordCountedQuantities)
+			inventory.GET("/counts/:id/variance", middleware.RequirePermission(domain.PermInventoryRead), inventoryHandler.VarianceReport)
+			inventory.POST("/counts/:id/apply", middleware.RequirePermission(domain.PermInventoryWrite), inventoryHandler.ApplyStockCount)
+		}
+
+		reports := protected.Group("/reports", middleware.RequirePermission(domain.PermReportsRead))
 		{
-			orders.POST("", orderHandler.Create)
-			orders.GET("", orderHandler.List)
-			orders.GET("/:id", orderHandler.GetByID)
-			orders.PATCH("/:id/status", orderHandler.UpdateStatus)
+			reports.GET("/menu-costs", reportHandler.MenuItemCosts)
+			reports.GET("/margins", reportHandler.Margins)
 		}
 	}
 }
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions.
+const (
+	AuditMenuItemCreate    = "menu_item.create"
+	AuditMenuItemUpdate    = "menu_item.update"
+	AuditMenuItemDelete    = "menu_item.delete"
+	AuditOrderStatusChange = "order.status_change"
+	AuditOrderItemVoid     = "order.item_void"
+	AuditOrderMerge        = "order.merge"
+	AuditOrderTransfer     = "order.transfer"
+	AuditOrderRelease      = "order.release"
+)
+
+// Audited entity types.
+const (
+	AuditEntityMenuItem = "menu_item"
+	AuditEntityOrder    = "order"
+)
+
+// AuditEntry records one change. Before and After only hold the fields that
+// changed: After is empty for deletes and Before for creates.
+type AuditEntry struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	APIKeyID   *uuid.UUID      `json:"api_key_id,omitempty" db:"api_key_id"`
+	ActorName  string          `json:"actor_name" db:"actor_name"`
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter narrows an audit log query. Zero fields are ignored; From is
+// inclusive and To exclusive.
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the HTTP request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID stored by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// AuditRepository only ever appends; the table refuses updates and deletes.
+type AuditRepository interface {
+	Record(ctx context.Context, entry *AuditEntry) error
+	List(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+type AuditUsecase interface {
+	// Entry builds the log entry for action on an entity by the caller in ctx,
+	// for the repository to write in the same transaction as the change.
+	// before and after are snapshots of the entity (nil for a create or
+	// delete) and are reduced to the fields that differ.
+	Entry(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) (*AuditEntry, error)
+	List(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Customer struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	Name             string    `json:"name" db:"name"`
+	Phone            string    `json:"phone" db:"phone"`
+	Email            string    `json:"email" db:"email"`
+	MarketingConsent bool      `json:"marketing_consent" db:"marketing_consent"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CustomerFilter narrows a customer search. Empty fields are ignored.
+type CustomerFilter struct {
+	Phone string
+	Email string
+}
+
+type CustomerRepository interface {
+	Create(ctx context.Context, customer *Customer) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Customer, error)
+	Search(ctx context.Context, filter CustomerFilter) ([]Customer, error)
+	Update(ctx context.Context, customer *Customer) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type CustomerUsecase interface {
+	Create(ctx context.Context, customer *Customer) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Customer, error)
+	Search(ctx context.Context, filter CustomerFilter) ([]Customer, error)
+	Update(ctx context.Context, customer *Customer) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	OrderHistory(ctx context.Context, customerID uuid.UUID) ([]Order, error)
+}
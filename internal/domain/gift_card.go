@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// GiftCardCategory is the menu category of items that sell gift cards. Each
+// unit sold issues a card worth its price, or reloads the card named on the
+// line.
+const GiftCardCategory = "Gift Cards"
+
+var (
+	ErrGiftCardExpired             = errors.New("gift card has expired")
+	ErrInsufficientGiftCardBalance = errors.New("insufficient gift card balance")
+)
+
+const (
+	GiftCardIssue  = "issue"
+	GiftCardReload = "reload"
+	GiftCardRedeem = "redeem"
+	GiftCardRefund = "refund"
+	GiftCardVoid   = "void"
+)
+
+type GiftCard struct {
+	ID            uuid.UUID             `json:"id" db:"id"`
+	Code          string                `json:"code" db:"code"`
+	InitialValue  decimal.Decimal       `json:"initial_value" db:"initial_value"`
+	Balance       decimal.Decimal       `json:"balance" db:"balance"`
+	ExpiresAt     *time.Time            `json:"expires_at,omitempty" db:"expires_at"`
+	Expired       bool                  `json:"expired" db:"-"`
+	IssuedOrderID *uuid.UUID            `json:"issued_order_id,omitempty" db:"issued_order_id"`
+	Transactions  []GiftCardTransaction `json:"transactions,omitempty" db:"-"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// GiftCardTransaction is an immutable line of the gift card ledger. Amount is
+// positive for credits and negative for debits; BalanceAfter is the card
+// balance once the line was applied.
+type GiftCardTransaction struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	GiftCardID   uuid.UUID       `json:"gift_card_id" db:"gift_card_id"`
+	OrderID      *uuid.UUID      `json:"order_id,omitempty" db:"order_id"`
+	Type         string          `json:"type" db:"type"`
+	Amount       decimal.Decimal `json:"amount" db:"amount"`
+	BalanceAfter decimal.Decimal `json:"balance_after" db:"balance_after"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+type GiftCardRepository interface {
+	// Create stores a new card together with its issue transaction.
+	Create(ctx context.Context, card *GiftCard, issue *GiftCardTransaction) error
+	GetByCode(ctx context.Context, code string) (*GiftCard, error)
+	ListByIssuedOrder(ctx context.Context, orderID uuid.UUID) ([]GiftCard, error)
+	ListTransactions(ctx context.Context, cardID uuid.UUID) ([]GiftCardTransaction, error)
+	ListOrderTransactions(ctx context.Context, orderID uuid.UUID) ([]GiftCardTransaction, error)
+	// Credit adds a positive transaction to a card, moving its expiry when
+	// expiresAt is set.
+	Credit(ctx context.Context, entry *GiftCardTransaction, expiresAt *time.Time) error
+	// Void debits up to -entry.Amount, never taking the balance below zero.
+	Void(ctx context.Context, entry *GiftCardTransaction) error
+}
+
+type GiftCardUsecase interface {
+	GetByCode(ctx context.Context, code string) (*GiftCard, error)
+	ListForOrder(ctx context.Context, orderID uuid.UUID) ([]GiftCard, error)
+	IssueForOrder(ctx context.Context, order *Order) error
+	RefundOrder(ctx context.Context, orderID uuid.UUID) error
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("idempotency key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key so a
+// retry of it gets the original response instead of running again. Keys are
+// scoped to the staff member or API key that sent them.
+type IdempotencyRecord struct {
+	OwnerID uuid.UUID `db:"owner_id"`
+	Key     string    `db:"idempotency_key"`
+	// Fingerprint identifies the request the key was first used for.
+	Fingerprint string `db:"fingerprint"`
+	// LeaseID identifies the request holding the key. A request that is still
+	// running at LockedUntil is presumed lost and a retry takes the key over
+	// under a new lease.
+	LeaseID     uuid.UUID `db:"lease_id"`
+	LockedUntil time.Time `db:"locked_until"`
+	// StatusCode, Headers, Response and CompletedAt are set once the request
+	// finished. Headers maps the replayed header names to their values.
+	StatusCode  *int            `db:"status_code"`
+	Headers     json.RawMessage `db:"headers"`
+	Response    []byte          `db:"response"`
+	CreatedAt   time.Time       `db:"created_at"`
+	CompletedAt *time.Time      `db:"completed_at"`
+	ExpiresAt   time.Time       `db:"expires_at"`
+}
+
+type IdempotencyRepository interface {
+	// Reserve stores record unless its owner already has an unexpired record
+	// with the same key, in which case that record is returned instead. A
+	// record of the same request whose lease ran out is taken over.
+	Reserve(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+	// Complete stores the response, provided record still holds the lease.
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	// Release removes a reservation whose request did not complete, provided
+	// record still holds the lease.
+	Release(ctx context.Context, record *IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type IdempotencyUsecase interface {
+	// Begin claims key for the request with fingerprint. It returns a new
+	// record to Complete or Release once the request is handled, or the
+	// completed record of an earlier request with the same key to replay.
+	Begin(ctx context.Context, key, fingerprint string) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, record *IdempotencyRecord, statusCode int, headers map[string]string, response []byte) error
+	Release(ctx context.Context, record *IdempotencyRecord) error
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	StockMovementReceipt = "receipt"
+	StockMovementWaste   = "waste"
+)
+
+const (
+	StockCountStatusOpen    = "open"
+	StockCountStatusApplied = "applied"
+)
+
+// Ingredient is a stocked raw material. BaselineQuantity is the stock on hand
+// as of BaselineAt, which is reset whenever a stock count is applied.
+type Ingredient struct {
+	ID               uuid.UUID       `json:"id" db:"id"`
+	Name             string          `json:"name" db:"name"`
+	Unit             string          `json:"unit" db:"unit"`
+	UnitCost         decimal.Decimal `json:"unit_cost" db:"unit_cost"`
+	BaselineQuantity decimal.Decimal `json:"baseline_quantity" db:"baseline_quantity"`
+	BaselineAt       time.Time       `json:"baseline_at" db:"baseline_at"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// RecipeItem is the quantity of an ingredient consumed by one unit of a menu item.
+type RecipeItem struct {
+	MenuItemID   uuid.UUID       `json:"menu_item_id" db:"menu_item_id"`
+	IngredientID uuid.UUID       `json:"ingredient_id" db:"ingredient_id"`
+	Quantity     decimal.Decimal `json:"quantity" db:"quantity"`
+}
+
+type StockMovement struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	IngredientID uuid.UUID       `json:"ingredient_id" db:"ingredient_id"`
+	Type         string          `json:"type" db:"type"`
+	Quantity     decimal.Decimal `json:"quantity" db:"quantity"`
+	UnitCost     decimal.Decimal `json:"unit_cost" db:"unit_cost"`
+	Note         string          `json:"note" db:"note"`
+	OccurredAt   time.Time       `json:"occurred_at" db:"occurred_at"`
+}
+
+type StockCount struct {
+	ID        uuid.UUID        `json:"id" db:"id"`
+	Status    string           `json:"status" db:"status"`
+	Note      string           `json:"note" db:"note"`
+	CountedAt time.Time        `json:"counted_at" db:"counted_at"`
+	AppliedAt *time.Time       `json:"applied_at,omitempty" db:"applied_at"`
+	Lines     []StockCountLine `json:"lines,omitempty"`
+}
+
+type StockCountLine struct {
+	StockCountID    uuid.UUID       `json:"stock_count_id" db:"stock_count_id"`
+	IngredientID    uuid.UUID       `json:"ingredient_id" db:"ingredient_id"`
+	CountedQuantity decimal.Decimal `json:"counted_quantity" db:"counted_quantity"`
+}
+
+// StockLevel breaks down the expected stock of an ingredient between its
+// baseline and a point in time.
+type StockLevel struct {
+	IngredientID uuid.UUID       `json:"ingredient_id" db:"ingredient_id"`
+	Name         string          `json:"name" db:"name"`
+	Unit         string          `json:"unit" db:"unit"`
+	UnitCost     decimal.Decimal `json:"unit_cost" db:"unit_cost"`
+	Opening      decimal.Decimal `json:"opening" db:"opening"`
+	Received     decimal.Decimal `json:"received" db:"received"`
+	Sold         decimal.Decimal `json:"sold" db:"sold"`
+	Waste        decimal.Decimal `json:"waste" db:"waste"`
+}
+
+// Expected returns opening + received - sold - waste.
+func (l StockLevel) Expected() decimal.Decimal {
+	return l.Opening.Add(l.Received).Sub(l.Sold).Sub(l.Waste)
+}
+
+type StockVarianceLine struct {
+	StockLevel
+	Expected     decimal.Decimal `json:"expected"`
+	Counted      decimal.Decimal `json:"counted"`
+	Variance     decimal.Decimal `json:"variance"`
+	VarianceCost decimal.Decimal `json:"variance_cost"`
+}
+
+type StockVarianceReport struct {
+	StockCountID      uuid.UUID           `json:"stock_count_id"`
+	Status            string              `json:"status"`
+	CountedAt         time.Time           `json:"counted_at"`
+	Lines             []StockVarianceLine `json:"lines"`
+	TotalVarianceCost decimal.Decimal     `json:"total_variance_cost"`
+}
+
+type InventoryRepository interface {
+	CreateIngredient(ctx context.Context, ingredient *Ingredient) error
+	GetIngredientByID(ctx context.Context, id uuid.UUID) (*Ingredient, error)
+	ListIngredients(ctx context.Context) ([]Ingredient, error)
+	GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]RecipeItem, error)
+	GetMenuItemCost(ctx context.Context, menuItemID uuid.UUID) (decimal.Decimal, error)
+	ReplaceRecipe(ctx context.Context, menuItemID uuid.UUID, items []RecipeItem) error
+	CreateMovement(ctx context.Context, movement *StockMovement) error
+	CreateStockCount(ctx context.Context, count *StockCount) error
+	GetStockCount(ctx context.Context, id uuid.UUID) (*StockCount, error)
+	UpsertStockCountLines(ctx context.Context, countID uuid.UUID, lines []StockCountLine) error
+	GetStockLevels(ctx context.Context, asOf time.Time) ([]StockLevel, error)
+	ApplyStockCount(ctx context.Context, countID uuid.UUID, appliedAt time.Time) error
+}
+
+type InventoryUsecase interface {
+	CreateIngredient(ctx context.Context, ingredient *Ingredient) error
+	ListIngredients(ctx context.Context) ([]Ingredient, error)
+	GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]RecipeItem, error)
+	SetRecipe(ctx context.Context, menuItemID uuid.UUID, items []RecipeItem) error
+	RecordMovement(ctx context.Context, movement *StockMovement) error
+	StartStockCount(ctx context.Context, count *StockCount) error
+	GetStockCount(ctx context.Context, id uuid.UUID) (*StockCount, error)
+	RecordCountedQuantities(ctx context.Context, countID uuid.UUID, lines []StockCountLine) error
+	VarianceReport(ctx context.Context, countID uuid.UUID) (*StockVarianceReport, error)
+	ApplyStockCount(ctx context.Context, countID uuid.UUID) error
+}
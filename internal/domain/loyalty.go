@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInsufficientPoints is returned when a customer redeems more loyalty points
+// than their balance.
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+const (
+	LoyaltyEntryEarn     = "earn"
+	LoyaltyEntryRedeem   = "redeem"
+	LoyaltyEntryReversal = "reversal"
+	LoyaltyEntryVoid     = "void"
+)
+
+// LoyaltyEntry is an immutable line of the loyalty ledger. Points are positive
+// when credited to the customer and negative when debited.
+type LoyaltyEntry struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	CustomerID uuid.UUID  `json:"customer_id" db:"customer_id"`
+	OrderID    *uuid.UUID `json:"order_id,omitempty" db:"order_id"`
+	// OrderItemID is the voided line a void entry takes points back for.
+	OrderItemID *uuid.UUID `json:"order_item_id,omitempty" db:"order_item_id"`
+	Type        string     `json:"type" db:"type"`
+	Points      int64      `json:"points" db:"points"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type LoyaltyAccount struct {
+	CustomerID uuid.UUID      `json:"customer_id"`
+	Balance    int64          `json:"balance"`
+	Entries    []LoyaltyEntry `json:"entries"`
+}
+
+type LoyaltyRepository interface {
+	Balance(ctx context.Context, customerID uuid.UUID) (int64, error)
+	ListEntries(ctx context.Context, customerID uuid.UUID) ([]LoyaltyEntry, error)
+	ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]LoyaltyEntry, error)
+	AddEntry(ctx context.Context, entry *LoyaltyEntry) error
+	Redeem(ctx context.Context, entry *LoyaltyEntry) error
+}
+
+type LoyaltyUsecase interface {
+	GetAccount(ctx context.Context, customerID uuid.UUID) (*LoyaltyAccount, error)
+	RedemptionValue(points int64) decimal.Decimal
+	RedeemForOrder(ctx context.Context, order *Order) error
+	EarnForOrder(ctx context.Context, order *Order) error
+	// ReverseLine returns the entry taking back the points a voided line
+	// earned, or nil if there are none. order must already be repriced without
+	// the line. It does not touch the ledger.
+	ReverseLine(ctx context.Context, order *Order, itemID uuid.UUID) (*LoyaltyEntry, error)
+	ReverseOrder(ctx context.Context, orderID uuid.UUID) error
+}
@@ -12,6 +12,14 @@ import (
 // ErrNotFound is returned when a requested resource does not exist.
 var ErrNotFound = errors.New("not found")
 
+// ErrAlreadyExists is returned when a resource conflicts with a unique field
+// of an existing one.
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrConflict is returned when a resource was changed by someone else between
+// being read and being written.
+var ErrConflict = errors.New("conflict")
+
 type MenuItem struct {
 	ID          uuid.UUID       `json:"id" db:"id" binding:"omitempty"`
 	Name        string          `json:"name" db:"name" binding:"required"`
@@ -19,16 +27,25 @@ type MenuItem struct {
 	Price       decimal.Decimal `json:"price" db:"price" binding:"required"`
 	Category    string          `json:"category" db:"category" binding:"required"`
 	IsAvailable bool            `json:"is_available" db:"is_available"`
-	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	// Version is bumped on every update and served as the item's ETag.
+	Version   int64     `json:"version" db:"version"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// Audit, if set, is written to the audit log in the same transaction as
+	// the next write of the item.
+	Audit *AuditEntry `json:"-" db:"-"`
 }
 
 type MenuItemRepository interface {
 	Create(ctx context.Context, item *MenuItem) error
 	GetByID(ctx context.Context, id uuid.UUID) (*MenuItem, error)
 	Fetch(ctx context.Context) ([]MenuItem, error)
+	// Update replaces the item if it is still at item.Version and bumps the
+	// version. It returns ErrConflict if the item has changed since.
 	Update(ctx context.Context, item *MenuItem) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete removes the item if it is still at item.Version, returning
+	// ErrConflict if it has changed since.
+	Delete(ctx context.Context, item *MenuItem) error
 }
 
 type MenuItemUsecase interface {
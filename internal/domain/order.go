@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,30 +15,225 @@ const (
 	OrderStatusPaid      = "paid"
 	OrderStatusCancelled = "cancelled"
 	OrderStatusCompleted = "completed"
+	// OrderStatusSplit closes an order whose bill was split into new orders.
+	OrderStatusSplit = "split"
+	// OrderStatusMerged closes an order whose lines were moved to another.
+	OrderStatusMerged = "merged"
 )
 
+// Where an order goes. Tax and service charges can differ between them.
+const (
+	OrderTypeDineIn   = "dine_in"
+	OrderTypeTakeaway = "takeaway"
+	OrderTypeDelivery = "delivery"
+)
+
+func IsOrderType(orderType string) bool {
+	switch orderType {
+	case OrderTypeDineIn, OrderTypeTakeaway, OrderTypeDelivery:
+		return true
+	}
+	return false
+}
+
 type Order struct {
-	ID          uuid.UUID       `json:"id" db:"id"`
-	OrderNumber string          `json:"order_number" db:"order_number"`
-	Status      string          `json:"status" db:"status"`
-	Subtotal    decimal.Decimal `json:"subtotal" db:"subtotal"`
-	Tax         decimal.Decimal `json:"tax" db:"tax"`
-	Total       decimal.Decimal `json:"total" db:"total"`
-	Items       []OrderItem     `json:"items,omitempty"`
-	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	ID uuid.UUID `json:"id" db:"id"`
+	// OrderNumber is the short number called out at the counter. It restarts
+	// every business day, so Reference is the one to use on paperwork.
+	OrderNumber  string    `json:"order_number" db:"order_number"`
+	Reference    string    `json:"reference" db:"reference"`
+	StoreCode    string    `json:"store_code" db:"store_code"`
+	BusinessDate time.Time `json:"business_date" db:"business_date"`
+	Status       string    `json:"status" db:"status"`
+	OrderType    string    `json:"order_type" db:"order_type"`
+	// TableNumber is the table a dine-in order is served to, or the pager
+	// handed to the customer.
+	TableNumber string `json:"table_number,omitempty" db:"table_number"`
+	CallOutName string `json:"call_out_name,omitempty" db:"call_out_name"`
+	// Note holds special instructions for the whole order.
+	Note string `json:"note,omitempty" db:"note"`
+	// PickupAt is set on pre-orders. They stay out of the preparation queue
+	// until ReleasedAt, a lead time before pickup.
+	PickupAt   *time.Time `json:"pickup_at,omitempty" db:"pickup_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty" db:"released_at"`
+	// TabOpenedAt is set when the order is parked as a tab. The tab is open
+	// for as long as the order stays pending.
+	TabName     string     `json:"tab_name,omitempty" db:"tab_name"`
+	TabOpenedAt *time.Time `json:"tab_opened_at,omitempty" db:"tab_opened_at"`
+	// ParentOrderID is the order whose bill was split to make this one.
+	ParentOrderID *uuid.UUID `json:"parent_order_id,omitempty" db:"parent_order_id"`
+	// MergedIntoID is the order that took over this one's lines.
+	MergedIntoID *uuid.UUID      `json:"merged_into_id,omitempty" db:"merged_into_id"`
+	CustomerID   *uuid.UUID      `json:"customer_id,omitempty" db:"customer_id"`
+	Subtotal     decimal.Decimal `json:"subtotal" db:"subtotal"`
+	Discount     decimal.Decimal `json:"discount" db:"discount"`
+	// ManualDiscount is the part of Discount typed in by staff rather than
+	// redeemed from loyalty points.
+	ManualDiscount decimal.Decimal `json:"manual_discount" db:"manual_discount"`
+	Tax            decimal.Decimal `json:"tax" db:"tax"`
+	ServiceCharge  decimal.Decimal `json:"service_charge" db:"service_charge"`
+	Total          decimal.Decimal `json:"total" db:"total"`
+	RedeemedPoints int64           `json:"redeemed_points" db:"redeemed_points"`
+	// CashierID and TerminalID record who rang the order up and where.
+	CashierID  *uuid.UUID  `json:"cashier_id,omitempty" db:"cashier_id"`
+	TerminalID *uuid.UUID  `json:"terminal_id,omitempty" db:"terminal_id"`
+	Items      []OrderItem `json:"items,omitempty"`
+	// StatusHistory is only loaded for a single order.
+	StatusHistory []OrderStatusChange `json:"status_history,omitempty"`
+	// Version is bumped on every update and served as the order's ETag.
+	Version   int64     `json:"version" db:"version"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// Approval, if set, is recorded in the same transaction as the next write
+	// of the order.
+	Approval *Approval `json:"-" db:"-"`
+	// Audit, if set, is written to the audit log in the same transaction as
+	// the next write of the order.
+	Audit *AuditEntry `json:"-" db:"-"`
+	// SlotBooking, if set, is checked against the pickup slot in the same
+	// transaction as the next write of the order.
+	SlotBooking *SlotBooking `json:"-" db:"-"`
+}
+
+// ErrPickupSlotFull is returned when a pre-order no longer fits in its pickup
+// slot.
+var ErrPickupSlotFull = errors.New("pickup slot is full")
+
+// SlotBooking is the room a pre-order takes in the pickup slot [From, To).
+// Units counts its lines in Categories, or all but gift cards if empty.
+type SlotBooking struct {
+	From       time.Time
+	To         time.Time
+	Units      decimal.Decimal
+	Capacity   int
+	Categories []string
+}
+
+// OrderStatusChange is one step in an order's status timeline. FromStatus is
+// nil for the entry written when the order is created.
+type OrderStatusChange struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	OrderID    uuid.UUID  `json:"order_id" db:"order_id"`
+	FromStatus *string    `json:"from_status" db:"from_status"`
+	ToStatus   string     `json:"to_status" db:"to_status"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	APIKeyID   *uuid.UUID `json:"api_key_id,omitempty" db:"api_key_id"`
+	Reason     string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	// Approval and Audit, if set, are recorded in the same transaction as the
+	// change.
+	Approval *Approval   `json:"-" db:"-"`
+	Audit    *AuditEntry `json:"-" db:"-"`
+}
+
+// FormatOrderNumber gives the nth order of a business day, e.g. "A-042".
+func FormatOrderNumber(storeCode string, n int) string {
+	return fmt.Sprintf("%s-%03d", storeCode, n)
+}
+
+// FormatOrderReference makes an order number unique across days, e.g.
+// "A-20261018-042".
+func FormatOrderReference(storeCode string, businessDate time.Time, n int) string {
+	return fmt.Sprintf("%s-%s-%03d", storeCode, businessDate.Format("20060102"), n)
+}
+
+// OrderSplit is one of the orders a bill is split into by items.
+type OrderSplit struct {
+	Items []SplitItem
+}
+
+// SplitItem moves Quantity of the line ItemID to a split order. Quantity can
+// be fractional for an item shared between several orders.
+type SplitItem struct {
+	ItemID   uuid.UUID
+	Quantity decimal.Decimal
+}
+
+// OrderFilter narrows an order listing. Zero fields are ignored.
+type OrderFilter struct {
+	CustomerID *uuid.UUID
+	OrderType  string
+	// OpenTabs keeps only pending orders parked as tabs.
+	OpenTabs bool
+	// Queue leaves out pre-orders that have not been released yet, and Held
+	// keeps only those still waiting to be released.
+	Queue bool
+	Held  bool
 }
 
 type OrderRepository interface {
+	// Create takes the next number for the order's store and business day and
+	// fills in OrderNumber and Reference.
 	Create(ctx context.Context, order *Order) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)
-	List(ctx context.Context) ([]Order, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time) error
+	List(ctx context.Context, filter OrderFilter) ([]Order, error)
+	// UpdateStatus moves the order from change.FromStatus to change.ToStatus,
+	// bumps its version and appends change to its history in one transaction.
+	// It returns ErrConflict if the order is no longer at version.
+	UpdateStatus(ctx context.Context, change *OrderStatusChange, version int64) error
+	// UpdateItems replaces the lines and totals of a pending order and bumps
+	// its version. It returns ErrConflict if the order is no longer pending at
+	// version.
+	UpdateItems(ctx context.Context, order *Order, version int64) error
+	// VoidItem marks item voided, stores the order's new totals and records
+	// what void gives back, crediting gift cards, in one transaction. It
+	// returns ErrConflict if the order is no longer at version in the same
+	// status.
+	VoidItem(ctx context.Context, order *Order, item *OrderItem, void *LineVoid, version int64) error
+	// OpenTab stores the order's tab name and opening time and bumps its
+	// version. It returns ErrConflict if the order is no longer pending at
+	// version or is already a tab.
+	OpenTab(ctx context.Context, order *Order, version int64) error
+	// Split applies change to close the order as split and creates children,
+	// numbering them like Create, in one transaction. It returns ErrConflict
+	// if the order is no longer pending at version.
+	Split(ctx context.Context, change *OrderStatusChange, version int64, children []Order) error
+	// Merge closes each source with its change in changes, moves its lines to
+	// target and stores target's new totals, in one transaction. It returns
+	// ErrConflict if target is no longer pending at version or a source is no
+	// longer pending at the version it was read at.
+	Merge(ctx context.Context, target *Order, version int64, sources []Order, changes []OrderStatusChange) error
+	// UpdateTable stores the order's table number and bumps its version. It
+	// returns ErrConflict if the order is no longer pending at version.
+	UpdateTable(ctx context.Context, order *Order, version int64) error
+	// ReleaseScheduled releases the held pre-orders picked up before the given
+	// time, bumping their versions and writing each release to the order's
+	// status history and the audit log in one transaction. It returns how
+	// many it released.
+	ReleaseScheduled(ctx context.Context, before, now time.Time) (int64, error)
+	StatusHistory(ctx context.Context, orderID uuid.UUID) ([]OrderStatusChange, error)
 }
 
 type OrderUsecase interface {
 	Create(ctx context.Context, order *Order) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)
-	List(ctx context.Context) ([]Order, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	List(ctx context.Context, filter OrderFilter) ([]Order, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) error
+	// AddItem, UpdateItemQuantity and RemoveItem edit the lines of a pending
+	// order and return it repriced.
+	AddItem(ctx context.Context, orderID uuid.UUID, item *OrderItem) (*Order, error)
+	// AddRound adds several lines to a pending order at once.
+	AddRound(ctx context.Context, orderID uuid.UUID, items []OrderItem) (*Order, error)
+	UpdateItemQuantity(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (*Order, error)
+	// UpdateItemNote replaces the note on a line of a pending order.
+	UpdateItemNote(ctx context.Context, orderID, itemID uuid.UUID, note string) (*Order, error)
+	RemoveItem(ctx context.Context, orderID, itemID uuid.UUID) (*Order, error)
+	// VoidItem takes a line off a paid order and refunds the difference.
+	VoidItem(ctx context.Context, orderID, itemID uuid.UUID, reason string) (*Order, error)
+	// OpenTab parks a pending order as an open tab under name, or under its
+	// table number when name is empty.
+	OpenTab(ctx context.Context, orderID uuid.UUID, name string) (*Order, error)
+	// Split and SplitEqually replace a pending order with new pending orders
+	// whose totals add up to its total, and return them.
+	Split(ctx context.Context, orderID uuid.UUID, splits []OrderSplit) ([]Order, error)
+	SplitEqually(ctx context.Context, orderID uuid.UUID, shares int) ([]Order, error)
+	// Merge moves the lines of the pending orders sourceIDs onto the pending
+	// order targetID and reprices it.
+	Merge(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) (*Order, error)
+	// Transfer moves a pending order to another table.
+	Transfer(ctx context.Context, orderID uuid.UUID, tableNumber string) (*Order, error)
+	// ReleaseDue releases the pre-orders whose lead time has come into the
+	// preparation queue. It is run by the scheduler, not on behalf of a
+	// caller.
+	ReleaseDue(ctx context.Context) (int64, error)
 }
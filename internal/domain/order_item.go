@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -12,4 +14,29 @@ type OrderItem struct {
 	Quantity   int             `json:"quantity" db:"quantity"`
 	UnitPrice  decimal.Decimal `json:"unit_price" db:"unit_price"`
 	LineTotal  decimal.Decimal `json:"line_total" db:"line_total"`
+	UnitCost   decimal.Decimal `json:"unit_cost" db:"unit_cost"`
+	// GiftCardCode names the card a gift card line reloads. Lines without
+	// one issue new cards.
+	GiftCardCode string `json:"gift_card_code,omitempty" db:"gift_card_code"`
+	// StampProgramID marks a free line paid for by a full stamp card.
+	StampProgramID *uuid.UUID `json:"stamp_program_id,omitempty" db:"stamp_program_id"`
+	// A voided line stays on the order for the record but is left out of its
+	// totals.
+	VoidedAt   *time.Time `json:"voided_at,omitempty" db:"voided_at"`
+	VoidReason string     `json:"void_reason,omitempty" db:"void_reason"`
+	VoidedBy   *uuid.UUID `json:"voided_by,omitempty" db:"voided_by"`
+	// Share is the part of Quantity this order pays for when an item was
+	// shared between the orders of a split bill. Nil means all of it.
+	Share *decimal.Decimal `json:"share,omitempty" db:"share"`
+	// Note holds special instructions for the line, e.g. "extra hot".
+	Note string `json:"note,omitempty" db:"note"`
+}
+
+// Units is how many of the menu item the line sells, counting shares.
+func (i OrderItem) Units() decimal.Decimal {
+	units := decimal.NewFromInt(int64(i.Quantity))
+	if i.Share != nil {
+		units = units.Mul(*i.Share)
+	}
+	return units
 }
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Both wrap ErrForbidden so callers that only know about permissions still
+// answer 403.
+var (
+	ErrOverrideRequired = fmt.Errorf("%w: manager approval required", ErrForbidden)
+	ErrInvalidOverride  = fmt.Errorf("%w: invalid manager approval", ErrForbidden)
+)
+
+// Actions that need a manager's approval when performed by anyone else.
+const (
+	OverrideCancelPaidOrder = "order.cancel_paid"
+	OverrideLargeDiscount   = "order.large_discount"
+	OverrideVoidLine        = "order.void_line"
+)
+
+// OverridePermissions is the permission an approver needs for each action.
+var OverridePermissions = map[string]Permission{
+	OverrideCancelPaidOrder: PermOrdersRefund,
+	OverrideLargeDiscount:   PermOrdersDiscount,
+	OverrideVoidLine:        PermOrdersRefund,
+}
+
+// How an approval was given: by the actor's own role, a manager typing their
+// PIN on the spot, or a single-use token a manager issued beforehand.
+const (
+	ApprovalMethodRole  = "role"
+	ApprovalMethodPIN   = "pin"
+	ApprovalMethodToken = "token"
+)
+
+// OverrideCredentials is what a request presents to have a sensitive action
+// approved: a manager's username and PIN, or an approval token.
+type OverrideCredentials struct {
+	ManagerUsername string
+	PIN             string
+	ApprovalToken   string
+}
+
+type overrideKey struct{}
+
+func WithOverride(ctx context.Context, credentials *OverrideCredentials) context.Context {
+	return context.WithValue(ctx, overrideKey{}, credentials)
+}
+
+func OverrideFromContext(ctx context.Context) (*OverrideCredentials, bool) {
+	credentials, ok := ctx.Value(overrideKey{}).(*OverrideCredentials)
+	return credentials, ok && credentials != nil
+}
+
+// Approval records who approved a sensitive action for whom.
+type Approval struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Action     string     `json:"action" db:"action"`
+	EntityID   uuid.UUID  `json:"entity_id" db:"entity_id"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	ApproverID uuid.UUID  `json:"approver_id" db:"approver_id"`
+	Method     string     `json:"method" db:"method"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	// TokenID is the approval token the approval was given with. The token is
+	// only used up when the approval is recorded.
+	TokenID *uuid.UUID `json:"-" db:"-"`
+}
+
+// ApprovalToken lets a manager approve an action from their own device. It is
+// single use, short lived and may be bound to one entity.
+type ApprovalToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Token     string     `json:"approval_token,omitempty" db:"-"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ManagerID uuid.UUID  `json:"manager_id" db:"manager_id"`
+	Action    string     `json:"action" db:"action"`
+	EntityID  *uuid.UUID `json:"entity_id,omitempty" db:"entity_id"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ApprovalFilter narrows an approval listing. Empty fields are ignored.
+type ApprovalFilter struct {
+	Action   string
+	EntityID *uuid.UUID
+}
+
+type OverrideRepository interface {
+	CreateToken(ctx context.Context, token *ApprovalToken) error
+	// FindToken returns a matching unused, unexpired token without using it,
+	// or nil if there is none.
+	FindToken(ctx context.Context, tokenHash, action string, entityID uuid.UUID, at time.Time) (*ApprovalToken, error)
+	// Record stores approval, using up the token it was given with in the
+	// same transaction. It returns ErrInvalidOverride if the token has been
+	// used or has expired since it was found.
+	Record(ctx context.Context, approval *Approval) error
+	List(ctx context.Context, filter ApprovalFilter) ([]Approval, error)
+}
+
+type OverrideUsecase interface {
+	IssueToken(ctx context.Context, action string, entityID *uuid.UUID) (*ApprovalToken, error)
+	// Approve checks that the caller may perform action on entityID, either
+	// because their role grants the action's permission or because the request
+	// carries a valid approval from someone whose role does. Nothing is used up
+	// or stored: the approval is meant to be recorded in the same transaction
+	// as the action, which is when an approval token is consumed.
+	Approve(ctx context.Context, action string, entityID uuid.UUID) (*Approval, error)
+	List(ctx context.Context, filter ApprovalFilter) ([]Approval, error)
+}
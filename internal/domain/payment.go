@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrOrderNotPayable   = errors.New("only pending orders can take payments")
+	ErrPaymentExceedsDue = errors.New("payment exceeds the amount due")
+)
+
+const (
+	TenderCash     = "cash"
+	TenderCard     = "card"
+	TenderGiftCard = "gift_card"
+)
+
+type Payment struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	OrderID      uuid.UUID       `json:"order_id" db:"order_id"`
+	Tender       string          `json:"tender" db:"tender"`
+	Amount       decimal.Decimal `json:"amount" db:"amount"`
+	GiftCardID   *uuid.UUID      `json:"gift_card_id,omitempty" db:"gift_card_id"`
+	GiftCardCode string          `json:"gift_card_code,omitempty" db:"-"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Refund is money given back against one payment, e.g. for a voided line.
+type Refund struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	OrderID     uuid.UUID       `json:"order_id" db:"order_id"`
+	PaymentID   uuid.UUID       `json:"payment_id" db:"payment_id"`
+	OrderItemID *uuid.UUID      `json:"order_item_id,omitempty" db:"order_item_id"`
+	Tender      string          `json:"tender" db:"tender"`
+	Amount      decimal.Decimal `json:"amount" db:"amount"`
+	GiftCardID  *uuid.UUID      `json:"gift_card_id,omitempty" db:"gift_card_id"`
+	Reason      string          `json:"reason,omitempty" db:"reason"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// LineVoid is what voiding a line gives back besides the line itself: refunds
+// against the order's payments and the points and stamps the line earned.
+type LineVoid struct {
+	Refunds []Refund
+	Points  *LoyaltyEntry
+	Stamps  []StampEntry
+}
+
+// OrderBalance summarises what has been paid against an order.
+type OrderBalance struct {
+	OrderID  uuid.UUID       `json:"order_id"`
+	Status   string          `json:"status"`
+	Total    decimal.Decimal `json:"total"`
+	Paid     decimal.Decimal `json:"paid"`
+	Refunded decimal.Decimal `json:"refunded"`
+	Due      decimal.Decimal `json:"due"`
+	Payments []Payment       `json:"payments"`
+	Refunds  []Refund        `json:"refunds"`
+}
+
+type PaymentRepository interface {
+	// Create records a payment, checking it against the amount still due and
+	// debiting the gift card it uses in the same transaction. It bumps the
+	// order's version, so an edit read before the payment no longer applies.
+	Create(ctx context.Context, payment *Payment) error
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]Payment, error)
+	ListRefunds(ctx context.Context, orderID uuid.UUID) ([]Refund, error)
+}
+
+type PaymentUsecase interface {
+	Pay(ctx context.Context, orderID uuid.UUID, payment *Payment) (*OrderBalance, error)
+	GetBalance(ctx context.Context, orderID uuid.UUID) (*OrderBalance, error)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPreconditionFailed is returned when a write was made conditional on a
+// version the resource no longer has.
+var ErrPreconditionFailed = errors.New("resource has been modified")
+
+type ifMatchKey struct{}
+
+// WithIfMatch makes writes under ctx conditional on the resource still having
+// one of versions, as sent by a client in an If-Match header.
+func WithIfMatch(ctx context.Context, versions []int64) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, versions)
+}
+
+func IfMatchFromContext(ctx context.Context) ([]int64, bool) {
+	versions, ok := ctx.Value(ifMatchKey{}).([]int64)
+	return versions, ok
+}
+
+// CheckIfMatch returns ErrPreconditionFailed if ctx carries If-Match versions
+// and version is not one of them.
+func CheckIfMatch(ctx context.Context, version int64) error {
+	versions, ok := IfMatchFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, v := range versions {
+		if v == version {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MenuItemCost is the theoretical cost and gross margin of one unit of a menu
+// item at current ingredient costs.
+type MenuItemCost struct {
+	MenuItemID    uuid.UUID       `json:"menu_item_id" db:"menu_item_id"`
+	Name          string          `json:"name" db:"name"`
+	Category      string          `json:"category" db:"category"`
+	Price         decimal.Decimal `json:"price" db:"price"`
+	Cost          decimal.Decimal `json:"cost" db:"cost"`
+	Margin        decimal.Decimal `json:"margin" db:"-"`
+	MarginPercent decimal.Decimal `json:"margin_percent" db:"-"`
+}
+
+// ItemSales aggregates what was sold of a menu item, using the prices and
+// costs snapshotted on the order items at sale time.
+type ItemSales struct {
+	MenuItemID    uuid.UUID       `json:"menu_item_id" db:"menu_item_id"`
+	Name          string          `json:"name" db:"name"`
+	Category      string          `json:"category" db:"category"`
+	Quantity      int             `json:"quantity" db:"quantity"`
+	Revenue       decimal.Decimal `json:"revenue" db:"revenue"`
+	Cost          decimal.Decimal `json:"cost" db:"cost"`
+	Margin        decimal.Decimal `json:"margin" db:"-"`
+	MarginPercent decimal.Decimal `json:"margin_percent" db:"-"`
+}
+
+type CategorySales struct {
+	Category      string          `json:"category"`
+	Quantity      int             `json:"quantity"`
+	Revenue       decimal.Decimal `json:"revenue"`
+	Cost          decimal.Decimal `json:"cost"`
+	Margin        decimal.Decimal `json:"margin"`
+	MarginPercent decimal.Decimal `json:"margin_percent"`
+}
+
+type MarginReport struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Items         []ItemSales     `json:"items"`
+	Categories    []CategorySales `json:"categories"`
+	Revenue       decimal.Decimal `json:"revenue"`
+	Cost          decimal.Decimal `json:"cost"`
+	Margin        decimal.Decimal `json:"margin"`
+	MarginPercent decimal.Decimal `json:"margin_percent"`
+}
+
+type ReportRepository interface {
+	MenuItemCosts(ctx context.Context) ([]MenuItemCost, error)
+	ItemSales(ctx context.Context, from, to time.Time) ([]ItemSales, error)
+}
+
+type ReportUsecase interface {
+	MenuItemCosts(ctx context.Context) ([]MenuItemCost, error)
+	MarginReport(ctx context.Context, from, to time.Time) (*MarginReport, error)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrForbidden = errors.New("permission denied")
+
+const (
+	RoleCashier = "cashier"
+	RoleBarista = "barista"
+	RoleManager = "manager"
+)
+
+type Permission string
+
+const (
+	PermMenuRead        Permission = "menu:read"
+	PermOrdersRead      Permission = "orders:read"
+	PermCustomersRead   Permission = "customers:read"
+	PermInventoryRead   Permission = "inventory:read"
+	PermOrdersCreate    Permission = "orders:create"
+	PermOrdersCancel    Permission = "orders:cancel"
+	PermOrdersPrepare   Permission = "orders:prepare"
+	PermOrdersRefund    Permission = "orders:refund"
+	PermOrdersDiscount  Permission = "orders:discount"
+	PermPaymentsTake    Permission = "payments:take"
+	PermMenuWrite       Permission = "menu:write"
+	PermCustomersWrite  Permission = "customers:write"
+	PermInventoryWrite  Permission = "inventory:write"
+	PermLoyaltyManage   Permission = "loyalty:manage"
+	PermReportsRead     Permission = "reports:read"
+	PermStaffManage     Permission = "staff:manage"
+	PermTerminalsManage Permission = "terminals:manage"
+	PermAPIKeysManage   Permission = "apikeys:manage"
+	PermAuditRead       Permission = "audit:read"
+)
+
+// staffReads is what every staff member can look at.
+var staffReads = []Permission{PermMenuRead, PermOrdersRead, PermCustomersRead, PermInventoryRead}
+
+// RolePermissions lists what each role may do. Every role can read orders, the
+// menu, customers and inventory. Managers can do everything.
+var RolePermissions = map[string][]Permission{
+	RoleCashier: append([]Permission{PermOrdersCreate, PermOrdersCancel, PermPaymentsTake, PermCustomersWrite}, staffReads...),
+	RoleBarista: append([]Permission{PermOrdersPrepare}, staffReads...),
+	RoleManager: append([]Permission{
+		PermOrdersCreate, PermOrdersCancel, PermOrdersPrepare, PermOrdersRefund, PermOrdersDiscount, PermPaymentsTake,
+		PermMenuWrite, PermCustomersWrite, PermInventoryWrite, PermLoyaltyManage, PermReportsRead,
+		PermStaffManage, PermTerminalsManage, PermAPIKeysManage, PermAuditRead,
+	}, staffReads...),
+}
+
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// Can reports whether the identity's role, or an API key's scopes, grant
+// permission.
+func (i *Identity) Can(permission Permission) bool {
+	granted := RolePermissions[i.Role]
+	if i.APIKeyID != nil {
+		granted = nil
+		for _, scope := range i.Scopes {
+			granted = append(granted, APIKeyScopes[scope]...)
+		}
+	}
+	for _, p := range granted {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns ErrForbidden unless ctx carries an identity holding
+// permission. Calls without any identity are refused too.
+func Authorize(ctx context.Context, permission Permission) error {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok || !identity.Can(permission) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+type Staff struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Username     string    `json:"username" db:"username"`
+	Name         string    `json:"name" db:"name"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`
+	Active       bool      `json:"active" db:"active"`
+	// PINHash is the bcrypt hash of the staff member's terminal PIN, if set.
+	PINHash           *string    `json:"-" db:"pin_hash"`
+	PINFailedAttempts int        `json:"-" db:"pin_failed_attempts"`
+	PINLockedUntil    *time.Time `json:"pin_locked_until,omitempty" db:"pin_locked_until"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Identity is the authenticated caller behind a request. TerminalID is set
+// when a staff member signed in with a PIN on a registered POS terminal. For
+// an API key, APIKeyID and Scopes are set, Username is the key's name and there
+// is no staff member or role.
+type Identity struct {
+	StaffID    uuid.UUID  `json:"staff_id"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role,omitempty"`
+	TerminalID *uuid.UUID `json:"terminal_id,omitempty"`
+	APIKeyID   *uuid.UUID `json:"api_key_id,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+}
+
+// StaffRef returns the ID of the staff member behind the request, or nil for
+// an API key.
+func (i *Identity) StaffRef() *uuid.UUID {
+	if i.APIKeyID != nil {
+		return nil
+	}
+	return &i.StaffID
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the authenticated staff member.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the staff member stored by WithIdentity, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// TokenPair is returned on login and refresh. The access token authenticates
+// API calls; the refresh token only buys a new pair.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+}
+
+type StaffRepository interface {
+	Create(ctx context.Context, staff *Staff) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Staff, error)
+	GetByUsername(ctx context.Context, username string) (*Staff, error)
+	List(ctx context.Context) ([]Staff, error)
+	Count(ctx context.Context) (int, error)
+	// SetPIN stores a new PIN hash and clears any lockout.
+	SetPIN(ctx context.Context, id uuid.UUID, pinHash string, updatedAt time.Time) error
+	// ClaimPINAttempt counts a PIN attempt before the PIN is checked, and
+	// locks the PIN until lockUntil once maxAttempts is reached, reporting
+	// whether this attempt was the last one allowed. It returns ErrPINLocked
+	// without counting anything if the PIN is locked at now.
+	ClaimPINAttempt(ctx context.Context, id uuid.UUID, maxAttempts int, now, lockUntil time.Time) (bool, error)
+	ResetPINFailures(ctx context.Context, id uuid.UUID) error
+}
+
+type StaffUsecase interface {
+	Create(ctx context.Context, staff *Staff, password string) error
+	List(ctx context.Context) ([]Staff, error)
+	// SetPIN sets the terminal PIN of a staff member. Staff may set their own;
+	// setting someone else's needs PermStaffManage.
+	SetPIN(ctx context.Context, id uuid.UUID, pin string) error
+	// EnsureAdmin creates the first staff account when none exist yet.
+	EnsureAdmin(ctx context.Context, username, password string) error
+}
+
+// Authenticator resolves a bearer token to the staff member it belongs to. It
+// returns ErrInvalidToken for tokens it does not recognise.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+type AuthUsecase interface {
+	Authenticator
+	Login(ctx context.Context, username, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInsufficientStamps is returned when a stamp card no longer holds enough
+// stamps for the reward being redeemed.
+var ErrInsufficientStamps = errors.New("stamp card is not full")
+
+const (
+	StampEntryStamp    = "stamp"
+	StampEntryRedeem   = "redeem"
+	StampEntryReversal = "reversal"
+	StampEntryVoid     = "void"
+)
+
+// StampProgram is a "buy N, get one free" card. Every item sold in one of its
+// categories earns a stamp; a full card pays for one item in those categories.
+type StampProgram struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	Categories     []string  `json:"categories" db:"-"`
+	StampsRequired int       `json:"stamps_required" db:"stamps_required"`
+	Active         bool      `json:"active" db:"active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StampEntry is an immutable line of the stamp ledger.
+type StampEntry struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ProgramID  uuid.UUID  `json:"program_id" db:"program_id"`
+	CustomerID uuid.UUID  `json:"customer_id" db:"customer_id"`
+	OrderID    *uuid.UUID `json:"order_id,omitempty" db:"order_id"`
+	// OrderItemID is the voided line a void entry takes stamps back for.
+	OrderItemID *uuid.UUID `json:"order_item_id,omitempty" db:"order_item_id"`
+	Type        string     `json:"type" db:"type"`
+	Stamps      int        `json:"stamps" db:"stamps"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StampCard is a customer's progress on one program.
+type StampCard struct {
+	ProgramID      uuid.UUID `json:"program_id"`
+	ProgramName    string    `json:"program_name"`
+	Stamps         int       `json:"stamps"`
+	StampsRequired int       `json:"stamps_required"`
+	Full           bool      `json:"full"`
+}
+
+type StampRepository interface {
+	CreateProgram(ctx context.Context, program *StampProgram) error
+	GetProgram(ctx context.Context, id uuid.UUID) (*StampProgram, error)
+	ListPrograms(ctx context.Context, activeOnly bool) ([]StampProgram, error)
+	UpdateProgram(ctx context.Context, program *StampProgram) error
+	Balances(ctx context.Context, customerID uuid.UUID) (map[uuid.UUID]int, error)
+	ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]StampEntry, error)
+	AddEntry(ctx context.Context, entry *StampEntry) error
+	Redeem(ctx context.Context, entry *StampEntry) error
+}
+
+type StampUsecase interface {
+	CreateProgram(ctx context.Context, program *StampProgram) error
+	ListPrograms(ctx context.Context) ([]StampProgram, error)
+	UpdateProgram(ctx context.Context, program *StampProgram) error
+	GetCards(ctx context.Context, customerID uuid.UUID) ([]StampCard, error)
+	// ApplyRewards turns one qualifying unit into a free line for every full
+	// card of the order's customer. It does not touch the ledger.
+	ApplyRewards(ctx context.Context, order *Order) error
+	RedeemRewards(ctx context.Context, order *Order) error
+	StampOrder(ctx context.Context, order *Order) error
+	// ReverseLine returns the entries taking back the stamps a voided line
+	// earned, one per program. It does not touch the ledger.
+	ReverseLine(ctx context.Context, order *Order, itemID uuid.UUID) ([]StampEntry, error)
+	ReverseOrder(ctx context.Context, orderID uuid.UUID) error
+}
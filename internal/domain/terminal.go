@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidTerminal = errors.New("unknown or inactive terminal")
+	ErrPINLocked       = errors.New("too many failed PIN attempts, try again later")
+)
+
+// Terminal is a registered POS device. Its device token is only shown once, at
+// registration; the database keeps a SHA-256 hash of it.
+type Terminal struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	DeviceToken string     `json:"device_token,omitempty" db:"-"`
+	Active      bool       `json:"active" db:"active"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty" db:"last_seen_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TerminalSession is a staff member signed in on a terminal with their PIN.
+// Only one session is open per terminal; a new PIN login switches the user.
+type TerminalSession struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TerminalID uuid.UUID  `json:"terminal_id" db:"terminal_id"`
+	StaffID    uuid.UUID  `json:"staff_id" db:"staff_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+
+	// Filled in by GetSessionByTokenHash so a request can be authenticated
+	// with a single lookup.
+	Username       string `json:"-" db:"username"`
+	Role           string `json:"-" db:"role"`
+	StaffActive    bool   `json:"-" db:"staff_active"`
+	TerminalActive bool   `json:"-" db:"terminal_active"`
+}
+
+// TerminalLogin is returned by a PIN login. SessionToken is used as a bearer
+// token like a JWT access token.
+type TerminalLogin struct {
+	SessionToken string    `json:"session_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	TokenType    string    `json:"token_type"`
+	Staff        *Identity `json:"staff"`
+}
+
+type TerminalRepository interface {
+	Create(ctx context.Context, terminal *Terminal) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*Terminal, error)
+	List(ctx context.Context) ([]Terminal, error)
+	// Deactivate disables a terminal and ends its open sessions.
+	Deactivate(ctx context.Context, id uuid.UUID, at time.Time) error
+	// StartSession ends any open session on the terminal and opens a new one.
+	StartSession(ctx context.Context, session *TerminalSession) error
+	GetSessionByTokenHash(ctx context.Context, tokenHash string) (*TerminalSession, error)
+	TouchSession(ctx context.Context, id uuid.UUID, at time.Time) error
+	EndSession(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type TerminalUsecase interface {
+	Authenticator
+	Register(ctx context.Context, terminal *Terminal) error
+	List(ctx context.Context) ([]Terminal, error)
+	Deactivate(ctx context.Context, id uuid.UUID) error
+	PINLogin(ctx context.Context, deviceToken, username, pin string) (*TerminalLogin, error)
+	Logout(ctx context.Context, sessionToken string) error
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) domain.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// apiKeyRow scans the scopes array that domain.APIKey keeps as a plain slice.
+type apiKeyRow struct {
+	domain.APIKey
+	Scopes pq.StringArray `db:"scopes"`
+}
+
+func (row apiKeyRow) toAPIKey() domain.APIKey {
+	key := row.APIKey
+	key.Scopes = []string(row.Scopes)
+	return key
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes),
+		key.CreatedBy, key.CreatedAt)
+	return err
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var row apiKeyRow
+	query := `SELECT id, name, prefix, key_hash, scopes, created_by, last_used_at, revoked_at, created_at
+		FROM api_keys WHERE key_hash = $1`
+	if err := r.db.GetContext(ctx, &row, query, keyHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	key := row.toAPIKey()
+	return &key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	query := `SELECT id, name, prefix, key_hash, scopes, created_by, last_used_at, revoked_at, created_at
+		FROM api_keys ORDER BY created_at DESC`
+	var rows []apiKeyRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	keys := make([]domain.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = row.toAPIKey()
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, at, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, at, id)
+	return err
+}
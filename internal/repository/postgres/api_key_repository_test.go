@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepository_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewAPIKeyRepository(sqlxDB)
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, prefix, key_hash, scopes, created_by, last_used_at, revoked_at, created_at
+		FROM api_keys WHERE key_hash = $1`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "key_hash", "scopes", "created_by", "last_used_at", "revoked_at", "created_at"}).
+			AddRow(id, "Kiosk", "key_abcdefgh", "hash", "{menu:read,orders:write}", nil, nil, nil, time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM api_keys WHERE key_hash = $1`)).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	key, err := repo.GetByHash(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+	assert.Equal(t, []string{"menu:read", "orders:write"}, key.Scopes)
+
+	key, err = repo.GetByHash(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewAPIKeyRepository(sqlxDB)
+	id := uuid.New()
+	at := time.Now()
+
+	query := regexp.QuoteMeta(`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`)
+	mock.ExpectExec(query).WithArgs(at, id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(at, id).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.Revoke(context.Background(), id, at))
+	assert.ErrorIs(t, repo.Revoke(context.Background(), id, at), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) domain.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	return insertAuditEntry(ctx, r.db, entry)
+}
+
+// insertAuditEntry writes entry through db, which may be a transaction. A nil
+// entry writes nothing.
+func insertAuditEntry(ctx context.Context, db sqlx.ExecerContext, entry *domain.AuditEntry) error {
+	if entry == nil {
+		return nil
+	}
+	query := `INSERT INTO audit_log (id, actor_id, api_key_id, actor_name, action, entity_type, entity_id, before, after, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := db.ExecContext(ctx, query, entry.ID, entry.ActorID, entry.APIKeyID, entry.ActorName, entry.Action,
+		entry.EntityType, entry.EntityID, jsonParam(entry.Before), jsonParam(entry.After), entry.RequestID, entry.CreatedAt)
+	return err
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := `SELECT id, actor_id, api_key_id, actor_name, action, entity_type, entity_id, before, after, request_id, created_at
+		FROM audit_log`
+	var conditions []string
+	var args []interface{}
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", len(args)))
+	}
+	if filter.EntityID != nil {
+		args = append(args, *filter.EntityID)
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	entries := []domain.AuditEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// jsonParam passes JSON as text; lib/pq would send raw bytes as bytea.
+func jsonParam(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
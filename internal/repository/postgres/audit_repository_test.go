@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewAuditRepository(sqlxDB)
+	actorID := uuid.New()
+	entry := &domain.AuditEntry{
+		ID:         uuid.New(),
+		ActorID:    &actorID,
+		ActorName:  "admin",
+		Action:     domain.AuditMenuItemCreate,
+		EntityType: domain.AuditEntityMenuItem,
+		EntityID:   uuid.New(),
+		After:      json.RawMessage(`{"name":"Latte"}`),
+		RequestID:  "req-1",
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log (id, actor_id, api_key_id, actor_name, action, entity_type, entity_id, before, after, request_id, created_at)`)).
+		WithArgs(entry.ID, entry.ActorID, entry.APIKeyID, "admin", domain.AuditMenuItemCreate, domain.AuditEntityMenuItem, entry.EntityID,
+			nil, `{"name":"Latte"}`, "req-1", entry.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.Record(context.Background(), entry))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_List_Filters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewAuditRepository(sqlxDB)
+	entityID := uuid.New()
+	from := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, actor_id, api_key_id, actor_name, action, entity_type, entity_id, before, after, request_id, created_at
+		FROM audit_log WHERE entity_type = $1 AND entity_id = $2 AND created_at >= $3 ORDER BY created_at DESC LIMIT $4`)).
+		WithArgs(domain.AuditEntityOrder, entityID, from, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "api_key_id", "actor_name", "action", "entity_type", "entity_id", "before", "after", "request_id", "created_at"}).
+			AddRow(uuid.New(), nil, nil, "kiosk", domain.AuditOrderStatusChange, domain.AuditEntityOrder, entityID, []byte(`{"status":"pending"}`), []byte(`{"status":"paid"}`), "req-2", time.Now()))
+
+	entries, err := repo.List(context.Background(), domain.AuditFilter{EntityType: domain.AuditEntityOrder, EntityID: &entityID, From: &from, Limit: 50})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.JSONEq(t, `{"status":"paid"}`, string(entries[0].After))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type customerRepository struct {
+	db *sqlx.DB
+}
+
+func NewCustomerRepository(db *sqlx.DB) domain.CustomerRepository {
+	return &customerRepository{db: db}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *domain.Customer) error {
+	query := `INSERT INTO customers (id, name, phone, email, marketing_consent, created_at, updated_at)
+		VALUES (:id, :name, :phone, :email, :marketing_consent, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, customer)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	var customer domain.Customer
+	query := `SELECT id, name, phone, email, marketing_consent, created_at, updated_at FROM customers WHERE id = $1`
+	if err := r.db.GetContext(ctx, &customer, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *customerRepository) Search(ctx context.Context, filter domain.CustomerFilter) ([]domain.Customer, error) {
+	query := `SELECT id, name, phone, email, marketing_consent, created_at, updated_at FROM customers`
+	var conditions []string
+	var args []interface{}
+	if filter.Phone != "" {
+		args = append(args, filter.Phone)
+		conditions = append(conditions, fmt.Sprintf("phone = $%d", len(args)))
+	}
+	if filter.Email != "" {
+		args = append(args, filter.Email)
+		conditions = append(conditions, fmt.Sprintf("LOWER(email) = $%d", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	customers := []domain.Customer{}
+	if err := r.db.SelectContext(ctx, &customers, query, args...); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (r *customerRepository) Update(ctx context.Context, customer *domain.Customer) error {
+	query := `UPDATE customers SET name=:name, phone=:phone, email=:email, marketing_consent=:marketing_consent,
+		updated_at=:updated_at WHERE id=:id`
+	result, err := r.db.NamedExecContext(ctx, query, customer)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *customerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM customers WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerRepository_Create_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewCustomerRepository(sqlxDB)
+
+	customer := &domain.Customer{ID: uuid.New(), Name: "Sam", Phone: "5550102030", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO customers`)).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err = repo.Create(context.Background(), customer)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewCustomerRepository(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "email", "marketing_consent", "created_at", "updated_at"}).
+		AddRow(uuid.New(), "Sam", "5550102030", "sam@example.com", true, time.Now(), time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, phone, email, marketing_consent, created_at, updated_at FROM customers WHERE phone = $1 AND LOWER(email) = $2 ORDER BY name`)).
+		WithArgs("5550102030", "sam@example.com").
+		WillReturnRows(rows)
+
+	customers, err := repo.Search(context.Background(), domain.CustomerFilter{Phone: "5550102030", Email: "sam@example.com"})
+	assert.NoError(t, err)
+	assert.Len(t, customers, 1)
+	assert.True(t, customers[0].MarketingConsent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_Delete_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewCustomerRepository(sqlxDB)
+	id := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM customers WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+type giftCardRepository struct {
+	db *sqlx.DB
+}
+
+func NewGiftCardRepository(db *sqlx.DB) domain.GiftCardRepository {
+	return &giftCardRepository{db: db}
+}
+
+const giftCardTransactionInsert = `INSERT INTO gift_card_transactions (id, gift_card_id, order_id, type, amount, balance_after, created_at)
+		VALUES (:id, :gift_card_id, :order_id, :type, :amount, :balance_after, :created_at)`
+
+func (r *giftCardRepository) Create(ctx context.Context, card *domain.GiftCard, issue *domain.GiftCardTransaction) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO gift_cards (id, code, initial_value, balance, expires_at, issued_order_id, created_at, updated_at)
+		VALUES (:id, :code, :initial_value, :balance, :expires_at, :issued_order_id, :created_at, :updated_at)`
+	if _, err := tx.NamedExecContext(ctx, query, card); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrAlreadyExists
+		}
+		return err
+	}
+
+	issue.BalanceAfter = card.Balance
+	if _, err := tx.NamedExecContext(ctx, giftCardTransactionInsert, issue); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *giftCardRepository) GetByCode(ctx context.Context, code string) (*domain.GiftCard, error) {
+	var card domain.GiftCard
+	query := `SELECT id, code, initial_value, balance, expires_at, issued_order_id, created_at, updated_at
+		FROM gift_cards WHERE code = $1`
+	if err := r.db.GetContext(ctx, &card, query, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (r *giftCardRepository) ListByIssuedOrder(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCard, error) {
+	cards := []domain.GiftCard{}
+	query := `SELECT id, code, initial_value, balance, expires_at, issued_order_id, created_at, updated_at
+		FROM gift_cards WHERE issued_order_id = $1 ORDER BY created_at, code`
+	if err := r.db.SelectContext(ctx, &cards, query, orderID); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *giftCardRepository) ListTransactions(ctx context.Context, cardID uuid.UUID) ([]domain.GiftCardTransaction, error) {
+	var entries []domain.GiftCardTransaction
+	query := `SELECT id, gift_card_id, order_id, type, amount, balance_after, created_at
+		FROM gift_card_transactions WHERE gift_card_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &entries, query, cardID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *giftCardRepository) ListOrderTransactions(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCardTransaction, error) {
+	var entries []domain.GiftCardTransaction
+	query := `SELECT id, gift_card_id, order_id, type, amount, balance_after, created_at
+		FROM gift_card_transactions WHERE order_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &entries, query, orderID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *giftCardRepository) Credit(ctx context.Context, entry *domain.GiftCardTransaction, expiresAt *time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	balance, _, err := lockGiftCard(ctx, tx, entry.GiftCardID)
+	if err != nil {
+		return err
+	}
+	if err := applyGiftCardTransaction(ctx, tx, entry, balance, expiresAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *giftCardRepository) Void(ctx context.Context, entry *domain.GiftCardTransaction) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	balance, _, err := lockGiftCard(ctx, tx, entry.GiftCardID)
+	if err != nil {
+		return err
+	}
+	if balance.Add(entry.Amount).IsNegative() {
+		entry.Amount = balance.Neg()
+	}
+	if entry.Amount.IsZero() {
+		return nil
+	}
+	if err := applyGiftCardTransaction(ctx, tx, entry, balance, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// redeemGiftCard debits a card inside the caller's transaction, refusing
+// expired cards and debits larger than the balance.
+func redeemGiftCard(ctx context.Context, tx *sqlx.Tx, entry *domain.GiftCardTransaction) error {
+	balance, expiresAt, err := lockGiftCard(ctx, tx, entry.GiftCardID)
+	if err != nil {
+		return err
+	}
+	if expiresAt != nil && !entry.CreatedAt.Before(*expiresAt) {
+		return domain.ErrGiftCardExpired
+	}
+	if balance.Add(entry.Amount).IsNegative() {
+		return domain.ErrInsufficientGiftCardBalance
+	}
+	return applyGiftCardTransaction(ctx, tx, entry, balance, nil)
+}
+
+func lockGiftCard(ctx context.Context, tx *sqlx.Tx, cardID uuid.UUID) (decimal.Decimal, *time.Time, error) {
+	var card struct {
+		Balance   decimal.Decimal `db:"balance"`
+		ExpiresAt *time.Time      `db:"expires_at"`
+	}
+	query := `SELECT balance, expires_at FROM gift_cards WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &card, query, cardID); err != nil {
+		return decimal.Zero, nil, err
+	}
+	return card.Balance, card.ExpiresAt, nil
+}
+
+// applyGiftCardTransaction moves the card balance by entry.Amount and appends
+// the entry to the ledger.
+func applyGiftCardTransaction(ctx context.Context, tx *sqlx.Tx, entry *domain.GiftCardTransaction, balance decimal.Decimal, expiresAt *time.Time) error {
+	entry.BalanceAfter = balance.Add(entry.Amount)
+	query := `UPDATE gift_cards SET balance = $1, expires_at = COALESCE($2, expires_at), updated_at = $3 WHERE id = $4`
+	if _, err := tx.ExecContext(ctx, query, entry.BalanceAfter, expiresAt, entry.CreatedAt, entry.GiftCardID); err != nil {
+		return err
+	}
+	_, err := tx.NamedExecContext(ctx, giftCardTransactionInsert, entry)
+	return err
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiftCardRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewGiftCardRepository(sqlxDB)
+	now := time.Now()
+	card := &domain.GiftCard{
+		ID:           uuid.New(),
+		Code:         "ABCD-EFGH-JKLM-NPQR",
+		InitialValue: decimal.NewFromInt(25),
+		Balance:      decimal.NewFromInt(25),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	issue := &domain.GiftCardTransaction{ID: uuid.New(), GiftCardID: card.ID, Type: domain.GiftCardIssue, Amount: card.Balance, CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gift_cards`)).
+		WithArgs(card.ID, card.Code, card.InitialValue, card.Balance, card.ExpiresAt, card.IssuedOrderID, card.CreatedAt, card.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gift_card_transactions`)).
+		WithArgs(issue.ID, card.ID, issue.OrderID, domain.GiftCardIssue, issue.Amount, card.Balance, issue.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.Create(context.Background(), card, issue)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGiftCardRepository_Void_CapsAtBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewGiftCardRepository(sqlxDB)
+	entry := &domain.GiftCardTransaction{
+		ID:         uuid.New(),
+		GiftCardID: uuid.New(),
+		Type:       domain.GiftCardVoid,
+		Amount:     decimal.NewFromInt(-25),
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, expires_at FROM gift_cards WHERE id = $1 FOR UPDATE`)).
+		WithArgs(entry.GiftCardID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "expires_at"}).AddRow(decimal.NewFromInt(10), nil))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE gift_cards SET balance = $1`)).
+		WithArgs(decimal.Zero, nil, entry.CreatedAt, entry.GiftCardID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gift_card_transactions`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.Void(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "-10", entry.Amount.String())
+	assert.True(t, entry.BalanceAfter.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve takes over an expired record with the same key, so keys can be
+// reused once their window has passed. It also takes over a record of the same
+// request whose lease ran out before it completed.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	query := `INSERT INTO idempotency_keys (owner_id, idempotency_key, fingerprint, lease_id, locked_until, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (owner_id, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, lease_id = EXCLUDED.lease_id, locked_until = EXCLUDED.locked_until,
+			status_code = NULL, headers = NULL, response = NULL,
+			created_at = EXCLUDED.created_at, completed_at = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)`
+	result, err := r.db.ExecContext(ctx, query, record.OwnerID, record.Key, record.Fingerprint, record.LeaseID, record.LockedUntil,
+		record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 1 {
+		return nil, nil
+	}
+
+	// The existing record may have been released in the meantime, in which
+	// case this returns sql.ErrNoRows.
+	var existing domain.IdempotencyRecord
+	query = `SELECT owner_id, idempotency_key, fingerprint, lease_id, locked_until, status_code, headers, response,
+		created_at, completed_at, expires_at
+		FROM idempotency_keys WHERE owner_id = $1 AND idempotency_key = $2`
+	if err := r.db.GetContext(ctx, &existing, query, record.OwnerID, record.Key); err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, headers = $2, response = $3, completed_at = $4
+		WHERE owner_id = $5 AND idempotency_key = $6 AND lease_id = $7 AND completed_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, record.StatusCode, jsonParam(record.Headers), record.Response, record.CompletedAt,
+		record.OwnerID, record.Key, record.LeaseID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	query := `DELETE FROM idempotency_keys WHERE owner_id = $1 AND idempotency_key = $2 AND lease_id = $3 AND completed_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, record.OwnerID, record.Key, record.LeaseID)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	return err
+}
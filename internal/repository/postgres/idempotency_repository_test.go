@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewIdempotencyRepository(sqlxDB)
+	now := time.Now()
+	record := &domain.IdempotencyRecord{OwnerID: uuid.New(), Key: "k", Fingerprint: "fp", LeaseID: uuid.New(),
+		LockedUntil: now.Add(time.Minute), CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	insert := regexp.QuoteMeta(`INSERT INTO idempotency_keys (owner_id, idempotency_key, fingerprint, lease_id, locked_until, created_at, expires_at)`)
+	reclaim := regexp.QuoteMeta(`OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)`)
+	mock.ExpectExec(insert+`.*`+reclaim).
+		WithArgs(record.OwnerID, record.Key, record.Fingerprint, record.LeaseID, record.LockedUntil, record.CreatedAt, record.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM idempotency_keys WHERE owner_id = $1 AND idempotency_key = $2`)).
+		WithArgs(record.OwnerID, record.Key).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "idempotency_key", "fingerprint", "lease_id", "locked_until", "status_code", "headers", "response", "created_at", "completed_at", "expires_at"}).
+			AddRow(record.OwnerID, "k", "fp", uuid.New(), now, 201, []byte(`{"ETag":"\"1\""}`), []byte(`{"id":1}`), now, now, now.Add(time.Hour)))
+
+	existing, err := repo.Reserve(context.Background(), record)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	existing, err = repo.Reserve(context.Background(), record)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, *existing.StatusCode)
+	assert.Equal(t, `{"ETag":"\"1\""}`, string(existing.Headers))
+	assert.Equal(t, `{"id":1}`, string(existing.Response))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Complete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewIdempotencyRepository(sqlxDB)
+	status := 200
+	now := time.Now()
+	record := &domain.IdempotencyRecord{OwnerID: uuid.New(), Key: "k", LeaseID: uuid.New(), StatusCode: &status,
+		Headers: []byte(`{"Location":"/orders/1"}`), Response: []byte(`{}`), CompletedAt: &now}
+
+	// Once the lease was taken over by a retry, the update matches no row.
+	query := regexp.QuoteMeta(`UPDATE idempotency_keys SET status_code = $1, headers = $2, response = $3, completed_at = $4
+		WHERE owner_id = $5 AND idempotency_key = $6 AND lease_id = $7 AND completed_at IS NULL`)
+	mock.ExpectExec(query).
+		WithArgs(record.StatusCode, `{"Location":"/orders/1"}`, record.Response, record.CompletedAt, record.OwnerID, record.Key, record.LeaseID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.Complete(context.Background(), record))
+	assert.ErrorIs(t, repo.Complete(context.Background(), record), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Release(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewIdempotencyRepository(sqlxDB)
+	record := &domain.IdempotencyRecord{OwnerID: uuid.New(), Key: "k", LeaseID: uuid.New()}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE owner_id = $1 AND idempotency_key = $2 AND lease_id = $3 AND completed_at IS NULL`)).
+		WithArgs(record.OwnerID, record.Key, record.LeaseID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.Release(context.Background(), record))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
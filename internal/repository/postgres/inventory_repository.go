@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+type inventoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewInventoryRepository(db *sqlx.DB) domain.InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+func (r *inventoryRepository) CreateIngredient(ctx context.Context, ingredient *domain.Ingredient) error {
+	query := `INSERT INTO ingredients (id, name, unit, unit_cost, baseline_quantity, baseline_at, created_at, updated_at)
+		VALUES (:id, :name, :unit, :unit_cost, :baseline_quantity, :baseline_at, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, ingredient)
+	return err
+}
+
+func (r *inventoryRepository) GetIngredientByID(ctx context.Context, id uuid.UUID) (*domain.Ingredient, error) {
+	var ingredient domain.Ingredient
+	query := `SELECT id, name, unit, unit_cost, baseline_quantity, baseline_at, created_at, updated_at
+		FROM ingredients WHERE id = $1`
+	if err := r.db.GetContext(ctx, &ingredient, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+func (r *inventoryRepository) ListIngredients(ctx context.Context) ([]domain.Ingredient, error) {
+	var ingredients []domain.Ingredient
+	query := `SELECT id, name, unit, unit_cost, baseline_quantity, baseline_at, created_at, updated_at
+		FROM ingredients ORDER BY name`
+	if err := r.db.SelectContext(ctx, &ingredients, query); err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *inventoryRepository) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]domain.RecipeItem, error) {
+	items := []domain.RecipeItem{}
+	query := `SELECT menu_item_id, ingredient_id, quantity FROM recipe_items WHERE menu_item_id = $1 ORDER BY ingredient_id`
+	if err := r.db.SelectContext(ctx, &items, query, menuItemID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetMenuItemCost returns the theoretical cost of one unit of a menu item,
+// valued at the latest unit cost of each ingredient in its recipe.
+func (r *inventoryRepository) GetMenuItemCost(ctx context.Context, menuItemID uuid.UUID) (decimal.Decimal, error) {
+	var cost decimal.Decimal
+	query := `SELECT COALESCE(SUM(ri.quantity * i.unit_cost), 0) FROM recipe_items ri
+		JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.menu_item_id = $1`
+	if err := r.db.GetContext(ctx, &cost, query, menuItemID); err != nil {
+		return decimal.Zero, err
+	}
+	return cost, nil
+}
+
+func (r *inventoryRepository) ReplaceRecipe(ctx context.Context, menuItemID uuid.UUID, items []domain.RecipeItem) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipe_items WHERE menu_item_id = $1`, menuItemID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO recipe_items (menu_item_id, ingredient_id, quantity) VALUES (:menu_item_id, :ingredient_id, :quantity)`
+	for i := range items {
+		if _, err := tx.NamedExecContext(ctx, query, &items[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateMovement records a stock movement. A costed receipt also becomes the
+// latest unit cost of its ingredient.
+func (r *inventoryRepository) CreateMovement(ctx context.Context, movement *domain.StockMovement) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO stock_movements (id, ingredient_id, type, quantity, unit_cost, note, occurred_at)
+		VALUES (:id, :ingredient_id, :type, :quantity, :unit_cost, :note, :occurred_at)`
+	if _, err := tx.NamedExecContext(ctx, query, movement); err != nil {
+		return err
+	}
+
+	if movement.Type == domain.StockMovementReceipt && movement.UnitCost.IsPositive() {
+		costQuery := `UPDATE ingredients SET unit_cost = $1, updated_at = $2 WHERE id = $3`
+		if _, err := tx.ExecContext(ctx, costQuery, movement.UnitCost, movement.OccurredAt, movement.IngredientID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *inventoryRepository) CreateStockCount(ctx context.Context, count *domain.StockCount) error {
+	query := `INSERT INTO stock_counts (id, status, note, counted_at) VALUES (:id, :status, :note, :counted_at)`
+	_, err := r.db.NamedExecContext(ctx, query, count)
+	return err
+}
+
+func (r *inventoryRepository) GetStockCount(ctx context.Context, id uuid.UUID) (*domain.StockCount, error) {
+	var count domain.StockCount
+	query := `SELECT id, status, note, counted_at, applied_at FROM stock_counts WHERE id = $1`
+	if err := r.db.GetContext(ctx, &count, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	count.Lines = []domain.StockCountLine{}
+	linesQuery := `SELECT stock_count_id, ingredient_id, counted_quantity FROM stock_count_lines
+		WHERE stock_count_id = $1 ORDER BY ingredient_id`
+	if err := r.db.SelectContext(ctx, &count.Lines, linesQuery, id); err != nil {
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+func (r *inventoryRepository) UpsertStockCountLines(ctx context.Context, countID uuid.UUID, lines []domain.StockCountLine) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO stock_count_lines (stock_count_id, ingredient_id, counted_quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (stock_count_id, ingredient_id) DO UPDATE SET counted_quantity = EXCLUDED.counted_quantity`
+	for _, line := range lines {
+		if _, err := tx.ExecContext(ctx, query, countID, line.IngredientID, line.CountedQuantity); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetStockLevels returns, for every ingredient, the movements between its
+// baseline and asOf. Sales are derived from paid and completed orders through
+// the recipe of each sold menu item, and fall in the period the order was paid
+// in rather than the one it was opened in.
+func (r *inventoryRepository) GetStockLevels(ctx context.Context, asOf time.Time) ([]domain.StockLevel, error) {
+	query := `SELECT i.id AS ingredient_id, i.name, i.unit, i.unit_cost, i.baseline_quantity AS opening,
+		COALESCE((SELECT SUM(m.quantity) FROM stock_movements m
+			WHERE m.ingredient_id = i.id AND m.type = 'receipt' AND m.occurred_at > i.baseline_at AND m.occurred_at <= $1), 0) AS received,
+		COALESCE((SELECT SUM(oi.quantity * COALESCE(oi.share, 1) * ri.quantity) FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			JOIN LATERAL (SELECT COALESCE(MIN(h.created_at), o.created_at) AS sold_at FROM order_status_history h
+				WHERE h.order_id = o.id AND h.to_status IN ('paid', 'completed')) s ON TRUE
+			JOIN recipe_items ri ON ri.menu_item_id = oi.menu_item_id
+			WHERE ri.ingredient_id = i.id AND o.status IN ('paid', 'completed') AND oi.voided_at IS NULL
+			AND s.sold_at > i.baseline_at AND s.sold_at <= $1), 0) AS sold,
+		COALESCE((SELECT SUM(m.quantity) FROM stock_movements m
+			WHERE m.ingredient_id = i.id AND m.type = 'waste' AND m.occurred_at > i.baseline_at AND m.occurred_at <= $1), 0) AS waste
+		FROM ingredients i
+		ORDER BY i.name`
+
+	var levels []domain.StockLevel
+	if err := r.db.SelectContext(ctx, &levels, query, asOf); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// ApplyStockCount makes the counted quantities the new stock baseline of their
+// ingredients and marks the count as applied, in a single transaction. It
+// returns ErrConflict if any of the ingredients already has a baseline from a
+// later count.
+func (r *inventoryRepository) ApplyStockCount(ctx context.Context, countID uuid.UUID, appliedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE stock_counts SET status = 'applied', applied_at = $1
+		WHERE id = $2 AND status = 'open'`, appliedAt, countID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	var lines int64
+	if err := tx.GetContext(ctx, &lines, `SELECT COUNT(*) FROM stock_count_lines WHERE stock_count_id = $1`, countID); err != nil {
+		return err
+	}
+	baselineQuery := `UPDATE ingredients i SET baseline_quantity = l.counted_quantity, baseline_at = c.counted_at, updated_at = $1
+		FROM stock_count_lines l
+		JOIN stock_counts c ON c.id = l.stock_count_id
+		WHERE l.stock_count_id = $2 AND l.ingredient_id = i.id AND c.counted_at > i.baseline_at`
+	result, err = tx.ExecContext(ctx, baselineQuery, appliedAt, countID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err = result.RowsAffected(); err != nil {
+		return err
+	}
+	if rowsAffected != lines {
+		return domain.ErrConflict
+	}
+
+	return tx.Commit()
+}
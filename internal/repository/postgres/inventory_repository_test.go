@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventoryRepository_ReplaceRecipe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+
+	menuItemID := uuid.New()
+	item := domain.RecipeItem{MenuItemID: menuItemID, IngredientID: uuid.New(), Quantity: decimal.NewFromFloat(0.2)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM recipe_items WHERE menu_item_id = $1`)).
+		WithArgs(menuItemID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO recipe_items (menu_item_id, ingredient_id, quantity) VALUES (?, ?, ?)`)).
+		WithArgs(item.MenuItemID, item.IngredientID, item.Quantity).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.ReplaceRecipe(context.Background(), menuItemID, []domain.RecipeItem{item})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_CreateMovement_ReceiptUpdatesUnitCost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+
+	movement := &domain.StockMovement{
+		ID:           uuid.New(),
+		IngredientID: uuid.New(),
+		Type:         domain.StockMovementReceipt,
+		Quantity:     decimal.NewFromInt(12),
+		UnitCost:     decimal.NewFromFloat(1.10),
+		OccurredAt:   time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stock_movements`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE ingredients SET unit_cost = $1, updated_at = $2 WHERE id = $3`)).
+		WithArgs(movement.UnitCost, movement.OccurredAt, movement.IngredientID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.CreateMovement(context.Background(), movement)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_GetStockLevels_SkipsVoidedLines(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	asOf := time.Now()
+
+	// Voided lines give their ingredients back by no longer counting as sold.
+	mock.ExpectQuery(`o\.status IN \('paid', 'completed'\) AND oi\.voided_at IS NULL`).
+		WithArgs(asOf).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient_id", "name", "unit", "unit_cost", "opening", "received", "sold", "waste"}).
+			AddRow(uuid.New(), "Milk", "l", decimal.NewFromFloat(1.2), decimal.NewFromInt(10), decimal.NewFromInt(5), decimal.NewFromInt(3), decimal.Zero))
+
+	levels, err := repo.GetStockLevels(context.Background(), asOf)
+	assert.NoError(t, err)
+	if assert.Len(t, levels, 1) {
+		assert.True(t, levels[0].Sold.Equal(decimal.NewFromInt(3)))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_GetStockCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	countID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, status, note, counted_at, applied_at FROM stock_counts WHERE id = $1`)).
+		WithArgs(countID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "note", "counted_at", "applied_at"}).
+			AddRow(countID, domain.StockCountStatusOpen, "weekly", time.Now(), nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT stock_count_id, ingredient_id, counted_quantity FROM stock_count_lines`)).
+		WithArgs(countID).
+		WillReturnRows(sqlmock.NewRows([]string{"stock_count_id", "ingredient_id", "counted_quantity"}).
+			AddRow(countID, uuid.New(), decimal.NewFromInt(4)))
+
+	count, err := repo.GetStockCount(context.Background(), countID)
+	assert.NoError(t, err)
+	assert.Equal(t, "weekly", count.Note)
+	assert.Len(t, count.Lines, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_GetStockCount_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	countID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, status, note, counted_at, applied_at FROM stock_counts WHERE id = $1`)).
+		WithArgs(countID).
+		WillReturnError(sql.ErrNoRows)
+
+	count, err := repo.GetStockCount(context.Background(), countID)
+	assert.NoError(t, err)
+	assert.Nil(t, count)
+}
+
+func TestInventoryRepository_ApplyStockCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	countID := uuid.New()
+	appliedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stock_counts SET status = 'applied'`)).
+		WithArgs(appliedAt, countID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM stock_count_lines WHERE stock_count_id = $1`)).
+		WithArgs(countID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE ingredients i SET baseline_quantity = l.counted_quantity`)).
+		WithArgs(appliedAt, countID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	err = repo.ApplyStockCount(context.Background(), countID, appliedAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_ApplyStockCount_AlreadyApplied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	countID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stock_counts SET status = 'applied'`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.ApplyStockCount(context.Background(), countID, time.Now())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_ApplyStockCount_Stale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewInventoryRepository(sqlxDB)
+	countID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stock_counts SET status = 'applied'`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM stock_count_lines`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta(`AND c.counted_at > i.baseline_at`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectRollback()
+
+	err = repo.ApplyStockCount(context.Background(), countID, time.Now())
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
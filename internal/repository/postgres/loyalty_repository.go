@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type loyaltyRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoyaltyRepository(db *sqlx.DB) domain.LoyaltyRepository {
+	return &loyaltyRepository{db: db}
+}
+
+func (r *loyaltyRepository) Balance(ctx context.Context, customerID uuid.UUID) (int64, error) {
+	var balance int64
+	query := `SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1`
+	if err := r.db.GetContext(ctx, &balance, query, customerID); err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *loyaltyRepository) ListEntries(ctx context.Context, customerID uuid.UUID) ([]domain.LoyaltyEntry, error) {
+	var entries []domain.LoyaltyEntry
+	query := `SELECT id, customer_id, order_id, order_item_id, type, points, created_at FROM loyalty_ledger
+		WHERE customer_id = $1 ORDER BY created_at DESC`
+	if err := r.db.SelectContext(ctx, &entries, query, customerID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *loyaltyRepository) ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]domain.LoyaltyEntry, error) {
+	var entries []domain.LoyaltyEntry
+	query := `SELECT id, customer_id, order_id, order_item_id, type, points, created_at FROM loyalty_ledger
+		WHERE order_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &entries, query, orderID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddEntry appends an entry to the ledger. An order can only hold one entry of
+// each type, so repeating an earn or reversal is a no-op.
+func (r *loyaltyRepository) AddEntry(ctx context.Context, entry *domain.LoyaltyEntry) error {
+	query := `INSERT INTO loyalty_ledger (id, customer_id, order_id, order_item_id, type, points, created_at)
+		VALUES (:id, :customer_id, :order_id, :order_item_id, :type, :points, :created_at)
+		ON CONFLICT (order_id, type) WHERE order_id IS NOT NULL AND type <> 'void' DO NOTHING`
+	_, err := r.db.NamedExecContext(ctx, query, entry)
+	return err
+}
+
+// insertLoyaltyEntry appends an entry to the ledger within tx.
+func insertLoyaltyEntry(ctx context.Context, tx *sqlx.Tx, entry *domain.LoyaltyEntry) error {
+	query := `INSERT INTO loyalty_ledger (id, customer_id, order_id, order_item_id, type, points, created_at)
+		VALUES (:id, :customer_id, :order_id, :order_item_id, :type, :points, :created_at)`
+	_, err := tx.NamedExecContext(ctx, query, entry)
+	return err
+}
+
+// Redeem debits the customer's balance, locking the customer row so concurrent
+// redemptions cannot overdraw it.
+func (r *loyaltyRepository) Redeem(ctx context.Context, entry *domain.LoyaltyEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var customerID uuid.UUID
+	if err := tx.GetContext(ctx, &customerID, `SELECT id FROM customers WHERE id = $1 FOR UPDATE`, entry.CustomerID); err != nil {
+		return err
+	}
+
+	var balance int64
+	if err := tx.GetContext(ctx, &balance, `SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1`, entry.CustomerID); err != nil {
+		return err
+	}
+	if balance+entry.Points < 0 {
+		return domain.ErrInsufficientPoints
+	}
+
+	query := `INSERT INTO loyalty_ledger (id, customer_id, order_id, type, points, created_at)
+		VALUES (:id, :customer_id, :order_id, :type, :points, :created_at)`
+	if _, err := tx.NamedExecContext(ctx, query, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoyaltyRepository_Balance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewLoyaltyRepository(sqlxDB)
+	customerID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1`)).
+		WithArgs(customerID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(120))
+
+	balance, err := repo.Balance(context.Background(), customerID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(120), balance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoyaltyRepository_Redeem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewLoyaltyRepository(sqlxDB)
+	orderID := uuid.New()
+	entry := &domain.LoyaltyEntry{
+		ID:         uuid.New(),
+		CustomerID: uuid.New(),
+		OrderID:    &orderID,
+		Type:       domain.LoyaltyEntryRedeem,
+		Points:     -50,
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM customers WHERE id = $1 FOR UPDATE`)).
+		WithArgs(entry.CustomerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entry.CustomerID))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1`)).
+		WithArgs(entry.CustomerID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(80))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO loyalty_ledger (id, customer_id, order_id, type, points, created_at)`)).
+		WithArgs(entry.ID, entry.CustomerID, entry.OrderID, entry.Type, entry.Points, entry.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.Redeem(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoyaltyRepository_Redeem_InsufficientPoints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewLoyaltyRepository(sqlxDB)
+	entry := &domain.LoyaltyEntry{ID: uuid.New(), CustomerID: uuid.New(), Type: domain.LoyaltyEntryRedeem, Points: -50}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM customers WHERE id = $1 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entry.CustomerID))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(20))
+	mock.ExpectRollback()
+
+	err = repo.Redeem(context.Background(), entry)
+	assert.ErrorIs(t, err, domain.ErrInsufficientPoints)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
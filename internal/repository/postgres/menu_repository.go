@@ -18,10 +18,21 @@ func NewMenuItemRepository(db *sqlx.DB) domain.MenuItemRepository {
 }
 
 func (r *menuRepository) Create(ctx context.Context, item *domain.MenuItem) error {
-	query := `INSERT INTO menu_items (id, name, description, price, category, is_available, created_at, updated_at)
-              VALUES (:id, :name, :description, :price, :category, :is_available, :created_at, :updated_at)`
-	_, err := r.db.NamedExecContext(ctx, query, item)
-	return err
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO menu_items (id, name, description, price, category, is_available, version, created_at, updated_at)
+              VALUES (:id, :name, :description, :price, :category, :is_available, :version, :created_at, :updated_at)`
+	if _, err := tx.NamedExecContext(ctx, query, item); err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, item.Audit); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *menuRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MenuItem, error) {
@@ -48,9 +59,15 @@ func (r *menuRepository) Fetch(ctx context.Context) ([]domain.MenuItem, error) {
 }
 
 func (r *menuRepository) Update(ctx context.Context, item *domain.MenuItem) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `UPDATE menu_items SET name=:name, description=:description, price=:price, category=:category,
-              is_available=:is_available, updated_at=:updated_at WHERE id=:id`
-	result, err := r.db.NamedExecContext(ctx, query, item)
+              is_available=:is_available, version=version + 1, updated_at=:updated_at WHERE id=:id AND version=:version`
+	result, err := tx.NamedExecContext(ctx, query, item)
 	if err != nil {
 		return err
 	}
@@ -61,15 +78,28 @@ func (r *menuRepository) Update(ctx context.Context, item *domain.MenuItem) erro
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return missingOrChanged(ctx, tx, item.ID)
+	}
+	if err := insertAuditEntry(ctx, tx, item.Audit); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
 	}
 
+	item.Version++
 	return nil
 }
 
-func (r *menuRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM menu_items WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *menuRepository) Delete(ctx context.Context, item *domain.MenuItem) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM menu_items WHERE id = $1 AND version = $2`
+	result, err := tx.ExecContext(ctx, query, item.ID, item.Version)
 	if err != nil {
 		return err
 	}
@@ -80,8 +110,22 @@ func (r *menuRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return missingOrChanged(ctx, tx, item.ID)
 	}
+	if err := insertAuditEntry(ctx, tx, item.Audit); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return nil
+// missingOrChanged explains why a versioned write matched no rows.
+func missingOrChanged(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) error {
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM menu_items WHERE id = $1)`, id); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrConflict
+	}
+	return sql.ErrNoRows
 }
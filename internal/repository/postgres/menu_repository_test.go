@@ -33,13 +33,20 @@ func TestMenuRepository_Create(t *testing.T) {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	item.Audit = &domain.AuditEntry{ID: uuid.New(), Action: domain.AuditMenuItemCreate, EntityType: domain.AuditEntityMenuItem, EntityID: item.ID}
 
-	query := `INSERT INTO menu_items (id, name, description, price, category, is_available, created_at, updated_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO menu_items (id, name, description, price, category, is_available, version, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
+	// The audit entry is written in the same transaction as the item.
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(query)).
-		WithArgs(item.ID, item.Name, item.Description, item.Price, item.Category, item.IsAvailable, item.CreatedAt, item.UpdatedAt).
+		WithArgs(item.ID, item.Name, item.Description, item.Price, item.Category, item.IsAvailable, item.Version, item.CreatedAt, item.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log`)).
+		WithArgs(item.Audit.ID, nil, nil, "", domain.AuditMenuItemCreate, domain.AuditEntityMenuItem, item.ID, nil, nil, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	err = repo.Create(context.Background(), item)
 	assert.NoError(t, err)
@@ -114,18 +121,22 @@ func TestMenuRepository_Update(t *testing.T) {
 		Price:       decimal.NewFromFloat(4.50),
 		Category:    "Coffee",
 		IsAvailable: true,
+		Version:     2,
 		UpdatedAt:   time.Now(),
 	}
 
 	query := `UPDATE menu_items SET name=?, description=?, price=?, category=?,
-              is_available=?, updated_at=? WHERE id=?`
+              is_available=?, version=version + 1, updated_at=? WHERE id=? AND version=?`
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(query)).
-		WithArgs(item.Name, item.Description, item.Price, item.Category, item.IsAvailable, item.UpdatedAt, item.ID).
+		WithArgs(item.Name, item.Description, item.Price, item.Category, item.IsAvailable, item.UpdatedAt, item.ID, item.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	err = repo.Update(context.Background(), item)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(3), item.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -144,16 +155,48 @@ func TestMenuRepository_Update_NotFound(t *testing.T) {
 	}
 
 	query := `UPDATE menu_items SET name=?, description=?, price=?, category=?,
-              is_available=?, updated_at=? WHERE id=?`
+              is_available=?, version=version + 1, updated_at=? WHERE id=? AND version=?`
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(query)).
 		WillReturnResult(sqlmock.NewResult(0, 0)) // 0 rows affected
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM menu_items WHERE id = $1)`)).
+		WithArgs(item.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
 
 	err = repo.Update(context.Background(), item)
 	assert.ErrorIs(t, err, sql.ErrNoRows)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestMenuRepository_Update_Conflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewMenuItemRepository(sqlxDB)
+
+	item := &domain.MenuItem{ID: uuid.New(), Name: "Updated Latte", Version: 1, UpdatedAt: time.Now()}
+
+	item.Audit = &domain.AuditEntry{ID: uuid.New()}
+
+	// A write that loses the race leaves no audit entry behind.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE menu_items SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM menu_items WHERE id = $1)`)).
+		WithArgs(item.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = repo.Update(context.Background(), item)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Equal(t, int64(1), item.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestMenuRepository_Delete(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -163,13 +206,18 @@ func TestMenuRepository_Delete(t *testing.T) {
 	repo := NewMenuItemRepository(sqlxDB)
 
 	id := uuid.New()
-	query := `DELETE FROM menu_items WHERE id = $1`
+	item := &domain.MenuItem{ID: id, Version: 1, Audit: &domain.AuditEntry{ID: uuid.New(), Action: domain.AuditMenuItemDelete}}
+	query := `DELETE FROM menu_items WHERE id = $1 AND version = $2`
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(query)).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = repo.Delete(context.Background(), id)
+	err = repo.Delete(context.Background(), item)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
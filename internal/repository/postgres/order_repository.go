@@ -3,11 +3,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"coffee-shop-pos/internal/domain"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 )
 
@@ -26,46 +30,105 @@ func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error
 	}
 	defer tx.Rollback()
 
-	orderQuery := `INSERT INTO orders (id, order_number, status, subtotal, tax, total, created_at, updated_at)
-		VALUES (:id, :order_number, :status, :subtotal, :tax, :total, :created_at, :updated_at)`
+	if err := bookSlot(ctx, tx, order.ID, order.SlotBooking); err != nil {
+		return err
+	}
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := recordApproval(ctx, tx, order.Approval); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertOrder numbers the order and stores it with its lines and history.
+func insertOrder(ctx context.Context, tx *sqlx.Tx, order *domain.Order) error {
+	// The counter row stays locked until the order commits, so concurrent
+	// orders get consecutive numbers and a failed one does not leave a gap.
+	var number int
+	counterQuery := `INSERT INTO order_number_counters (store_code, business_date, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (store_code, business_date) DO UPDATE SET last_number = order_number_counters.last_number + 1
+		RETURNING last_number`
+	if err := tx.GetContext(ctx, &number, counterQuery, order.StoreCode, order.BusinessDate); err != nil {
+		return err
+	}
+	order.OrderNumber = domain.FormatOrderNumber(order.StoreCode, number)
+	order.Reference = domain.FormatOrderReference(order.StoreCode, order.BusinessDate, number)
+
+	orderQuery := `INSERT INTO orders (id, order_number, reference, store_code, business_date, status, order_type, table_number, call_out_name, note, pickup_at, released_at, parent_order_id, customer_id, subtotal, discount, manual_discount, tax, service_charge, total, redeemed_points, cashier_id, terminal_id, version, created_at, updated_at)
+		VALUES (:id, :order_number, :reference, :store_code, :business_date, :status, :order_type, :table_number, :call_out_name, :note, :pickup_at, :released_at, :parent_order_id, :customer_id, :subtotal, :discount, :manual_discount, :tax, :service_charge, :total, :redeemed_points, :cashier_id, :terminal_id, :version, :created_at, :updated_at)`
 	if _, err := tx.NamedExecContext(ctx, orderQuery, order); err != nil {
 		return err
 	}
 
-	itemQuery := `INSERT INTO order_items (id, order_id, menu_item_id, quantity, unit_price, line_total)
-		VALUES (:id, :order_id, :menu_item_id, :quantity, :unit_price, :line_total)`
-	for i := range order.Items {
-		if _, err := tx.NamedExecContext(ctx, itemQuery, &order.Items[i]); err != nil {
+	if err := insertOrderItems(ctx, tx, order.Items); err != nil {
+		return err
+	}
+
+	for i := range order.StatusHistory {
+		if err := insertStatusChange(ctx, tx, &order.StatusHistory[i]); err != nil {
 			return err
 		}
 	}
-
-	return tx.Commit()
+	return nil
 }
 
 func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
-	query := `SELECT o.id, o.order_number, o.status, o.subtotal, o.tax, o.total, o.created_at, o.updated_at,
-		oi.id AS item_id, oi.order_id, oi.menu_item_id, oi.quantity, oi.unit_price, oi.line_total
+	query := `SELECT o.id, o.order_number, o.reference, o.store_code, o.business_date, o.status, o.order_type, o.table_number, o.call_out_name, o.note, o.pickup_at, o.released_at,
+		o.tab_name, o.tab_opened_at, o.parent_order_id, o.merged_into_id, o.customer_id, o.subtotal, o.discount, o.manual_discount, o.tax, o.service_charge, o.total, o.redeemed_points,
+		o.cashier_id, o.terminal_id, o.version, o.created_at, o.updated_at,
+		oi.id AS item_id, oi.order_id, oi.menu_item_id, oi.quantity, oi.unit_price, oi.line_total, oi.unit_cost,
+		oi.gift_card_code, oi.stamp_program_id, oi.voided_at, oi.void_reason, oi.voided_by, oi.share, oi.note AS item_note
 		FROM orders o
 		LEFT JOIN order_items oi ON oi.order_id = o.id
 		WHERE o.id = $1
 		ORDER BY oi.id`
 
 	type orderJoinRow struct {
-		ID          uuid.UUID        `db:"id"`
-		OrderNumber string           `db:"order_number"`
-		Status      string           `db:"status"`
-		Subtotal    decimal.Decimal  `db:"subtotal"`
-		Tax         decimal.Decimal  `db:"tax"`
-		Total       decimal.Decimal  `db:"total"`
-		CreatedAt   time.Time        `db:"created_at"`
-		UpdatedAt   time.Time        `db:"updated_at"`
-		ItemID      *uuid.UUID       `db:"item_id"`
-		OrderID     *uuid.UUID       `db:"order_id"`
-		MenuItemID  *uuid.UUID       `db:"menu_item_id"`
-		Quantity    *int             `db:"quantity"`
-		UnitPrice   *decimal.Decimal `db:"unit_price"`
-		LineTotal   *decimal.Decimal `db:"line_total"`
+		ID             uuid.UUID        `db:"id"`
+		OrderNumber    string           `db:"order_number"`
+		Reference      string           `db:"reference"`
+		StoreCode      string           `db:"store_code"`
+		BusinessDate   time.Time        `db:"business_date"`
+		Status         string           `db:"status"`
+		OrderType      string           `db:"order_type"`
+		TableNumber    string           `db:"table_number"`
+		CallOutName    string           `db:"call_out_name"`
+		Note           string           `db:"note"`
+		PickupAt       *time.Time       `db:"pickup_at"`
+		ReleasedAt     *time.Time       `db:"released_at"`
+		TabName        string           `db:"tab_name"`
+		TabOpenedAt    *time.Time       `db:"tab_opened_at"`
+		ParentOrderID  *uuid.UUID       `db:"parent_order_id"`
+		MergedIntoID   *uuid.UUID       `db:"merged_into_id"`
+		CustomerID     *uuid.UUID       `db:"customer_id"`
+		Subtotal       decimal.Decimal  `db:"subtotal"`
+		Discount       decimal.Decimal  `db:"discount"`
+		ManualDiscount decimal.Decimal  `db:"manual_discount"`
+		Tax            decimal.Decimal  `db:"tax"`
+		ServiceCharge  decimal.Decimal  `db:"service_charge"`
+		Total          decimal.Decimal  `db:"total"`
+		RedeemedPoints int64            `db:"redeemed_points"`
+		CashierID      *uuid.UUID       `db:"cashier_id"`
+		TerminalID     *uuid.UUID       `db:"terminal_id"`
+		Version        int64            `db:"version"`
+		CreatedAt      time.Time        `db:"created_at"`
+		UpdatedAt      time.Time        `db:"updated_at"`
+		ItemID         *uuid.UUID       `db:"item_id"`
+		OrderID        *uuid.UUID       `db:"order_id"`
+		MenuItemID     *uuid.UUID       `db:"menu_item_id"`
+		Quantity       *int             `db:"quantity"`
+		UnitPrice      *decimal.Decimal `db:"unit_price"`
+		LineTotal      *decimal.Decimal `db:"line_total"`
+		UnitCost       *decimal.Decimal `db:"unit_cost"`
+		GiftCardCode   *string          `db:"gift_card_code"`
+		StampProgramID *uuid.UUID       `db:"stamp_program_id"`
+		VoidedAt       *time.Time       `db:"voided_at"`
+		VoidReason     *string          `db:"void_reason"`
+		VoidedBy       *uuid.UUID       `db:"voided_by"`
+		Share          *decimal.Decimal `db:"share"`
+		ItemNote       *string          `db:"item_note"`
 	}
 
 	var rows []orderJoinRow
@@ -80,15 +143,36 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Or
 	}
 
 	order := &domain.Order{
-		ID:          rows[0].ID,
-		OrderNumber: rows[0].OrderNumber,
-		Status:      rows[0].Status,
-		Subtotal:    rows[0].Subtotal,
-		Tax:         rows[0].Tax,
-		Total:       rows[0].Total,
-		CreatedAt:   rows[0].CreatedAt,
-		UpdatedAt:   rows[0].UpdatedAt,
-		Items:       []domain.OrderItem{},
+		ID:             rows[0].ID,
+		OrderNumber:    rows[0].OrderNumber,
+		Reference:      rows[0].Reference,
+		StoreCode:      rows[0].StoreCode,
+		BusinessDate:   rows[0].BusinessDate,
+		Status:         rows[0].Status,
+		OrderType:      rows[0].OrderType,
+		TableNumber:    rows[0].TableNumber,
+		CallOutName:    rows[0].CallOutName,
+		Note:           rows[0].Note,
+		PickupAt:       rows[0].PickupAt,
+		ReleasedAt:     rows[0].ReleasedAt,
+		TabName:        rows[0].TabName,
+		TabOpenedAt:    rows[0].TabOpenedAt,
+		ParentOrderID:  rows[0].ParentOrderID,
+		MergedIntoID:   rows[0].MergedIntoID,
+		CustomerID:     rows[0].CustomerID,
+		Subtotal:       rows[0].Subtotal,
+		Discount:       rows[0].Discount,
+		ManualDiscount: rows[0].ManualDiscount,
+		Tax:            rows[0].Tax,
+		ServiceCharge:  rows[0].ServiceCharge,
+		Total:          rows[0].Total,
+		RedeemedPoints: rows[0].RedeemedPoints,
+		CashierID:      rows[0].CashierID,
+		TerminalID:     rows[0].TerminalID,
+		Version:        rows[0].Version,
+		CreatedAt:      rows[0].CreatedAt,
+		UpdatedAt:      rows[0].UpdatedAt,
+		Items:          []domain.OrderItem{},
 	}
 
 	for _, row := range rows {
@@ -96,12 +180,26 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Or
 			continue
 		}
 		item := domain.OrderItem{
-			ID:         *row.ItemID,
-			OrderID:    *row.OrderID,
-			MenuItemID: *row.MenuItemID,
-			Quantity:   *row.Quantity,
-			UnitPrice:  *row.UnitPrice,
-			LineTotal:  *row.LineTotal,
+			ID:             *row.ItemID,
+			OrderID:        *row.OrderID,
+			MenuItemID:     *row.MenuItemID,
+			Quantity:       *row.Quantity,
+			UnitPrice:      *row.UnitPrice,
+			LineTotal:      *row.LineTotal,
+			UnitCost:       *row.UnitCost,
+			StampProgramID: row.StampProgramID,
+			VoidedAt:       row.VoidedAt,
+			VoidedBy:       row.VoidedBy,
+			Share:          row.Share,
+		}
+		if row.GiftCardCode != nil {
+			item.GiftCardCode = *row.GiftCardCode
+		}
+		if row.VoidReason != nil {
+			item.VoidReason = *row.VoidReason
+		}
+		if row.ItemNote != nil {
+			item.Note = *row.ItemNote
 		}
 		order.Items = append(order.Items, item)
 	}
@@ -109,10 +207,38 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Or
 	return order, nil
 }
 
-func (r *orderRepository) List(ctx context.Context) ([]domain.Order, error) {
-	query := `SELECT id, order_number, status, subtotal, tax, total, created_at, updated_at FROM orders ORDER BY created_at DESC`
+func (r *orderRepository) List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, error) {
+	query := `SELECT id, order_number, reference, store_code, business_date, status, order_type, table_number, call_out_name, note, pickup_at, released_at,
+		tab_name, tab_opened_at, parent_order_id, merged_into_id, customer_id, subtotal, discount, manual_discount, tax, service_charge, total, redeemed_points, cashier_id, terminal_id, version, created_at, updated_at
+		FROM orders`
+	var conditions []string
+	var args []interface{}
+	if filter.CustomerID != nil {
+		args = append(args, *filter.CustomerID)
+		conditions = append(conditions, fmt.Sprintf("customer_id = $%d", len(args)))
+	}
+	if filter.OrderType != "" {
+		args = append(args, filter.OrderType)
+		conditions = append(conditions, fmt.Sprintf("order_type = $%d", len(args)))
+	}
+	if filter.OpenTabs {
+		args = append(args, domain.OrderStatusPending)
+		conditions = append(conditions, fmt.Sprintf("tab_opened_at IS NOT NULL AND status = $%d", len(args)))
+	}
+	if filter.Queue {
+		conditions = append(conditions, "(pickup_at IS NULL OR released_at IS NOT NULL)")
+	}
+	if filter.Held {
+		args = append(args, domain.OrderStatusPending, domain.OrderStatusPaid)
+		conditions = append(conditions, fmt.Sprintf("pickup_at IS NOT NULL AND released_at IS NULL AND status IN ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+
 	var orders []domain.Order
-	if err := r.db.SelectContext(ctx, &orders, query); err != nil {
+	if err := r.db.SelectContext(ctx, &orders, query, args...); err != nil {
 		return nil, err
 	}
 
@@ -137,27 +263,432 @@ func (r *orderRepository) List(ctx context.Context) ([]domain.Order, error) {
 	return orders, nil
 }
 
-func (r *orderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time) error {
-	query := `UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`
-	result, err := r.db.ExecContext(ctx, query, status, updatedAt, id)
+func (r *orderRepository) UpdateStatus(ctx context.Context, change *domain.OrderStatusChange, version int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`
+	result, err := tx.ExecContext(ctx, query, change.ToStatus, change.CreatedAt, change.OrderID, change.FromStatus, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, change.OrderID); err != nil {
+		return err
+	}
+
+	if err := insertStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+	if err := recordApproval(ctx, tx, change.Approval); err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, change.Audit); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkOrderUpdated tells a conditional update of order id that matched no
+// row apart: ErrConflict if the order exists, sql.ErrNoRows if it does not.
+func checkOrderUpdated(ctx context.Context, tx *sqlx.Tx, result sql.Result, id uuid.UUID) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, id); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrConflict
+	}
+	return sql.ErrNoRows
+}
+
+func (r *orderRepository) UpdateItems(ctx context.Context, order *domain.Order, version int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := bookSlot(ctx, tx, order.ID, order.SlotBooking); err != nil {
+		return err
+	}
+	query := `UPDATE orders SET subtotal = $1, discount = $2, tax = $3, service_charge = $4, total = $5, version = version + 1, updated_at = $6
+		WHERE id = $7 AND status = $8 AND version = $9`
+	result, err := tx.ExecContext(ctx, query, order.Subtotal, order.Discount, order.Tax, order.ServiceCharge, order.Total, order.UpdatedAt,
+		order.ID, domain.OrderStatusPending, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, order.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM order_items WHERE order_id = $1`, order.ID); err != nil {
+		return err
+	}
+	if err := insertOrderItems(ctx, tx, order.Items); err != nil {
+		return err
+	}
+	if err := recordApproval(ctx, tx, order.Approval); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	order.Version = version + 1
+	return nil
+}
+
+func (r *orderRepository) VoidItem(ctx context.Context, order *domain.Order, item *domain.OrderItem, void *domain.LineVoid, version int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET subtotal = $1, discount = $2, tax = $3, service_charge = $4, total = $5, version = version + 1, updated_at = $6
+		WHERE id = $7 AND status = $8 AND version = $9`
+	result, err := tx.ExecContext(ctx, query, order.Subtotal, order.Discount, order.Tax, order.ServiceCharge, order.Total, order.UpdatedAt,
+		order.ID, order.Status, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, order.ID); err != nil {
+		return err
+	}
 
+	query = `UPDATE order_items SET voided_at = $1, void_reason = $2, voided_by = $3
+		WHERE id = $4 AND order_id = $5 AND voided_at IS NULL`
+	result, err = tx.ExecContext(ctx, query, item.VoidedAt, item.VoidReason, item.VoidedBy, item.ID, order.ID)
+	if err != nil {
+		return err
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return domain.ErrConflict
+	}
+
+	for i := range void.Refunds {
+		refund := &void.Refunds[i]
+		if refund.GiftCardID != nil {
+			balance, _, err := lockGiftCard(ctx, tx, *refund.GiftCardID)
+			if err != nil {
+				return err
+			}
+			orderID := order.ID
+			credit := &domain.GiftCardTransaction{
+				ID:         uuid.New(),
+				GiftCardID: *refund.GiftCardID,
+				OrderID:    &orderID,
+				Type:       domain.GiftCardRefund,
+				Amount:     refund.Amount,
+				CreatedAt:  refund.CreatedAt,
+			}
+			if err := applyGiftCardTransaction(ctx, tx, credit, balance, nil); err != nil {
+				return err
+			}
+		}
+		query := `INSERT INTO refunds (id, order_id, payment_id, order_item_id, tender, amount, gift_card_id, reason, created_at)
+			VALUES (:id, :order_id, :payment_id, :order_item_id, :tender, :amount, :gift_card_id, :reason, :created_at)`
+		if _, err := tx.NamedExecContext(ctx, query, refund); err != nil {
+			return err
+		}
+	}
+	if void.Points != nil {
+		if err := insertLoyaltyEntry(ctx, tx, void.Points); err != nil {
+			return err
+		}
+	}
+	for i := range void.Stamps {
+		if err := insertStampEntry(ctx, tx, &void.Stamps[i]); err != nil {
+			return err
+		}
+	}
+	if err := recordApproval(ctx, tx, order.Approval); err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, order.Audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	order.Version = version + 1
+	return nil
+}
+
+func (r *orderRepository) OpenTab(ctx context.Context, order *domain.Order, version int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET tab_name = $1, tab_opened_at = $2, version = version + 1, updated_at = $3
+		WHERE id = $4 AND status = $5 AND version = $6 AND tab_opened_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, order.TabName, order.TabOpenedAt, order.UpdatedAt, order.ID, domain.OrderStatusPending, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, order.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	order.Version = version + 1
+	return nil
+}
+
+func (r *orderRepository) Split(ctx context.Context, change *domain.OrderStatusChange, version int64, children []domain.Order) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`
+	result, err := tx.ExecContext(ctx, query, change.ToStatus, change.CreatedAt, change.OrderID, domain.OrderStatusPending, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, change.OrderID); err != nil {
+		return err
+	}
+
+	if err := insertStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+	for i := range children {
+		if err := insertOrder(ctx, tx, &children[i]); err != nil {
+			return err
+		}
+		// The stamp cards spent on the free lines go to the order that took them.
+		moved := make(map[uuid.UUID]bool)
+		for _, item := range children[i].Items {
+			if item.StampProgramID == nil || moved[*item.StampProgramID] {
+				continue
+			}
+			moved[*item.StampProgramID] = true
+			query := `UPDATE stamp_ledger SET order_id = $1 WHERE order_id = $2 AND program_id = $3 AND type = 'redeem'`
+			if _, err := tx.ExecContext(ctx, query, children[i].ID, change.OrderID, *item.StampProgramID); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *orderRepository) Merge(ctx context.Context, target *domain.Order, version int64, sources []domain.Order, changes []domain.OrderStatusChange) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, source := range sources {
+		query := `UPDATE orders SET status = $1, merged_into_id = $2, version = version + 1, updated_at = $3
+			WHERE id = $4 AND status = $5 AND version = $6`
+		result, err := tx.ExecContext(ctx, query, domain.OrderStatusMerged, target.ID, changes[i].CreatedAt, source.ID, domain.OrderStatusPending, source.Version)
+		if err != nil {
+			return err
+		}
+		if err := checkOrderUpdated(ctx, tx, result, source.ID); err != nil {
+			return err
+		}
+		if err := insertStatusChange(ctx, tx, &changes[i]); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE order_items SET order_id = $1 WHERE order_id = $2`, target.ID, source.ID); err != nil {
+			return err
+		}
+		// The stamp cards spent on the source's free lines go with them.
+		if _, err := tx.ExecContext(ctx, `UPDATE stamp_ledger SET order_id = $1 WHERE order_id = $2 AND type = 'redeem'`, target.ID, source.ID); err != nil {
+			return err
+		}
+	}
+	// Checked once the sources are merged, so their lines count only once.
+	if err := bookSlot(ctx, tx, target.ID, target.SlotBooking); err != nil {
+		return err
+	}
+
+	query := `UPDATE orders SET customer_id = $1, note = $2, subtotal = $3, discount = $4, manual_discount = $5, tax = $6, service_charge = $7, total = $8,
+		version = version + 1, updated_at = $9
+		WHERE id = $10 AND status = $11 AND version = $12`
+	result, err := tx.ExecContext(ctx, query, target.CustomerID, target.Note, target.Subtotal, target.Discount, target.ManualDiscount, target.Tax, target.ServiceCharge, target.Total,
+		target.UpdatedAt, target.ID, domain.OrderStatusPending, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, target.ID); err != nil {
+		return err
+	}
+	if err := recordApproval(ctx, tx, target.Approval); err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, target.Audit); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	target.Version = version + 1
+	return nil
+}
+
+func (r *orderRepository) UpdateTable(ctx context.Context, order *domain.Order, version int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
+	query := `UPDATE orders SET table_number = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`
+	result, err := tx.ExecContext(ctx, query, order.TableNumber, order.UpdatedAt, order.ID, domain.OrderStatusPending, version)
+	if err != nil {
+		return err
+	}
+	if err := checkOrderUpdated(ctx, tx, result, order.ID); err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, order.Audit); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	order.Version = version + 1
 	return nil
 }
 
+// bookSlot checks that the order fits in its pickup slot next to the other
+// open pre-orders in it. The slot stays locked until tx ends, so two orders
+// cannot both take its last places.
+func bookSlot(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, booking *domain.SlotBooking) error {
+	if booking == nil {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('pickup_slot'), $1)`, int32(booking.From.Unix()/60)); err != nil {
+		return err
+	}
+
+	query := `SELECT COALESCE(SUM(oi.quantity * COALESCE(oi.share, 1)), 0)
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		JOIN menu_items m ON m.id = oi.menu_item_id
+		WHERE o.pickup_at >= $1 AND o.pickup_at < $2 AND o.status IN ($3, $4, $5) AND oi.voided_at IS NULL
+		AND o.id <> $6 AND LOWER(COALESCE(m.category, '')) <> LOWER($7)`
+	args := []interface{}{booking.From, booking.To, domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusCompleted, orderID, domain.GiftCardCategory}
+	if len(booking.Categories) > 0 {
+		lowered := make([]string, len(booking.Categories))
+		for i, category := range booking.Categories {
+			lowered[i] = strings.ToLower(category)
+		}
+		args = append(args, pq.Array(lowered))
+		query += " AND LOWER(COALESCE(m.category, '')) = ANY($8)"
+	}
+
+	var booked decimal.Decimal
+	if err := tx.GetContext(ctx, &booked, query, args...); err != nil {
+		return err
+	}
+	if booked.Add(booking.Units).GreaterThan(decimal.NewFromInt(int64(booking.Capacity))) {
+		return domain.ErrPickupSlotFull
+	}
+	return nil
+}
+
+func (r *orderRepository) ReleaseScheduled(ctx context.Context, before, now time.Time) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var due []struct {
+		ID     uuid.UUID `db:"id"`
+		Status string    `db:"status"`
+	}
+	query := `SELECT id, status FROM orders
+		WHERE pickup_at IS NOT NULL AND released_at IS NULL AND pickup_at <= $1 AND status IN ($2, $3)
+		ORDER BY pickup_at FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &due, query, before, domain.OrderStatusPending, domain.OrderStatusPaid); err != nil {
+		return 0, err
+	}
+
+	after, err := json.Marshal(map[string]time.Time{"released_at": now})
+	if err != nil {
+		return 0, err
+	}
+	for _, order := range due {
+		if _, err := tx.ExecContext(ctx, `UPDATE orders SET released_at = $1, version = version + 1, updated_at = $1 WHERE id = $2`, now, order.ID); err != nil {
+			return 0, err
+		}
+		// A release leaves the status as it is, so it shows in the history as
+		// a change from the status to itself.
+		status := order.Status
+		change := &domain.OrderStatusChange{ID: uuid.New(), OrderID: order.ID, FromStatus: &status, ToStatus: status,
+			Reason: "released for preparation", CreatedAt: now}
+		if err := insertStatusChange(ctx, tx, change); err != nil {
+			return 0, err
+		}
+		entry := &domain.AuditEntry{ID: uuid.New(), Action: domain.AuditOrderRelease, EntityType: domain.AuditEntityOrder,
+			EntityID: order.ID, After: after, CreatedAt: now}
+		if err := insertAuditEntry(ctx, tx, entry); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(due)), nil
+}
+
+func (r *orderRepository) StatusHistory(ctx context.Context, orderID uuid.UUID) ([]domain.OrderStatusChange, error) {
+	history := []domain.OrderStatusChange{}
+	query := `SELECT id, order_id, from_status, to_status, actor_id, api_key_id, reason, created_at
+		FROM order_status_history WHERE order_id = $1 ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &history, query, orderID); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func insertOrderItems(ctx context.Context, tx *sqlx.Tx, items []domain.OrderItem) error {
+	query := `INSERT INTO order_items (id, order_id, menu_item_id, quantity, unit_price, line_total, unit_cost, gift_card_code, stamp_program_id, share, note)
+		VALUES (:id, :order_id, :menu_item_id, :quantity, :unit_price, :line_total, :unit_cost, :gift_card_code, :stamp_program_id, :share, :note)`
+	for i := range items {
+		if _, err := tx.NamedExecContext(ctx, query, &items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertStatusChange(ctx context.Context, tx *sqlx.Tx, change *domain.OrderStatusChange) error {
+	query := `INSERT INTO order_status_history (id, order_id, from_status, to_status, actor_id, api_key_id, reason, created_at)
+		VALUES (:id, :order_id, :from_status, :to_status, :actor_id, :api_key_id, :reason, :created_at)`
+	_, err := tx.NamedExecContext(ctx, query, change)
+	return err
+}
+
 func (r *orderRepository) getOrderItems(ctx context.Context, orderIDs []uuid.UUID) (map[uuid.UUID][]domain.OrderItem, error) {
 	itemsByOrder := make(map[uuid.UUID][]domain.OrderItem)
-	query, args, err := sqlx.In(`SELECT id, order_id, menu_item_id, quantity, unit_price, line_total
+	query, args, err := sqlx.In(`SELECT id, order_id, menu_item_id, quantity, unit_price, line_total, unit_cost, gift_card_code, stamp_program_id,
+		voided_at, void_reason, voided_by, share, note
 		FROM order_items WHERE order_id IN (?) ORDER BY order_id, id`, orderIDs)
 	if err != nil {
 		return nil, err
@@ -11,6 +11,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,14 +26,19 @@ func TestOrderRepository_Create(t *testing.T) {
 
 	orderID := uuid.New()
 	order := &domain.Order{
-		ID:          orderID,
-		OrderNumber: "ORD-123",
-		Status:      domain.OrderStatusPending,
-		Subtotal:    decimal.NewFromFloat(10),
-		Tax:         decimal.NewFromFloat(1),
-		Total:       decimal.NewFromFloat(11),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:           orderID,
+		StoreCode:    "B",
+		BusinessDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Status:       domain.OrderStatusPending,
+		OrderType:    domain.OrderTypeDineIn,
+		TableNumber:  "12",
+		CallOutName:  "Sam",
+		Note:         "Birthday, bring a candle",
+		Subtotal:     decimal.NewFromFloat(10),
+		Tax:          decimal.NewFromFloat(1),
+		Total:        decimal.NewFromFloat(11),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 		Items: []domain.OrderItem{{
 			ID:         uuid.New(),
 			OrderID:    orderID,
@@ -40,26 +46,35 @@ func TestOrderRepository_Create(t *testing.T) {
 			Quantity:   2,
 			UnitPrice:  decimal.NewFromFloat(5),
 			LineTotal:  decimal.NewFromFloat(10),
+			UnitCost:   decimal.NewFromFloat(1.25),
+			Note:       "extra hot",
 		}},
 	}
 
 	mock.ExpectBegin()
-	orderQuery := `INSERT INTO orders (id, order_number, status, subtotal, tax, total, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO order_number_counters (store_code, business_date, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (store_code, business_date) DO UPDATE SET last_number = order_number_counters.last_number + 1
+		RETURNING last_number`)).
+		WithArgs("B", order.BusinessDate).
+		WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(42))
+	orderQuery := `INSERT INTO orders (id, order_number, reference, store_code, business_date, status, order_type, table_number, call_out_name, note, pickup_at, released_at, parent_order_id, customer_id, subtotal, discount, manual_discount, tax, service_charge, total, redeemed_points, cashier_id, terminal_id, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	mock.ExpectExec(regexp.QuoteMeta(orderQuery)).
-		WithArgs(order.ID, order.OrderNumber, order.Status, order.Subtotal, order.Tax, order.Total, order.CreatedAt, order.UpdatedAt).
+		WithArgs(order.ID, "B-042", "B-20261018-042", "B", order.BusinessDate, order.Status, domain.OrderTypeDineIn, "12", "Sam", order.Note, order.PickupAt, order.ReleasedAt, order.ParentOrderID, order.CustomerID, order.Subtotal, order.Discount, order.ManualDiscount, order.Tax, order.ServiceCharge, order.Total, order.RedeemedPoints, order.CashierID, order.TerminalID, order.Version, order.CreatedAt, order.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	itemQuery := `INSERT INTO order_items (id, order_id, menu_item_id, quantity, unit_price, line_total)
-		VALUES (?, ?, ?, ?, ?, ?)`
+	itemQuery := `INSERT INTO order_items (id, order_id, menu_item_id, quantity, unit_price, line_total, unit_cost, gift_card_code, stamp_program_id, share, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	item := order.Items[0]
 	mock.ExpectExec(regexp.QuoteMeta(itemQuery)).
-		WithArgs(item.ID, item.OrderID, item.MenuItemID, item.Quantity, item.UnitPrice, item.LineTotal).
+		WithArgs(item.ID, item.OrderID, item.MenuItemID, item.Quantity, item.UnitPrice, item.LineTotal, item.UnitCost, item.GiftCardCode, item.StampProgramID, item.Share, item.Note).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	err = repo.Create(context.Background(), order)
 	assert.NoError(t, err)
+	assert.Equal(t, "B-042", order.OrderNumber)
+	assert.Equal(t, "B-20261018-042", order.Reference)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -72,10 +87,13 @@ func TestOrderRepository_GetByID(t *testing.T) {
 	repo := NewOrderRepository(sqlxDB)
 	orderID := uuid.New()
 
-	joinRows := sqlmock.NewRows([]string{"id", "order_number", "status", "subtotal", "tax", "total", "created_at", "updated_at", "item_id", "order_id", "menu_item_id", "quantity", "unit_price", "line_total"}).
-		AddRow(orderID, "ORD-1", domain.OrderStatusPending, decimal.NewFromFloat(10), decimal.NewFromFloat(1), decimal.NewFromFloat(11), time.Now(), time.Now(), uuid.New(), orderID, uuid.New(), 2, decimal.NewFromFloat(5), decimal.NewFromFloat(10))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT o.id, o.order_number, o.status, o.subtotal, o.tax, o.total, o.created_at, o.updated_at,
-		oi.id AS item_id, oi.order_id, oi.menu_item_id, oi.quantity, oi.unit_price, oi.line_total
+	joinRows := sqlmock.NewRows([]string{"id", "order_number", "reference", "store_code", "business_date", "status", "order_type", "table_number", "call_out_name", "note", "pickup_at", "released_at", "tab_name", "tab_opened_at", "parent_order_id", "merged_into_id", "customer_id", "subtotal", "discount", "manual_discount", "tax", "service_charge", "total", "redeemed_points", "cashier_id", "terminal_id", "version", "created_at", "updated_at", "item_id", "order_id", "menu_item_id", "quantity", "unit_price", "line_total", "unit_cost", "gift_card_code", "stamp_program_id", "voided_at", "void_reason", "voided_by", "share", "item_note"}).
+		AddRow(orderID, "A-001", "A-20261018-001", "A", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), domain.OrderStatusPending, domain.OrderTypeDineIn, "12", "", "No nuts", nil, nil, "", nil, nil, nil, nil, decimal.NewFromFloat(10), decimal.Zero, decimal.Zero, decimal.NewFromFloat(1), decimal.Zero, decimal.NewFromFloat(11), 0, nil, nil, 3, time.Now(), time.Now(), uuid.New(), orderID, uuid.New(), 2, decimal.NewFromFloat(5), decimal.NewFromFloat(10), decimal.NewFromFloat(1.25), nil, nil, nil, nil, nil, nil, "no lid")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT o.id, o.order_number, o.reference, o.store_code, o.business_date, o.status, o.order_type, o.table_number, o.call_out_name, o.note, o.pickup_at, o.released_at,
+		o.tab_name, o.tab_opened_at, o.parent_order_id, o.merged_into_id, o.customer_id, o.subtotal, o.discount, o.manual_discount, o.tax, o.service_charge, o.total, o.redeemed_points,
+		o.cashier_id, o.terminal_id, o.version, o.created_at, o.updated_at,
+		oi.id AS item_id, oi.order_id, oi.menu_item_id, oi.quantity, oi.unit_price, oi.line_total, oi.unit_cost,
+		oi.gift_card_code, oi.stamp_program_id, oi.voided_at, oi.void_reason, oi.voided_by, oi.share, oi.note AS item_note
 		FROM orders o
 		LEFT JOIN order_items oi ON oi.order_id = o.id
 		WHERE o.id = $1
@@ -87,6 +105,12 @@ func TestOrderRepository_GetByID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, order)
 	assert.Len(t, order.Items, 1)
+	assert.Equal(t, int64(3), order.Version)
+	assert.Equal(t, "A-20261018-001", order.Reference)
+	assert.Equal(t, domain.OrderTypeDineIn, order.OrderType)
+	assert.Equal(t, "12", order.TableNumber)
+	assert.Equal(t, "No nuts", order.Note)
+	assert.Equal(t, "no lid", order.Items[0].Note)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -99,21 +123,47 @@ func TestOrderRepository_List(t *testing.T) {
 	repo := NewOrderRepository(sqlxDB)
 	orderID := uuid.New()
 
-	rows := sqlmock.NewRows([]string{"id", "order_number", "status", "subtotal", "tax", "total", "created_at", "updated_at"}).
-		AddRow(orderID, "ORD-1", domain.OrderStatusPending, decimal.NewFromFloat(10), decimal.NewFromFloat(1), decimal.NewFromFloat(11), time.Now(), time.Now())
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, order_number, status, subtotal, tax, total, created_at, updated_at FROM orders ORDER BY created_at DESC`)).WillReturnRows(rows)
+	rows := sqlmock.NewRows([]string{"id", "order_number", "reference", "store_code", "business_date", "status", "order_type", "table_number", "call_out_name", "note", "pickup_at", "released_at", "tab_name", "tab_opened_at", "parent_order_id", "merged_into_id", "customer_id", "subtotal", "discount", "manual_discount", "tax", "service_charge", "total", "redeemed_points", "cashier_id", "terminal_id", "version", "created_at", "updated_at"}).
+		AddRow(orderID, "A-001", "A-20261018-001", "A", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), domain.OrderStatusPending, domain.OrderTypeDelivery, "", "", "", nil, nil, "Jo", time.Now(), nil, nil, nil, decimal.NewFromFloat(10), decimal.Zero, decimal.Zero, decimal.NewFromFloat(1), decimal.Zero, decimal.NewFromFloat(11), 0, nil, nil, 3, time.Now(), time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, order_number, reference, store_code, business_date, status, order_type, table_number, call_out_name, note, pickup_at, released_at,
+		tab_name, tab_opened_at, parent_order_id, merged_into_id, customer_id, subtotal, discount, manual_discount, tax, service_charge, total, redeemed_points, cashier_id, terminal_id, version, created_at, updated_at
+		FROM orders WHERE order_type = $1 AND tab_opened_at IS NOT NULL AND status = $2 ORDER BY created_at DESC`)).
+		WithArgs(domain.OrderTypeDelivery, domain.OrderStatusPending).
+		WillReturnRows(rows)
 
-	itemRows := sqlmock.NewRows([]string{"id", "order_id", "menu_item_id", "quantity", "unit_price", "line_total"}).
-		AddRow(uuid.New(), orderID, uuid.New(), 1, decimal.NewFromFloat(10), decimal.NewFromFloat(10))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, order_id, menu_item_id, quantity, unit_price, line_total
+	itemRows := sqlmock.NewRows([]string{"id", "order_id", "menu_item_id", "quantity", "unit_price", "line_total", "unit_cost", "gift_card_code", "stamp_program_id", "voided_at", "void_reason", "voided_by", "share", "note"}).
+		AddRow(uuid.New(), orderID, uuid.New(), 1, decimal.NewFromFloat(10), decimal.NewFromFloat(10), decimal.NewFromFloat(2), "", nil, nil, "", nil, "0.5", "")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, order_id, menu_item_id, quantity, unit_price, line_total, unit_cost, gift_card_code, stamp_program_id,
+		voided_at, void_reason, voided_by, share, note
 		FROM order_items WHERE order_id IN (?) ORDER BY order_id, id`)).
 		WithArgs(orderID).
 		WillReturnRows(itemRows)
 
-	orders, err := repo.List(context.Background())
+	orders, err := repo.List(context.Background(), domain.OrderFilter{OrderType: domain.OrderTypeDelivery, OpenTabs: true})
 	assert.NoError(t, err)
 	assert.Len(t, orders, 1)
 	assert.Len(t, orders[0].Items, 1)
+	assert.Equal(t, "Jo", orders[0].TabName)
+	assert.Equal(t, "0.5", orders[0].Items[0].Share.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_List_ByCustomer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	customerID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM orders WHERE customer_id = $1 ORDER BY created_at DESC`)).
+		WithArgs(customerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	orders, err := repo.List(context.Background(), domain.OrderFilter{CustomerID: &customerID})
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -125,11 +175,528 @@ func TestOrderRepository_UpdateStatus_NotFound(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	repo := NewOrderRepository(sqlxDB)
 	id := uuid.New()
+	from := domain.OrderStatusPending
 
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`)).
-		WithArgs(domain.OrderStatusPaid, sqlmock.AnyArg(), id).
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`)).
+		WithArgs(domain.OrderStatusPaid, sqlmock.AnyArg(), id, from, int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
 
-	err = repo.UpdateStatus(context.Background(), id, domain.OrderStatusPaid, time.Now())
+	err = repo.UpdateStatus(context.Background(), &domain.OrderStatusChange{ID: uuid.New(), OrderID: id, FromStatus: &from, ToStatus: domain.OrderStatusPaid, CreatedAt: time.Now()}, 1)
 	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateStatus_Conflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	id := uuid.New()
+	from := domain.OrderStatusPending
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`)).
+		WithArgs(domain.OrderStatusCancelled, sqlmock.AnyArg(), id, from, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = repo.UpdateStatus(context.Background(), &domain.OrderStatusChange{ID: uuid.New(), OrderID: id, FromStatus: &from, ToStatus: domain.OrderStatusCancelled, CreatedAt: time.Now()}, 1)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateStatus_TokenUsedMeanwhile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	from := domain.OrderStatusPaid
+	tokenID := uuid.New()
+	change := &domain.OrderStatusChange{ID: uuid.New(), OrderID: uuid.New(), FromStatus: &from, ToStatus: domain.OrderStatusCancelled, CreatedAt: time.Now()}
+	change.Approval = &domain.Approval{ID: uuid.New(), Action: domain.OverrideCancelPaidOrder, EntityID: change.OrderID,
+		ApproverID: uuid.New(), Method: domain.ApprovalMethodToken, CreatedAt: change.CreatedAt, TokenID: &tokenID}
+
+	// The token goes in the same transaction as the change, so losing it to
+	// another request rolls the cancellation back.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE approval_tokens SET used_at = $1`)).
+		WithArgs(change.CreatedAt, tokenID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.UpdateStatus(context.Background(), change, 1), domain.ErrInvalidOverride)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateStatus_RecordsHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	from := domain.OrderStatusPending
+	actorID := uuid.New()
+	change := &domain.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    uuid.New(),
+		FromStatus: &from,
+		ToStatus:   domain.OrderStatusCancelled,
+		ActorID:    &actorID,
+		Reason:     "customer left",
+		CreatedAt:  time.Now(),
+	}
+	change.Audit = &domain.AuditEntry{ID: uuid.New(), ActorID: &actorID, Action: domain.AuditOrderStatusChange,
+		EntityType: domain.AuditEntityOrder, EntityID: change.OrderID, CreatedAt: change.CreatedAt}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`)).
+		WithArgs(domain.OrderStatusCancelled, change.CreatedAt, change.OrderID, change.FromStatus, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history (id, order_id, from_status, to_status, actor_id, api_key_id, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)).
+		WithArgs(change.ID, change.OrderID, change.FromStatus, change.ToStatus, change.ActorID, change.APIKeyID, change.Reason, change.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log`)).
+		WithArgs(change.Audit.ID, &actorID, nil, "", domain.AuditOrderStatusChange, domain.AuditEntityOrder, change.OrderID,
+			nil, nil, "", change.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdateStatus(context.Background(), change, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_StatusHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	orderID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, order_id, from_status, to_status, actor_id, api_key_id, reason, created_at
+		FROM order_status_history WHERE order_id = $1 ORDER BY created_at, id`)).
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "from_status", "to_status", "actor_id", "api_key_id", "reason", "created_at"}).
+			AddRow(uuid.New(), orderID, nil, domain.OrderStatusPending, nil, nil, "", time.Now()).
+			AddRow(uuid.New(), orderID, domain.OrderStatusPending, domain.OrderStatusPaid, nil, nil, "", time.Now()))
+
+	history, err := repo.StatusHistory(context.Background(), orderID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Nil(t, history[0].FromStatus)
+	assert.Equal(t, domain.OrderStatusPending, *history[1].FromStatus)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	order := &domain.Order{
+		ID:       uuid.New(),
+		Subtotal: decimal.NewFromFloat(8),
+		Tax:      decimal.NewFromFloat(0.8),
+		Total:    decimal.NewFromFloat(8.8),
+		Version:  3,
+	}
+	order.Items = []domain.OrderItem{{ID: uuid.New(), OrderID: order.ID, MenuItemID: uuid.New(), Quantity: 2,
+		UnitPrice: decimal.NewFromFloat(4), LineTotal: decimal.NewFromFloat(8)}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET subtotal = $1, discount = $2, tax = $3, service_charge = $4, total = $5, version = version + 1, updated_at = $6
+		WHERE id = $7 AND status = $8 AND version = $9`)).
+		WithArgs(order.Subtotal, order.Discount, order.Tax, order.ServiceCharge, order.Total, sqlmock.AnyArg(), order.ID, domain.OrderStatusPending, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM order_items WHERE order_id = $1`)).
+		WithArgs(order.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_items`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdateItems(context.Background(), order, 3))
+	assert.Equal(t, int64(4), order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateItems_NoLongerPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	order := &domain.Order{ID: uuid.New(), Version: 3}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET subtotal`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`)).
+		WithArgs(order.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.UpdateItems(context.Background(), order, 3), domain.ErrConflict)
+	assert.Equal(t, int64(3), order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_OpenTab(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	openedAt := time.Now()
+	order := &domain.Order{ID: uuid.New(), TabName: "Jo", TabOpenedAt: &openedAt, UpdatedAt: openedAt, Version: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET tab_name = $1, tab_opened_at = $2, version = version + 1, updated_at = $3
+		WHERE id = $4 AND status = $5 AND version = $6 AND tab_opened_at IS NULL`)).
+		WithArgs("Jo", &openedAt, openedAt, order.ID, domain.OrderStatusPending, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.OpenTab(context.Background(), order, 2))
+	assert.Equal(t, int64(3), order.Version)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET tab_name`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`)).
+		WithArgs(order.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.OpenTab(context.Background(), order, 2), domain.ErrConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_Split(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	parentID, childID := uuid.New(), uuid.New()
+	from := domain.OrderStatusPending
+	change := &domain.OrderStatusChange{ID: uuid.New(), OrderID: parentID, FromStatus: &from, ToStatus: domain.OrderStatusSplit, CreatedAt: time.Now()}
+	share := decimal.NewFromFloat(0.5)
+	programID := uuid.New()
+	child := domain.Order{
+		ID:            childID,
+		StoreCode:     "A",
+		BusinessDate:  time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Status:        domain.OrderStatusPending,
+		ParentOrderID: &parentID,
+		Items: []domain.OrderItem{
+			{ID: uuid.New(), OrderID: childID, MenuItemID: uuid.New(), Quantity: 1, Share: &share},
+			{ID: uuid.New(), OrderID: childID, MenuItemID: uuid.New(), Quantity: 1, StampProgramID: &programID},
+		},
+		StatusHistory: []domain.OrderStatusChange{{ID: uuid.New(), OrderID: childID, ToStatus: domain.OrderStatusPending}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`)).
+		WithArgs(domain.OrderStatusSplit, change.CreatedAt, parentID, domain.OrderStatusPending, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO order_number_counters`)).
+		WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(7))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_items`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_items`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stamp_ledger SET order_id = $1 WHERE order_id = $2 AND program_id = $3 AND type = 'redeem'`)).
+		WithArgs(childID, parentID, programID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	children := []domain.Order{child}
+	assert.NoError(t, repo.Split(context.Background(), change, 4, children))
+	assert.Equal(t, "A-007", children[0].OrderNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_Merge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	now := time.Now()
+	target := &domain.Order{ID: uuid.New(), Subtotal: decimal.NewFromFloat(12), Tax: decimal.NewFromFloat(1.2), Total: decimal.NewFromFloat(13.2), UpdatedAt: now}
+	source := domain.Order{ID: uuid.New(), Version: 3, MergedIntoID: &target.ID}
+	from := domain.OrderStatusPending
+	changes := []domain.OrderStatusChange{{ID: uuid.New(), OrderID: source.ID, FromStatus: &from, ToStatus: domain.OrderStatusMerged, CreatedAt: now}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, merged_into_id = $2`)).
+		WithArgs(domain.OrderStatusMerged, target.ID, now, source.ID, domain.OrderStatusPending, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_items SET order_id = $1 WHERE order_id = $2`)).
+		WithArgs(target.ID, source.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stamp_ledger SET order_id = $1 WHERE order_id = $2 AND type = 'redeem'`)).
+		WithArgs(target.ID, source.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET customer_id = $1`)).
+		WithArgs(target.CustomerID, target.Note, target.Subtotal, target.Discount, target.ManualDiscount, target.Tax, target.ServiceCharge, target.Total,
+			now, target.ID, domain.OrderStatusPending, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Merge(context.Background(), target, 5, []domain.Order{source}, changes))
+	assert.Equal(t, int64(6), target.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_Merge_SourceChanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	target := &domain.Order{ID: uuid.New()}
+	source := domain.Order{ID: uuid.New(), Version: 3}
+	changes := []domain.OrderStatusChange{{ID: uuid.New(), OrderID: source.ID, ToStatus: domain.OrderStatusMerged, CreatedAt: time.Now()}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, merged_into_id = $2`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WithArgs(source.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = repo.Merge(context.Background(), target, 5, []domain.Order{source}, changes)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_Merge_PickupSlotFull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	now := time.Now()
+	from := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	target := &domain.Order{ID: uuid.New(), UpdatedAt: now, SlotBooking: &domain.SlotBooking{
+		From: from, To: from.Add(5 * time.Minute), Units: decimal.NewFromInt(5), Capacity: 10,
+	}}
+	source := domain.Order{ID: uuid.New(), Version: 3}
+	changes := []domain.OrderStatusChange{{ID: uuid.New(), OrderID: source.ID, ToStatus: domain.OrderStatusMerged, CreatedAt: now}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET status = $1, merged_into_id = $2`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_items SET order_id = $1 WHERE order_id = $2`)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE stamp_ledger SET order_id = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// The slot is checked after the source is merged, so its lines are not
+	// counted twice.
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext('pickup_slot'), $1)`)).
+		WithArgs(int32(from.Unix() / 60)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(oi.quantity * COALESCE(oi.share, 1)), 0)`)).
+		WithArgs(from, from.Add(5*time.Minute), domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusCompleted,
+			target.ID, domain.GiftCardCategory).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("6"))
+	mock.ExpectRollback()
+
+	err = repo.Merge(context.Background(), target, 5, []domain.Order{source}, changes)
+	assert.ErrorIs(t, err, domain.ErrPickupSlotFull)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_UpdateTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	order := &domain.Order{ID: uuid.New(), TableNumber: "7", UpdatedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET table_number = $1, version = version + 1, updated_at = $2 WHERE id = $3 AND status = $4 AND version = $5`)).
+		WithArgs("7", order.UpdatedAt, order.ID, domain.OrderStatusPending, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdateTable(context.Background(), order, 2))
+	assert.Equal(t, int64(3), order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_Create_PickupSlotFull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	from := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	order := &domain.Order{ID: uuid.New(), SlotBooking: &domain.SlotBooking{
+		From: from, To: from.Add(5 * time.Minute), Units: decimal.NewFromInt(3), Capacity: 10, Categories: []string{"Coffee", "Tea"},
+	}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext('pickup_slot'), $1)`)).
+		WithArgs(int32(from.Unix() / 60)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(oi.quantity * COALESCE(oi.share, 1)), 0)`)).
+		WithArgs(from, from.Add(5*time.Minute), domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusCompleted,
+			order.ID, domain.GiftCardCategory, pq.Array([]string{"coffee", "tea"})).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("7.5"))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Create(context.Background(), order), domain.ErrPickupSlotFull)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_ReleaseScheduled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	now := time.Now()
+	before := now.Add(15 * time.Minute)
+	orderID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, status FROM orders
+		WHERE pickup_at IS NOT NULL AND released_at IS NULL AND pickup_at <= $1 AND status IN ($2, $3)
+		ORDER BY pickup_at FOR UPDATE SKIP LOCKED`)).
+		WithArgs(before, domain.OrderStatusPending, domain.OrderStatusPaid).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(orderID, domain.OrderStatusPaid))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET released_at = $1, version = version + 1, updated_at = $1 WHERE id = $2`)).
+		WithArgs(now, orderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_status_history`)).
+		WithArgs(sqlmock.AnyArg(), orderID, domain.OrderStatusPaid, domain.OrderStatusPaid, nil, nil, "released for preparation", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log`)).
+		WithArgs(sqlmock.AnyArg(), nil, nil, "", domain.AuditOrderRelease, domain.AuditEntityOrder, orderID,
+			nil, sqlmock.AnyArg(), "", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	released, err := repo.ReleaseScheduled(context.Background(), before, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), released)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_List_Held(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM orders WHERE pickup_at IS NOT NULL AND released_at IS NULL AND status IN ($1, $2) ORDER BY created_at DESC`)).
+		WithArgs(domain.OrderStatusPending, domain.OrderStatusPaid).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM orders WHERE (pickup_at IS NULL OR released_at IS NOT NULL) ORDER BY created_at DESC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	orders, err := repo.List(context.Background(), domain.OrderFilter{Held: true})
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+	_, err = repo.List(context.Background(), domain.OrderFilter{Queue: true})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_VoidItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOrderRepository(sqlxDB)
+	now := time.Now()
+	cardID := uuid.New()
+	order := &domain.Order{ID: uuid.New(), Status: domain.OrderStatusPaid, Subtotal: decimal.NewFromFloat(6), Tax: decimal.NewFromFloat(0.6), Total: decimal.NewFromFloat(6.6), UpdatedAt: now}
+	item := &domain.OrderItem{ID: uuid.New(), OrderID: order.ID, VoidedAt: &now, VoidReason: "wrong milk"}
+	refunds := []domain.Refund{{ID: uuid.New(), OrderID: order.ID, PaymentID: uuid.New(), OrderItemID: &item.ID, Tender: domain.TenderGiftCard,
+		Amount: decimal.NewFromFloat(4.4), GiftCardID: &cardID, Reason: "wrong milk", CreatedAt: now}}
+	customerID := uuid.New()
+	void := &domain.LineVoid{
+		Refunds: refunds,
+		Points:  &domain.LoyaltyEntry{ID: uuid.New(), CustomerID: customerID, OrderID: &order.ID, OrderItemID: &item.ID, Type: domain.LoyaltyEntryVoid, Points: -4, CreatedAt: now},
+		Stamps:  []domain.StampEntry{{ID: uuid.New(), ProgramID: uuid.New(), CustomerID: customerID, OrderID: &order.ID, OrderItemID: &item.ID, Type: domain.StampEntryVoid, Stamps: -1, CreatedAt: now}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET subtotal = $1`)).
+		WithArgs(order.Subtotal, order.Discount, order.Tax, order.ServiceCharge, order.Total, now, order.ID, domain.OrderStatusPaid, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_items SET voided_at = $1, void_reason = $2, voided_by = $3
+		WHERE id = $4 AND order_id = $5 AND voided_at IS NULL`)).
+		WithArgs(item.VoidedAt, "wrong milk", item.VoidedBy, item.ID, order.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, expires_at FROM gift_cards WHERE id = $1 FOR UPDATE`)).
+		WithArgs(cardID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "expires_at"}).AddRow(decimal.NewFromInt(2), nil))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE gift_cards SET balance = $1`)).
+		WithArgs(decimal.NewFromFloat(6.4), nil, now, cardID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gift_card_transactions`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO refunds`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO loyalty_ledger`)).
+		WithArgs(void.Points.ID, customerID, &order.ID, &item.ID, domain.LoyaltyEntryVoid, int64(-4), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stamp_ledger`)).
+		WithArgs(void.Stamps[0].ID, void.Stamps[0].ProgramID, customerID, &order.ID, &item.ID, domain.StampEntryVoid, -1, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.VoidItem(context.Background(), order, item, void, 5))
+	assert.Equal(t, int64(6), order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type overrideRepository struct {
+	db *sqlx.DB
+}
+
+func NewOverrideRepository(db *sqlx.DB) domain.OverrideRepository {
+	return &overrideRepository{db: db}
+}
+
+func (r *overrideRepository) CreateToken(ctx context.Context, token *domain.ApprovalToken) error {
+	query := `INSERT INTO approval_tokens (id, token_hash, manager_id, action, entity_id, expires_at, created_at)
+		VALUES (:id, :token_hash, :manager_id, :action, :entity_id, :expires_at, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, query, token)
+	return err
+}
+
+func (r *overrideRepository) FindToken(ctx context.Context, tokenHash, action string, entityID uuid.UUID, at time.Time) (*domain.ApprovalToken, error) {
+	query := `SELECT id, token_hash, manager_id, action, entity_id, expires_at, used_at, created_at FROM approval_tokens
+		WHERE token_hash = $2 AND action = $3 AND (entity_id IS NULL OR entity_id = $4)
+			AND used_at IS NULL AND expires_at > $1`
+	var token domain.ApprovalToken
+	if err := r.db.GetContext(ctx, &token, query, at, tokenHash, action, entityID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *overrideRepository) Record(ctx context.Context, approval *domain.Approval) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordApproval(ctx, tx, approval); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordApproval stores approval in tx, so it only exists if the action it
+// approves is stored too. The token it was given with is used up by a single
+// conditional update, so a token can only ever be used once.
+func recordApproval(ctx context.Context, tx *sqlx.Tx, approval *domain.Approval) error {
+	if approval == nil {
+		return nil
+	}
+	if approval.TokenID != nil {
+		result, err := tx.ExecContext(ctx, `UPDATE approval_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND expires_at > $1`,
+			approval.CreatedAt, *approval.TokenID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return domain.ErrInvalidOverride
+		}
+	}
+	query := `INSERT INTO approvals (id, action, entity_id, actor_id, approver_id, method, created_at)
+		VALUES (:id, :action, :entity_id, :actor_id, :approver_id, :method, :created_at)`
+	_, err := tx.NamedExecContext(ctx, query, approval)
+	return err
+}
+
+func (r *overrideRepository) List(ctx context.Context, filter domain.ApprovalFilter) ([]domain.Approval, error) {
+	query := `SELECT id, action, entity_id, actor_id, approver_id, method, created_at FROM approvals`
+	var conditions []string
+	var args []interface{}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.EntityID != nil {
+		args = append(args, *filter.EntityID)
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+
+	approvals := []domain.Approval{}
+	if err := r.db.SelectContext(ctx, &approvals, query, args...); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
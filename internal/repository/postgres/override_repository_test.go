@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverrideRepository_FindToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOverrideRepository(sqlxDB)
+	entityID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM approval_tokens`)).
+		WithArgs(now, "hash", domain.OverrideCancelPaidOrder, entityID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_hash", "manager_id", "action", "entity_id", "expires_at", "used_at", "created_at"}).
+			AddRow(uuid.New(), "hash", uuid.New(), domain.OverrideCancelPaidOrder, entityID, now.Add(time.Minute), nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM approval_tokens`)).
+		WithArgs(now, "hash", domain.OverrideCancelPaidOrder, entityID).
+		WillReturnError(sql.ErrNoRows)
+
+	token, err := repo.FindToken(context.Background(), "hash", domain.OverrideCancelPaidOrder, entityID, now)
+	assert.NoError(t, err)
+	assert.NotNil(t, token)
+
+	// Already used.
+	token, err = repo.FindToken(context.Background(), "hash", domain.OverrideCancelPaidOrder, entityID, now)
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOverrideRepository_Record_UsesToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOverrideRepository(sqlxDB)
+	tokenID := uuid.New()
+	approval := &domain.Approval{ID: uuid.New(), Action: domain.OverrideVoidLine, EntityID: uuid.New(), ApproverID: uuid.New(),
+		Method: domain.ApprovalMethodToken, CreatedAt: time.Now(), TokenID: &tokenID}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE approval_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`)).
+		WithArgs(approval.CreatedAt, tokenID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO approvals`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Record(context.Background(), approval))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOverrideRepository_Record_TokenAlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOverrideRepository(sqlxDB)
+	tokenID := uuid.New()
+	approval := &domain.Approval{ID: uuid.New(), Method: domain.ApprovalMethodToken, CreatedAt: time.Now(), TokenID: &tokenID}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE approval_tokens SET used_at = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Record(context.Background(), approval), domain.ErrInvalidOverride)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOverrideRepository_List_ByEntity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewOverrideRepository(sqlxDB)
+	entityID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM approvals WHERE entity_id = $1 ORDER BY created_at DESC`)).
+		WithArgs(entityID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "action", "entity_id", "actor_id", "approver_id", "method", "created_at"}).
+			AddRow(uuid.New(), domain.OverrideLargeDiscount, entityID, nil, uuid.New(), domain.ApprovalMethodPIN, time.Now()))
+
+	approvals, err := repo.List(context.Background(), domain.ApprovalFilter{EntityID: &entityID})
+	assert.NoError(t, err)
+	assert.Len(t, approvals, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
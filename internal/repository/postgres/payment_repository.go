@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+type paymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) domain.PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// Create locks the order so concurrent payments cannot together exceed its
+// total.
+func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var order struct {
+		Status string          `db:"status"`
+		Total  decimal.Decimal `db:"total"`
+	}
+	if err := tx.GetContext(ctx, &order, `SELECT status, total FROM orders WHERE id = $1 FOR UPDATE`, payment.OrderID); err != nil {
+		return err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return domain.ErrOrderNotPayable
+	}
+
+	var paid decimal.Decimal
+	if err := tx.GetContext(ctx, &paid, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = $1`, payment.OrderID); err != nil {
+		return err
+	}
+	if payment.Amount.GreaterThan(order.Total.Sub(paid)) {
+		return domain.ErrPaymentExceedsDue
+	}
+
+	if payment.GiftCardID != nil {
+		orderID := payment.OrderID
+		err := redeemGiftCard(ctx, tx, &domain.GiftCardTransaction{
+			ID:         uuid.New(),
+			GiftCardID: *payment.GiftCardID,
+			OrderID:    &orderID,
+			Type:       domain.GiftCardRedeem,
+			Amount:     payment.Amount.Neg(),
+			CreatedAt:  payment.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO payments (id, order_id, tender, amount, gift_card_id, created_at)
+		VALUES (:id, :order_id, :tender, :amount, :gift_card_id, :created_at)`
+	if _, err := tx.NamedExecContext(ctx, query, payment); err != nil {
+		return err
+	}
+	// Edits, splits and merges are refused once an order has payments. They
+	// write conditionally on the version, so a new version stops one that
+	// checked for payments before this one landed.
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET version = version + 1, updated_at = $1 WHERE id = $2`, payment.CreatedAt, payment.OrderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *paymentRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error) {
+	payments := []domain.Payment{}
+	query := `SELECT id, order_id, tender, amount, gift_card_id, created_at FROM payments
+		WHERE order_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &payments, query, orderID); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) ListRefunds(ctx context.Context, orderID uuid.UUID) ([]domain.Refund, error) {
+	refunds := []domain.Refund{}
+	query := `SELECT id, order_id, payment_id, order_item_id, tender, amount, gift_card_id, reason, created_at FROM refunds
+		WHERE order_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &refunds, query, orderID); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
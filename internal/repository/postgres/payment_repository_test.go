@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentRepository_Create_GiftCard(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewPaymentRepository(sqlxDB)
+	cardID := uuid.New()
+	payment := &domain.Payment{
+		ID:         uuid.New(),
+		OrderID:    uuid.New(),
+		Tender:     domain.TenderGiftCard,
+		Amount:     decimal.NewFromInt(4),
+		GiftCardID: &cardID,
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status, total FROM orders WHERE id = $1 FOR UPDATE`)).
+		WithArgs(payment.OrderID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "total"}).AddRow(domain.OrderStatusPending, decimal.NewFromInt(10)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = $1`)).
+		WithArgs(payment.OrderID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(decimal.NewFromInt(5)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, expires_at FROM gift_cards WHERE id = $1 FOR UPDATE`)).
+		WithArgs(cardID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "expires_at"}).AddRow(decimal.NewFromInt(20), time.Now().Add(time.Hour)))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE gift_cards SET balance = $1`)).
+		WithArgs(decimal.NewFromInt(16), nil, payment.CreatedAt, cardID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gift_card_transactions`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO payments (id, order_id, tender, amount, gift_card_id, created_at)`)).
+		WithArgs(payment.ID, payment.OrderID, payment.Tender, payment.Amount, payment.GiftCardID, payment.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE orders SET version = version + 1, updated_at = $1 WHERE id = $2`)).
+		WithArgs(payment.CreatedAt, payment.OrderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Create(context.Background(), payment)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_Create_ExceedsDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewPaymentRepository(sqlxDB)
+	payment := &domain.Payment{ID: uuid.New(), OrderID: uuid.New(), Tender: domain.TenderCash, Amount: decimal.NewFromInt(6)}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status, total FROM orders WHERE id = $1 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "total"}).AddRow(domain.OrderStatusPending, decimal.NewFromInt(10)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(decimal.NewFromInt(5)))
+	mock.ExpectRollback()
+
+	err = repo.Create(context.Background(), payment)
+	assert.ErrorIs(t, err, domain.ErrPaymentExceedsDue)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_Create_ExpiredGiftCard(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewPaymentRepository(sqlxDB)
+	cardID := uuid.New()
+	payment := &domain.Payment{ID: uuid.New(), OrderID: uuid.New(), Tender: domain.TenderGiftCard, Amount: decimal.NewFromInt(2), GiftCardID: &cardID, CreatedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status, total FROM orders WHERE id = $1 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "total"}).AddRow(domain.OrderStatusPending, decimal.NewFromInt(10)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(decimal.Zero))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, expires_at FROM gift_cards WHERE id = $1 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "expires_at"}).AddRow(decimal.NewFromInt(20), time.Now().Add(-time.Hour)))
+	mock.ExpectRollback()
+
+	err = repo.Create(context.Background(), payment)
+	assert.ErrorIs(t, err, domain.ErrGiftCardExpired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type reportRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportRepository(db *sqlx.DB) domain.ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) MenuItemCosts(ctx context.Context) ([]domain.MenuItemCost, error) {
+	query := `SELECT m.id AS menu_item_id, m.name, COALESCE(m.category, '') AS category, m.price,
+		COALESCE(SUM(ri.quantity * i.unit_cost), 0) AS cost
+		FROM menu_items m
+		LEFT JOIN recipe_items ri ON ri.menu_item_id = m.id
+		LEFT JOIN ingredients i ON i.id = ri.ingredient_id
+		GROUP BY m.id, m.name, m.category, m.price
+		ORDER BY m.category, m.name`
+
+	var costs []domain.MenuItemCost
+	if err := r.db.SelectContext(ctx, &costs, query); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// ItemSales sums paid and completed order items sold in [from, to), by the
+// time the order was paid as in GetStockLevels. Each order's discount is
+// spread over its lines in proportion to their totals, so revenue is what was
+// actually taken. Gift card lines are stored value rather than sales and are
+// left out.
+func (r *reportRepository) ItemSales(ctx context.Context, from, to time.Time) ([]domain.ItemSales, error) {
+	query := `WITH lines AS (
+			SELECT oi.menu_item_id, oi.quantity * COALESCE(oi.share, 1) AS units, oi.line_total,
+				oi.unit_cost * oi.quantity * COALESCE(oi.share, 1) AS cost, o.discount,
+				SUM(oi.line_total) OVER (PARTITION BY o.id) AS order_total
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			JOIN menu_items m ON m.id = oi.menu_item_id
+			JOIN LATERAL (SELECT COALESCE(MIN(h.created_at), o.created_at) AS sold_at FROM order_status_history h
+				WHERE h.order_id = o.id AND h.to_status IN ('paid', 'completed')) s ON TRUE
+			WHERE o.status IN ('paid', 'completed') AND oi.voided_at IS NULL
+			AND LOWER(COALESCE(m.category, '')) <> LOWER($3) AND s.sold_at >= $1 AND s.sold_at < $2)
+		SELECT l.menu_item_id, m.name, COALESCE(m.category, '') AS category,
+		ROUND(SUM(l.units))::int AS quantity,
+		ROUND(SUM(l.line_total - COALESCE(l.discount * l.line_total / NULLIF(l.order_total, 0), 0)), 2) AS revenue,
+		SUM(l.cost) AS cost
+		FROM lines l
+		JOIN menu_items m ON m.id = l.menu_item_id
+		GROUP BY l.menu_item_id, m.name, m.category
+		ORDER BY m.category, m.name`
+
+	var sales []domain.ItemSales
+	if err := r.db.SelectContext(ctx, &sales, query, from, to, domain.GiftCardCategory); err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
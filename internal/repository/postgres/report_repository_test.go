@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRepository_MenuItemCosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewReportRepository(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"menu_item_id", "name", "category", "price", "cost"}).
+		AddRow(uuid.New(), "Latte", "Coffee", decimal.NewFromFloat(4), decimal.NewFromFloat(0.95))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT m.id AS menu_item_id, m.name`)).WillReturnRows(rows)
+
+	costs, err := repo.MenuItemCosts(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, costs, 1)
+	assert.Equal(t, "0.95", costs[0].Cost.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_ItemSales(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewReportRepository(sqlxDB)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	rows := sqlmock.NewRows([]string{"menu_item_id", "name", "category", "quantity", "revenue", "cost"}).
+		AddRow(uuid.New(), "Latte", "Coffee", 12, decimal.NewFromFloat(48), decimal.NewFromFloat(11.4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT l.menu_item_id, m.name`)).
+		WithArgs(from, to, domain.GiftCardCategory).
+		WillReturnRows(rows)
+
+	sales, err := repo.ItemSales(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.Len(t, sales, 1)
+	assert.Equal(t, 12, sales[0].Quantity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
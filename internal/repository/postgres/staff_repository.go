@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type staffRepository struct {
+	db *sqlx.DB
+}
+
+func NewStaffRepository(db *sqlx.DB) domain.StaffRepository {
+	return &staffRepository{db: db}
+}
+
+func (r *staffRepository) Create(ctx context.Context, staff *domain.Staff) error {
+	query := `INSERT INTO staff (id, username, name, password_hash, role, active, created_at, updated_at)
+		VALUES (:id, :username, :name, :password_hash, :role, :active, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, staff)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *staffRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Staff, error) {
+	return r.get(ctx, `SELECT id, username, name, password_hash, role, active, pin_hash, pin_failed_attempts, pin_locked_until, created_at, updated_at FROM staff WHERE id = $1`, id)
+}
+
+func (r *staffRepository) GetByUsername(ctx context.Context, username string) (*domain.Staff, error) {
+	return r.get(ctx, `SELECT id, username, name, password_hash, role, active, pin_hash, pin_failed_attempts, pin_locked_until, created_at, updated_at FROM staff WHERE username = $1`, username)
+}
+
+func (r *staffRepository) get(ctx context.Context, query string, arg interface{}) (*domain.Staff, error) {
+	var staff domain.Staff
+	if err := r.db.GetContext(ctx, &staff, query, arg); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &staff, nil
+}
+
+func (r *staffRepository) List(ctx context.Context) ([]domain.Staff, error) {
+	staff := []domain.Staff{}
+	query := `SELECT id, username, name, password_hash, role, active, pin_hash, pin_failed_attempts, pin_locked_until, created_at, updated_at FROM staff ORDER BY username`
+	if err := r.db.SelectContext(ctx, &staff, query); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+func (r *staffRepository) SetPIN(ctx context.Context, id uuid.UUID, pinHash string, updatedAt time.Time) error {
+	query := `UPDATE staff SET pin_hash = $1, pin_failed_attempts = 0, pin_locked_until = NULL, updated_at = $2 WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, pinHash, updatedAt, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *staffRepository) ClaimPINAttempt(ctx context.Context, id uuid.UUID, maxAttempts int, now, lockUntil time.Time) (bool, error) {
+	// Checking the lock, counting and locking in one statement means every
+	// guess holds an attempt before its PIN is compared, so concurrent guesses
+	// cannot get past the limit. The counter restarts once the PIN is locked.
+	query := `UPDATE staff SET
+			pin_failed_attempts = CASE WHEN pin_failed_attempts + 1 >= $2 THEN 0 ELSE pin_failed_attempts + 1 END,
+			pin_locked_until = CASE WHEN pin_failed_attempts + 1 >= $2 THEN $4 ELSE pin_locked_until END
+		WHERE id = $1 AND (pin_locked_until IS NULL OR pin_locked_until <= $3)
+		RETURNING pin_failed_attempts = 0`
+	var last bool
+	if err := r.db.GetContext(ctx, &last, query, id, maxAttempts, now, lockUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, domain.ErrPINLocked
+		}
+		return false, err
+	}
+	return last, nil
+}
+
+func (r *staffRepository) ResetPINFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE staff SET pin_failed_attempts = 0, pin_locked_until = NULL WHERE id = $1 AND (pin_failed_attempts > 0 OR pin_locked_until IS NOT NULL)`, id)
+	return err
+}
+
+func (r *staffRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM staff`); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaffRepository_Create_DuplicateUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStaffRepository(sqlxDB)
+	staff := &domain.Staff{ID: uuid.New(), Username: "sam", Name: "Sam", PasswordHash: "hash", Role: domain.RoleCashier, Active: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO staff (id, username, name, password_hash, role, active, created_at, updated_at)`)).
+		WithArgs(staff.ID, staff.Username, staff.Name, staff.PasswordHash, staff.Role, staff.Active, staff.CreatedAt, staff.UpdatedAt).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err = repo.Create(context.Background(), staff)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStaffRepository_GetByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStaffRepository(sqlxDB)
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, name, password_hash, role, active, pin_hash, pin_failed_attempts, pin_locked_until, created_at, updated_at FROM staff WHERE username = $1`)).
+		WithArgs("sam").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "name", "password_hash", "role", "active", "pin_hash", "pin_failed_attempts", "pin_locked_until", "created_at", "updated_at"}).
+			AddRow(id, "sam", "Sam", "hash", domain.RoleManager, true, nil, 0, nil, time.Now(), time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, name, password_hash, role, active, pin_hash, pin_failed_attempts, pin_locked_until, created_at, updated_at FROM staff WHERE username = $1`)).
+		WithArgs("nobody").
+		WillReturnError(sql.ErrNoRows)
+
+	staff, err := repo.GetByUsername(context.Background(), "sam")
+	assert.NoError(t, err)
+	assert.Equal(t, id, staff.ID)
+	assert.Equal(t, domain.RoleManager, staff.Role)
+
+	staff, err = repo.GetByUsername(context.Background(), "nobody")
+	assert.NoError(t, err)
+	assert.Nil(t, staff)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStaffRepository_ClaimPINAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStaffRepository(sqlxDB)
+	id := uuid.New()
+	now := time.Now()
+	lockUntil := now.Add(15 * time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE id = $1 AND (pin_locked_until IS NULL OR pin_locked_until <= $3)`)).
+		WithArgs(id, 5, now, lockUntil).
+		WillReturnRows(sqlmock.NewRows([]string{"last"}).AddRow(true))
+
+	last, err := repo.ClaimPINAttempt(context.Background(), id, 5, now, lockUntil)
+	assert.NoError(t, err)
+	assert.True(t, last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStaffRepository_ClaimPINAttempt_Locked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStaffRepository(sqlxDB)
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE staff SET`)).
+		WithArgs(id, 5, now, now.Add(15*time.Minute)).
+		WillReturnRows(sqlmock.NewRows([]string{"last"}))
+
+	_, err = repo.ClaimPINAttempt(context.Background(), id, 5, now, now.Add(15*time.Minute))
+	assert.ErrorIs(t, err, domain.ErrPINLocked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
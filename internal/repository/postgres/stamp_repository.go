@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type stampRepository struct {
+	db *sqlx.DB
+}
+
+func NewStampRepository(db *sqlx.DB) domain.StampRepository {
+	return &stampRepository{db: db}
+}
+
+// stampProgramRow scans the categories array that domain.StampProgram keeps as
+// a plain slice.
+type stampProgramRow struct {
+	domain.StampProgram
+	Categories pq.StringArray `db:"categories"`
+}
+
+func (row stampProgramRow) toProgram() domain.StampProgram {
+	program := row.StampProgram
+	program.Categories = []string(row.Categories)
+	return program
+}
+
+func (r *stampRepository) CreateProgram(ctx context.Context, program *domain.StampProgram) error {
+	query := `INSERT INTO stamp_programs (id, name, categories, stamps_required, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.ExecContext(ctx, query, program.ID, program.Name, pq.Array(program.Categories),
+		program.StampsRequired, program.Active, program.CreatedAt, program.UpdatedAt)
+	return err
+}
+
+func (r *stampRepository) GetProgram(ctx context.Context, id uuid.UUID) (*domain.StampProgram, error) {
+	var row stampProgramRow
+	query := `SELECT id, name, categories, stamps_required, active, created_at, updated_at FROM stamp_programs WHERE id = $1`
+	if err := r.db.GetContext(ctx, &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	program := row.toProgram()
+	return &program, nil
+}
+
+func (r *stampRepository) ListPrograms(ctx context.Context, activeOnly bool) ([]domain.StampProgram, error) {
+	query := `SELECT id, name, categories, stamps_required, active, created_at, updated_at FROM stamp_programs`
+	if activeOnly {
+		query += " WHERE active"
+	}
+	query += " ORDER BY name"
+
+	var rows []stampProgramRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	programs := make([]domain.StampProgram, len(rows))
+	for i, row := range rows {
+		programs[i] = row.toProgram()
+	}
+	return programs, nil
+}
+
+func (r *stampRepository) UpdateProgram(ctx context.Context, program *domain.StampProgram) error {
+	query := `UPDATE stamp_programs SET name = $1, categories = $2, stamps_required = $3, active = $4, updated_at = $5
+		WHERE id = $6`
+	result, err := r.db.ExecContext(ctx, query, program.Name, pq.Array(program.Categories),
+		program.StampsRequired, program.Active, program.UpdatedAt, program.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Balances returns the current number of stamps per program for a customer.
+func (r *stampRepository) Balances(ctx context.Context, customerID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ProgramID uuid.UUID `db:"program_id"`
+		Stamps    int       `db:"stamps"`
+	}
+	query := `SELECT program_id, SUM(stamps) AS stamps FROM stamp_ledger WHERE customer_id = $1 GROUP BY program_id`
+	if err := r.db.SelectContext(ctx, &rows, query, customerID); err != nil {
+		return nil, err
+	}
+
+	balances := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		balances[row.ProgramID] = row.Stamps
+	}
+	return balances, nil
+}
+
+func (r *stampRepository) ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]domain.StampEntry, error) {
+	var entries []domain.StampEntry
+	query := `SELECT id, program_id, customer_id, order_id, order_item_id, type, stamps, created_at FROM stamp_ledger
+		WHERE order_id = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &entries, query, orderID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddEntry appends an entry to the ledger. A program records at most one entry
+// of each type per order, so repeating a stamp or reversal is a no-op.
+func (r *stampRepository) AddEntry(ctx context.Context, entry *domain.StampEntry) error {
+	query := `INSERT INTO stamp_ledger (id, program_id, customer_id, order_id, order_item_id, type, stamps, created_at)
+		VALUES (:id, :program_id, :customer_id, :order_id, :order_item_id, :type, :stamps, :created_at)
+		ON CONFLICT (program_id, order_id, type) WHERE order_id IS NOT NULL AND type <> 'void' DO NOTHING`
+	_, err := r.db.NamedExecContext(ctx, query, entry)
+	return err
+}
+
+// insertStampEntry appends an entry to the ledger within tx.
+func insertStampEntry(ctx context.Context, tx *sqlx.Tx, entry *domain.StampEntry) error {
+	query := `INSERT INTO stamp_ledger (id, program_id, customer_id, order_id, order_item_id, type, stamps, created_at)
+		VALUES (:id, :program_id, :customer_id, :order_id, :order_item_id, :type, :stamps, :created_at)`
+	_, err := tx.NamedExecContext(ctx, query, entry)
+	return err
+}
+
+// Redeem debits a full card, locking the customer row so two orders cannot
+// spend the same card.
+func (r *stampRepository) Redeem(ctx context.Context, entry *domain.StampEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var customerID uuid.UUID
+	if err := tx.GetContext(ctx, &customerID, `SELECT id FROM customers WHERE id = $1 FOR UPDATE`, entry.CustomerID); err != nil {
+		return err
+	}
+
+	var balance int
+	query := `SELECT COALESCE(SUM(stamps), 0) FROM stamp_ledger WHERE customer_id = $1 AND program_id = $2`
+	if err := tx.GetContext(ctx, &balance, query, entry.CustomerID, entry.ProgramID); err != nil {
+		return err
+	}
+	if balance+entry.Stamps < 0 {
+		return domain.ErrInsufficientStamps
+	}
+
+	insert := `INSERT INTO stamp_ledger (id, program_id, customer_id, order_id, type, stamps, created_at)
+		VALUES (:id, :program_id, :customer_id, :order_id, :type, :stamps, :created_at)`
+	if _, err := tx.NamedExecContext(ctx, insert, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
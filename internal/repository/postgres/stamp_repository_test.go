@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampRepository_GetProgram(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStampRepository(sqlxDB)
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, categories, stamps_required, active, created_at, updated_at FROM stamp_programs WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "categories", "stamps_required", "active", "created_at", "updated_at"}).
+			AddRow(id, "Hot coffee card", "{Coffee,Espresso}", 9, true, time.Now(), time.Now()))
+
+	program, err := repo.GetProgram(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Coffee", "Espresso"}, program.Categories)
+	assert.Equal(t, 9, program.StampsRequired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStampRepository_Balances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStampRepository(sqlxDB)
+	customerID := uuid.New()
+	programID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT program_id, SUM(stamps) AS stamps FROM stamp_ledger WHERE customer_id = $1 GROUP BY program_id`)).
+		WithArgs(customerID).
+		WillReturnRows(sqlmock.NewRows([]string{"program_id", "stamps"}).AddRow(programID, 7))
+
+	balances, err := repo.Balances(context.Background(), customerID)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, balances[programID])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStampRepository_Redeem_InsufficientStamps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewStampRepository(sqlxDB)
+	entry := &domain.StampEntry{ID: uuid.New(), ProgramID: uuid.New(), CustomerID: uuid.New(), Type: domain.StampEntryRedeem, Stamps: -9}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM customers WHERE id = $1 FOR UPDATE`)).
+		WithArgs(entry.CustomerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entry.CustomerID))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(stamps), 0) FROM stamp_ledger WHERE customer_id = $1 AND program_id = $2`)).
+		WithArgs(entry.CustomerID, entry.ProgramID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(8))
+	mock.ExpectRollback()
+
+	err = repo.Redeem(context.Background(), entry)
+	assert.ErrorIs(t, err, domain.ErrInsufficientStamps)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
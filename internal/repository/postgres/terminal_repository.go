@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type terminalRepository struct {
+	db *sqlx.DB
+}
+
+func NewTerminalRepository(db *sqlx.DB) domain.TerminalRepository {
+	return &terminalRepository{db: db}
+}
+
+func (r *terminalRepository) Create(ctx context.Context, terminal *domain.Terminal) error {
+	query := `INSERT INTO terminals (id, name, token_hash, active, created_at, updated_at)
+		VALUES (:id, :name, :token_hash, :active, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, terminal)
+	return err
+}
+
+func (r *terminalRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Terminal, error) {
+	var terminal domain.Terminal
+	query := `SELECT id, name, token_hash, active, last_seen_at, created_at, updated_at FROM terminals WHERE token_hash = $1`
+	if err := r.db.GetContext(ctx, &terminal, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+func (r *terminalRepository) List(ctx context.Context) ([]domain.Terminal, error) {
+	terminals := []domain.Terminal{}
+	query := `SELECT id, name, token_hash, active, last_seen_at, created_at, updated_at FROM terminals ORDER BY name`
+	if err := r.db.SelectContext(ctx, &terminals, query); err != nil {
+		return nil, err
+	}
+	return terminals, nil
+}
+
+func (r *terminalRepository) Deactivate(ctx context.Context, id uuid.UUID, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE terminals SET active = FALSE, updated_at = $1 WHERE id = $2`, at, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE terminal_sessions SET ended_at = $1 WHERE terminal_id = $2 AND ended_at IS NULL`, at, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *terminalRepository) StartSession(ctx context.Context, session *domain.TerminalSession) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE terminal_sessions SET ended_at = $1 WHERE terminal_id = $2 AND ended_at IS NULL`,
+		session.CreatedAt, session.TerminalID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO terminal_sessions (id, terminal_id, staff_id, token_hash, last_seen_at, expires_at, created_at)
+		VALUES (:id, :terminal_id, :staff_id, :token_hash, :last_seen_at, :expires_at, :created_at)`
+	if _, err := tx.NamedExecContext(ctx, query, session); err != nil {
+		// Two logins racing on the same terminal: the loser's insert hits the
+		// one-open-session index.
+		if isUniqueViolation(err) {
+			return domain.ErrAlreadyExists
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE terminals SET last_seen_at = $1 WHERE id = $2`, session.CreatedAt, session.TerminalID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *terminalRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*domain.TerminalSession, error) {
+	query := `SELECT ts.id, ts.terminal_id, ts.staff_id, ts.token_hash, ts.last_seen_at, ts.expires_at, ts.ended_at, ts.created_at,
+		s.username, s.role, s.active AS staff_active, t.active AS terminal_active
+		FROM terminal_sessions ts
+		JOIN staff s ON s.id = ts.staff_id
+		JOIN terminals t ON t.id = ts.terminal_id
+		WHERE ts.token_hash = $1`
+	var session domain.TerminalSession
+	if err := r.db.GetContext(ctx, &session, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *terminalRepository) TouchSession(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE terminal_sessions SET last_seen_at = $1 WHERE id = $2 AND ended_at IS NULL`, at, id)
+	return err
+}
+
+func (r *terminalRepository) EndSession(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE terminal_sessions SET ended_at = $1 WHERE id = $2 AND ended_at IS NULL`, at, id)
+	return err
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalRepository_StartSession_EndsOpenSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewTerminalRepository(sqlxDB)
+	now := time.Now()
+	session := &domain.TerminalSession{
+		ID:         uuid.New(),
+		TerminalID: uuid.New(),
+		StaffID:    uuid.New(),
+		TokenHash:  "hash",
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+		CreatedAt:  now,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE terminal_sessions SET ended_at = $1 WHERE terminal_id = $2 AND ended_at IS NULL`)).
+		WithArgs(now, session.TerminalID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO terminal_sessions`)).
+		WithArgs(session.ID, session.TerminalID, session.StaffID, session.TokenHash, session.LastSeenAt, session.ExpiresAt, session.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE terminals SET last_seen_at = $1 WHERE id = $2`)).
+		WithArgs(now, session.TerminalID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.StartSession(context.Background(), session)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTerminalRepository_StartSession_ConcurrentLogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewTerminalRepository(sqlxDB)
+	session := &domain.TerminalSession{ID: uuid.New(), TerminalID: uuid.New(), CreatedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE terminal_sessions SET ended_at`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO terminal_sessions`)).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	err = repo.StartSession(context.Background(), session)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTerminalRepository_GetSessionByTokenHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewTerminalRepository(sqlxDB)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "terminal_id", "staff_id", "token_hash", "last_seen_at", "expires_at", "ended_at", "created_at", "username", "role", "staff_active", "terminal_active"}).
+		AddRow(uuid.New(), uuid.New(), uuid.New(), "hash", now, now.Add(time.Hour), nil, now, "sam", domain.RoleBarista, true, true)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM terminal_sessions ts`)).WithArgs("hash").WillReturnRows(rows)
+
+	session, err := repo.GetSessionByTokenHash(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "sam", session.Username)
+	assert.True(t, session.TerminalActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
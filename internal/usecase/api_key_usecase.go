@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "key_"
+	// apiKeyDisplayLength is how much of a key is kept in clear to tell keys apart.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval keeps busy keys from writing last_used_at on every call.
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKeyName  = errors.New("API key name is required")
+	ErrInvalidAPIKeyScope = errors.New("unknown API key scope")
+	ErrAPIKeyNeedsScope   = errors.New("API key needs at least one scope")
+)
+
+type apiKeyUsecase struct {
+	apiKeyRepo domain.APIKeyRepository
+	now        func() time.Time
+}
+
+func NewAPIKeyUsecase(apiKeyRepo domain.APIKeyRepository) domain.APIKeyUsecase {
+	return &apiKeyUsecase{apiKeyRepo: apiKeyRepo, now: time.Now}
+}
+
+func (u *apiKeyUsecase) Create(ctx context.Context, key *domain.APIKey) error {
+	if err := domain.Authorize(ctx, domain.PermAPIKeysManage); err != nil {
+		return err
+	}
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" {
+		return ErrInvalidAPIKeyName
+	}
+	scopes := make(map[string]bool)
+	for _, scope := range key.Scopes {
+		if _, ok := domain.APIKeyScopes[scope]; !ok {
+			return ErrInvalidAPIKeyScope
+		}
+		scopes[scope] = true
+	}
+	if len(scopes) == 0 {
+		return ErrAPIKeyNeedsScope
+	}
+	key.Scopes = key.Scopes[:0]
+	for scope := range scopes {
+		key.Scopes = append(key.Scopes, scope)
+	}
+	sort.Strings(key.Scopes)
+
+	token, hash, err := newOpaqueToken(apiKeyPrefix)
+	if err != nil {
+		return err
+	}
+	identity, _ := domain.IdentityFromContext(ctx)
+	key.ID = uuid.New()
+	key.Prefix = token[:apiKeyDisplayLength]
+	key.KeyHash = hash
+	key.CreatedBy = identity.StaffRef()
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	key.CreatedAt = u.now()
+	if err := u.apiKeyRepo.Create(ctx, key); err != nil {
+		return err
+	}
+	key.Key = token
+	return nil
+}
+
+func (u *apiKeyUsecase) List(ctx context.Context) ([]domain.APIKey, error) {
+	if err := domain.Authorize(ctx, domain.PermAPIKeysManage); err != nil {
+		return nil, err
+	}
+	return u.apiKeyRepo.List(ctx)
+}
+
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := domain.Authorize(ctx, domain.PermAPIKeysManage); err != nil {
+		return err
+	}
+	err := u.apiKeyRepo.Revoke(ctx, id, u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, token string) (*domain.Identity, error) {
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+	key, err := u.apiKeyRepo.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	now := u.now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := u.apiKeyRepo.Touch(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.Identity{
+		Username: key.Name,
+		APIKeyID: &key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
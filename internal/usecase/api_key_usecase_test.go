@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyRepo struct{ mock.Mock }
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+func (m *mockAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+func (m *mockAPIKeyRepo) List(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+func (m *mockAPIKeyRepo) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+func (m *mockAPIKeyRepo) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func TestAPIKeyUsecase_Create_ReturnsKeyOnce(t *testing.T) {
+	repo := new(mockAPIKeyRepo)
+	u := NewAPIKeyUsecase(repo)
+
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).Return(nil)
+
+	key := &domain.APIKey{Name: " Kiosk ", Scopes: []string{"orders:write", "menu:read", "menu:read"}}
+	assert.NoError(t, u.Create(managerCtx(), key))
+	assert.Equal(t, "Kiosk", key.Name)
+	assert.Equal(t, []string{"menu:read", "orders:write"}, key.Scopes)
+	assert.Contains(t, key.Key, apiKeyPrefix)
+	assert.Equal(t, hashToken(key.Key), key.KeyHash)
+	assert.True(t, len(key.Key) > len(key.Prefix) && key.Key[:len(key.Prefix)] == key.Prefix)
+	assert.NotNil(t, key.CreatedBy)
+
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.APIKey{Name: "Sync", Scopes: []string{"staff:manage"}}), ErrInvalidAPIKeyScope)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.APIKey{Name: "Sync"}), ErrAPIKeyNeedsScope)
+	assert.ErrorIs(t, u.Create(staffCtx(domain.RoleCashier), &domain.APIKey{Name: "Sync", Scopes: []string{"menu:read"}}), domain.ErrForbidden)
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	repo := new(mockAPIKeyRepo)
+	u := NewAPIKeyUsecase(repo)
+	key := &domain.APIKey{ID: uuid.New(), Name: "Kiosk", Scopes: []string{"menu:read", "orders:write"}}
+	revokedAt := time.Now()
+
+	repo.On("GetByHash", mock.Anything, hashToken("key_good")).Return(key, nil)
+	repo.On("GetByHash", mock.Anything, hashToken("key_revoked")).Return(&domain.APIKey{ID: uuid.New(), RevokedAt: &revokedAt}, nil)
+	repo.On("GetByHash", mock.Anything, hashToken("key_unknown")).Return(nil, nil)
+	repo.On("Touch", mock.Anything, key.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	identity, err := u.Authenticate(context.Background(), "key_good")
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, *identity.APIKeyID)
+	assert.Nil(t, identity.StaffRef())
+	assert.True(t, identity.Can(domain.PermOrdersCreate))
+	assert.True(t, identity.Can(domain.PermMenuRead))
+	assert.False(t, identity.Can(domain.PermMenuWrite))
+	assert.False(t, identity.Can(domain.PermOrdersRefund))
+
+	// Recently used keys are not touched again.
+	lastUsed := time.Now()
+	key.LastUsedAt = &lastUsed
+	_, err = u.Authenticate(context.Background(), "key_good")
+	assert.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "Touch", 1)
+
+	_, err = u.Authenticate(context.Background(), "key_revoked")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	_, err = u.Authenticate(context.Background(), "key_unknown")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	_, err = u.Authenticate(context.Background(), "pos_session")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
+
+func TestAPIKeyUsecase_Revoke(t *testing.T) {
+	repo := new(mockAPIKeyRepo)
+	u := NewAPIKeyUsecase(repo)
+	id := uuid.New()
+
+	repo.On("Revoke", mock.Anything, id, mock.AnythingOfType("time.Time")).Return(nil)
+
+	assert.NoError(t, u.Revoke(managerCtx(), id))
+	assert.ErrorIs(t, u.Revoke(staffCtx(domain.RoleCashier), id), domain.ErrForbidden)
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditUsecase struct {
+	auditRepo domain.AuditRepository
+	now       func() time.Time
+}
+
+func NewAuditUsecase(auditRepo domain.AuditRepository) domain.AuditUsecase {
+	return &auditUsecase{auditRepo: auditRepo, now: time.Now}
+}
+
+func (u *auditUsecase) Entry(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) (*domain.AuditEntry, error) {
+	beforeJSON, afterJSON, err := auditDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.AuditEntry{
+		ID:         uuid.New(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		RequestID:  domain.RequestIDFromContext(ctx),
+		CreatedAt:  u.now(),
+	}
+	if identity, ok := domain.IdentityFromContext(ctx); ok {
+		entry.ActorID = identity.StaffRef()
+		entry.APIKeyID = identity.APIKeyID
+		entry.ActorName = identity.Username
+	}
+	return entry, nil
+}
+
+func (u *auditUsecase) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := domain.Authorize(ctx, domain.PermAuditRead); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	return u.auditRepo.List(ctx, filter)
+}
+
+// auditDiff renders both snapshots as JSON objects and, when there are two,
+// drops the fields they agree on.
+func auditDiff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if other, ok := afterFields[key]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+
+	beforeJSON, err := marshalFields(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshalFields(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+// jsonFields returns the JSON object form of v, or nil for nil (including a nil
+// pointer).
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func marshalFields(fields map[string]interface{}) (json.RawMessage, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAuditRepo struct{ mock.Mock }
+
+func (m *mockAuditRepo) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+func (m *mockAuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func TestAuditUsecase_Entry_KeepsOnlyChangedFields(t *testing.T) {
+	repo := new(mockAuditRepo)
+	u := NewAuditUsecase(repo)
+	id := uuid.New()
+	before := &domain.MenuItem{ID: id, Name: "Latte", Price: decimal.NewFromFloat(4), Category: "Coffee"}
+	after := &domain.MenuItem{ID: id, Name: "Latte", Price: decimal.NewFromFloat(4.5), Category: "Coffee"}
+
+	ctx := domain.WithRequestID(managerCtx(), "req-1")
+	entry, err := u.Entry(ctx, domain.AuditMenuItemUpdate, domain.AuditEntityMenuItem, id, before, after)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price":"4"}`, string(entry.Before))
+	assert.JSONEq(t, `{"price":"4.5"}`, string(entry.After))
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, domain.RoleManager, entry.ActorName)
+	assert.NotNil(t, entry.ActorID)
+
+	// A delete keeps the whole entity in Before.
+	var deleted *domain.MenuItem
+	entry, err = u.Entry(ctx, domain.AuditMenuItemDelete, domain.AuditEntityMenuItem, id, before, deleted)
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Before), `"name":"Latte"`)
+	assert.Nil(t, entry.After)
+	// Writing the entry is left to the repository making the change.
+	repo.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestAuditUsecase_List(t *testing.T) {
+	repo := new(mockAuditRepo)
+	u := NewAuditUsecase(repo)
+
+	repo.On("List", mock.Anything, domain.AuditFilter{Action: domain.AuditOrderStatusChange, Limit: defaultAuditLimit}).Return([]domain.AuditEntry{}, nil)
+
+	_, err := u.List(managerCtx(), domain.AuditFilter{Action: domain.AuditOrderStatusChange})
+	assert.NoError(t, err)
+	_, err = u.List(staffCtx(domain.RoleCashier), domain.AuditFilter{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	repo.AssertExpectations(t)
+}
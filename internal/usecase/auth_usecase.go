@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// jwtHeader is the fixed, pre-encoded header of every token we issue.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// dummyPasswordHash is compared against when a username does not exist so that
+// login takes the same time whether or not the account is there.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type AuthConfig struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	Username  string `json:"usr"`
+	Role      string `json:"rol"`
+	Type      string `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type authUsecase struct {
+	staffRepo domain.StaffRepository
+	config    AuthConfig
+	now       func() time.Time
+}
+
+func NewAuthUsecase(staffRepo domain.StaffRepository, config AuthConfig) domain.AuthUsecase {
+	return &authUsecase{
+		staffRepo: staffRepo,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+func (u *authUsecase) Login(ctx context.Context, username, password string) (*domain.TokenPair, error) {
+	staff, err := u.staffRepo.GetByUsername(ctx, normalizeUsername(username))
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(staff.PasswordHash), []byte(password)); err != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if !staff.Active {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return u.issue(staff)
+}
+
+func (u *authUsecase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	claims, err := u.parse(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	staffID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// Refreshing re-reads the account so deactivated staff lose access once
+	// their current access token runs out.
+	staff, err := u.staffRepo.GetByID(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil || !staff.Active {
+		return nil, domain.ErrInvalidToken
+	}
+	return u.issue(staff)
+}
+
+func (u *authUsecase) Authenticate(ctx context.Context, accessToken string) (*domain.Identity, error) {
+	claims, err := u.parse(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+	staffID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	return &domain.Identity{StaffID: staffID, Username: claims.Username, Role: claims.Role}, nil
+}
+
+func (u *authUsecase) issue(staff *domain.Staff) (*domain.TokenPair, error) {
+	now := u.now()
+	accessExpiresAt := now.Add(u.config.AccessTTL)
+	refreshExpiresAt := now.Add(u.config.RefreshTTL)
+
+	accessToken, err := u.sign(tokenClaims{
+		Subject:   staff.ID.String(),
+		Username:  staff.Username,
+		Role:      staff.Role,
+		Type:      tokenTypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: accessExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := u.sign(tokenClaims{
+		Subject:   staff.ID.String(),
+		Username:  staff.Username,
+		Role:      staff.Role,
+		Type:      tokenTypeRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: refreshExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		TokenType:        "Bearer",
+	}, nil
+}
+
+func (u *authUsecase) sign(claims tokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(u.signature(unsigned)), nil
+}
+
+func (u *authUsecase) parse(token, tokenType string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, domain.ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, u.signature(parts[0]+"."+parts[1])) {
+		return nil, domain.ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	if claims.Type != tokenType || u.now().Unix() >= claims.ExpiresAt {
+		return nil, domain.ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+func (u *authUsecase) signature(unsigned string) []byte {
+	mac := hmac.New(sha256.New, u.config.Secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
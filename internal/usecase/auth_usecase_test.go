@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testAuthConfig() AuthConfig {
+	return AuthConfig{
+		Secret:     []byte("0123456789abcdef0123456789abcdef"),
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	}
+}
+
+func testStaff(t *testing.T, password string) *domain.Staff {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return &domain.Staff{ID: uuid.New(), Username: "sam", Name: "Sam", PasswordHash: string(hash), Role: domain.RoleBarista, Active: true}
+}
+
+func TestAuthUsecase_Login_IssuesUsableTokens(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewAuthUsecase(staffRepo, testAuthConfig())
+	staff := testStaff(t, "password1")
+
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(staff, nil)
+
+	tokens, err := u.Login(context.Background(), " Sam ", "password1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+
+	identity, err := u.Authenticate(context.Background(), tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, staff.ID, identity.StaffID)
+	assert.Equal(t, "sam", identity.Username)
+	assert.Equal(t, domain.RoleBarista, identity.Role)
+
+	// A refresh token is not an access token.
+	_, err = u.Authenticate(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
+
+func TestAuthUsecase_Login_InvalidCredentials(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewAuthUsecase(staffRepo, testAuthConfig())
+	staff := testStaff(t, "password1")
+	inactive := testStaff(t, "password1")
+	inactive.Active = false
+
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(staff, nil).Once()
+	staffRepo.On("GetByUsername", mock.Anything, "nobody").Return(nil, nil).Once()
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(inactive, nil).Once()
+
+	_, err := u.Login(context.Background(), "sam", "wrong-password")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, err = u.Login(context.Background(), "nobody", "password1")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, err = u.Login(context.Background(), "sam", "password1")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestAuthUsecase_Authenticate_RejectsTamperedAndExpiredTokens(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewAuthUsecase(staffRepo, testAuthConfig()).(*authUsecase)
+	staff := testStaff(t, "password1")
+
+	tokens, err := u.issue(staff)
+	assert.NoError(t, err)
+
+	parts := strings.Split(tokens.AccessToken, ".")
+	forged, _ := NewAuthUsecase(staffRepo, AuthConfig{Secret: []byte("another-secret-another-secret-00"), AccessTTL: time.Hour}).(*authUsecase).issue(staff)
+	forgedParts := strings.Split(forged.AccessToken, ".")
+	_, err = u.Authenticate(context.Background(), parts[0]+"."+forgedParts[1]+"."+parts[2])
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	_, err = u.Authenticate(context.Background(), forged.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	_, err = u.Authenticate(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	u.now = func() time.Time { return time.Now().Add(16 * time.Minute) }
+	_, err = u.Authenticate(context.Background(), tokens.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewAuthUsecase(staffRepo, testAuthConfig()).(*authUsecase)
+	staff := testStaff(t, "password1")
+
+	tokens, err := u.issue(staff)
+	assert.NoError(t, err)
+
+	staffRepo.On("GetByID", mock.Anything, staff.ID).Return(staff, nil).Once()
+	refreshed, err := u.Refresh(context.Background(), tokens.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	// Access tokens cannot be used to refresh.
+	_, err = u.Refresh(context.Background(), tokens.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	deactivated := *staff
+	deactivated.Active = false
+	staffRepo.On("GetByID", mock.Anything, staff.ID).Return(&deactivated, nil).Once()
+	_, err = u.Refresh(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
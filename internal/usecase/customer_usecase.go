@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCustomerName  = errors.New("customer name is required")
+	ErrInvalidCustomerEmail = errors.New("invalid customer email")
+	ErrInvalidCustomerPhone = errors.New("invalid customer phone")
+)
+
+type customerUsecase struct {
+	customerRepo domain.CustomerRepository
+	orderRepo    domain.OrderRepository
+}
+
+func NewCustomerUsecase(customerRepo domain.CustomerRepository, orderRepo domain.OrderRepository) domain.CustomerUsecase {
+	return &customerUsecase{
+		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
+	}
+}
+
+func (u *customerUsecase) Create(ctx context.Context, customer *domain.Customer) error {
+	if err := domain.Authorize(ctx, domain.PermCustomersWrite); err != nil {
+		return err
+	}
+	if err := normalizeCustomer(customer); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	customer.ID = uuid.New()
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+	return u.customerRepo.Create(ctx, customer)
+}
+
+func (u *customerUsecase) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	return u.customerRepo.GetByID(ctx, id)
+}
+
+func (u *customerUsecase) Search(ctx context.Context, filter domain.CustomerFilter) ([]domain.Customer, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	filter.Phone = normalizePhone(filter.Phone)
+	filter.Email = strings.ToLower(strings.TrimSpace(filter.Email))
+	return u.customerRepo.Search(ctx, filter)
+}
+
+func (u *customerUsecase) Update(ctx context.Context, customer *domain.Customer) error {
+	if err := domain.Authorize(ctx, domain.PermCustomersWrite); err != nil {
+		return err
+	}
+	if err := normalizeCustomer(customer); err != nil {
+		return err
+	}
+
+	existing, err := u.customerRepo.GetByID(ctx, customer.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return domain.ErrNotFound
+	}
+
+	customer.CreatedAt = existing.CreatedAt
+	customer.UpdatedAt = time.Now()
+	err = u.customerRepo.Update(ctx, customer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *customerUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := domain.Authorize(ctx, domain.PermCustomersWrite); err != nil {
+		return err
+	}
+	err := u.customerRepo.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *customerUsecase) OrderHistory(ctx context.Context, customerID uuid.UUID) ([]domain.Order, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+	return u.orderRepo.List(ctx, domain.OrderFilter{CustomerID: &customerID})
+}
+
+// normalizeCustomer validates a customer and stores its contact details in the
+// canonical form used for lookups.
+func normalizeCustomer(customer *domain.Customer) error {
+	customer.Name = strings.TrimSpace(customer.Name)
+	if customer.Name == "" {
+		return ErrInvalidCustomerName
+	}
+
+	customer.Email = strings.ToLower(strings.TrimSpace(customer.Email))
+	if customer.Email != "" {
+		addr, err := mail.ParseAddress(customer.Email)
+		if err != nil || addr.Address != customer.Email {
+			return ErrInvalidCustomerEmail
+		}
+	}
+
+	if customer.Phone != "" {
+		customer.Phone = normalizePhone(customer.Phone)
+		if len(strings.TrimPrefix(customer.Phone, "+")) < 6 {
+			return ErrInvalidCustomerPhone
+		}
+	}
+	return nil
+}
+
+// normalizePhone strips formatting from a phone number, keeping digits and a
+// leading plus sign.
+func normalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if r >= '0' && r <= '9' || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCustomerRepo struct{ mock.Mock }
+
+func (m *mockCustomerRepo) Create(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+func (m *mockCustomerRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+func (m *mockCustomerRepo) Search(ctx context.Context, filter domain.CustomerFilter) ([]domain.Customer, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Customer), args.Error(1)
+}
+func (m *mockCustomerRepo) Update(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+func (m *mockCustomerRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCustomerUsecase_Create_NormalizesContactDetails(t *testing.T) {
+	customerRepo := new(mockCustomerRepo)
+	u := NewCustomerUsecase(customerRepo, new(mockOrderRepo))
+
+	customerRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Customer")).Return(nil)
+
+	customer := &domain.Customer{Name: " Sam ", Phone: "+1 (555) 010-2030", Email: "Sam@Example.com"}
+	err := u.Create(staffCtx(domain.RoleCashier), customer)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, customer.ID)
+	assert.Equal(t, "Sam", customer.Name)
+	assert.Equal(t, "+15550102030", customer.Phone)
+	assert.Equal(t, "sam@example.com", customer.Email)
+}
+
+func TestCustomerUsecase_Create_ValidationErrors(t *testing.T) {
+	u := NewCustomerUsecase(new(mockCustomerRepo), new(mockOrderRepo))
+
+	assert.ErrorIs(t, u.Create(staffCtx(domain.RoleCashier), &domain.Customer{}), ErrInvalidCustomerName)
+	assert.ErrorIs(t, u.Create(staffCtx(domain.RoleCashier), &domain.Customer{Name: "Sam", Email: "not-an-email"}), ErrInvalidCustomerEmail)
+	assert.ErrorIs(t, u.Create(staffCtx(domain.RoleCashier), &domain.Customer{Name: "Sam", Phone: "12"}), ErrInvalidCustomerPhone)
+}
+
+func TestCustomerUsecase_Search_NormalizesFilter(t *testing.T) {
+	customerRepo := new(mockCustomerRepo)
+	u := NewCustomerUsecase(customerRepo, new(mockOrderRepo))
+
+	customerRepo.On("Search", mock.Anything, domain.CustomerFilter{Phone: "5550102030"}).Return([]domain.Customer{{Name: "Sam"}}, nil)
+
+	customers, err := u.Search(staffCtx(domain.RoleCashier), domain.CustomerFilter{Phone: "555-010-2030"})
+	assert.NoError(t, err)
+	assert.Len(t, customers, 1)
+}
+
+func TestCustomerUsecase_OrderHistory(t *testing.T) {
+	customerRepo := new(mockCustomerRepo)
+	orderRepo := new(mockOrderRepo)
+	u := NewCustomerUsecase(customerRepo, orderRepo)
+	id := uuid.New()
+
+	customerRepo.On("GetByID", mock.Anything, id).Return(&domain.Customer{ID: id}, nil)
+	orderRepo.On("List", mock.Anything, domain.OrderFilter{CustomerID: &id}).Return([]domain.Order{{ID: uuid.New()}}, nil)
+
+	orders, err := u.OrderHistory(staffCtx(domain.RoleCashier), id)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+}
+
+func TestCustomerUsecase_OrderHistory_NotFound(t *testing.T) {
+	customerRepo := new(mockCustomerRepo)
+	orderRepo := new(mockOrderRepo)
+	u := NewCustomerUsecase(customerRepo, orderRepo)
+	id := uuid.New()
+
+	customerRepo.On("GetByID", mock.Anything, id).Return(nil, nil)
+
+	_, err := u.OrderHistory(staffCtx(domain.RoleCashier), id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	orderRepo.AssertNotCalled(t, "List")
+}
+
+func TestCustomerUsecase_RequiresCustomerPermissions(t *testing.T) {
+	u := NewCustomerUsecase(new(mockCustomerRepo), new(mockOrderRepo))
+	barista := staffCtx(domain.RoleBarista)
+
+	_, err := u.Search(context.Background(), domain.CustomerFilter{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = u.OrderHistory(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.ErrorIs(t, u.Create(barista, &domain.Customer{Name: "Sam"}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.Update(barista, &domain.Customer{ID: uuid.New(), Name: "Sam"}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.Delete(barista, uuid.New()), domain.ErrForbidden)
+}
@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var ErrGiftCardNotFound = errors.New("gift card not found")
+
+// giftCardAlphabet leaves out characters that are easily misread (0/O, 1/I).
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const giftCardCodeLength = 16
+
+type giftCardUsecase struct {
+	giftCardRepo domain.GiftCardRepository
+	menuRepo     domain.MenuItemRepository
+	validity     time.Duration
+}
+
+// NewGiftCardUsecase creates the gift card usecase. Issued and reloaded cards
+// expire after validity; a zero validity means cards never expire.
+func NewGiftCardUsecase(giftCardRepo domain.GiftCardRepository, menuRepo domain.MenuItemRepository, validity time.Duration) domain.GiftCardUsecase {
+	return &giftCardUsecase{
+		giftCardRepo: giftCardRepo,
+		menuRepo:     menuRepo,
+		validity:     validity,
+	}
+}
+
+func (u *giftCardUsecase) GetByCode(ctx context.Context, code string) (*domain.GiftCard, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersRead); err != nil {
+		return nil, err
+	}
+	card, err := u.giftCardRepo.GetByCode(ctx, normalizeGiftCardCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, ErrGiftCardNotFound
+	}
+
+	card.Transactions, err = u.giftCardRepo.ListTransactions(ctx, card.ID)
+	if err != nil {
+		return nil, err
+	}
+	card.Expired = giftCardExpired(card, time.Now())
+	return card, nil
+}
+
+func (u *giftCardUsecase) ListForOrder(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCard, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersRead); err != nil {
+		return nil, err
+	}
+	cards, err := u.giftCardRepo.ListByIssuedOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range cards {
+		cards[i].Expired = giftCardExpired(&cards[i], now)
+	}
+	return cards, nil
+}
+
+// IssueForOrder issues or reloads the gift cards sold on a paid order. Cards
+// the order already issued or reloaded are skipped, so a retry after a partial
+// failure only completes what is missing.
+func (u *giftCardUsecase) IssueForOrder(ctx context.Context, order *domain.Order) error {
+	existing, err := u.giftCardRepo.ListOrderTransactions(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	issued := make(map[string]int)
+	reloaded := make(map[uuid.UUID]int)
+	for _, entry := range existing {
+		switch entry.Type {
+		case domain.GiftCardIssue:
+			issued[entry.Amount.StringFixed(2)]++
+		case domain.GiftCardReload:
+			reloaded[entry.GiftCardID]++
+		}
+	}
+
+	now := time.Now()
+	orderID := order.ID
+	for _, item := range order.Items {
+		if !item.LineTotal.IsPositive() {
+			continue
+		}
+		menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return err
+		}
+		if menuItem == nil || !strings.EqualFold(menuItem.Category, domain.GiftCardCategory) {
+			continue
+		}
+
+		if item.GiftCardCode != "" {
+			card, err := u.giftCardRepo.GetByCode(ctx, item.GiftCardCode)
+			if err != nil {
+				return err
+			}
+			if card == nil {
+				return ErrGiftCardNotFound
+			}
+			if reloaded[card.ID] > 0 {
+				reloaded[card.ID]--
+				continue
+			}
+			err = u.giftCardRepo.Credit(ctx, &domain.GiftCardTransaction{
+				ID:         uuid.New(),
+				GiftCardID: card.ID,
+				OrderID:    &orderID,
+				Type:       domain.GiftCardReload,
+				Amount:     item.LineTotal,
+				CreatedAt:  now,
+			}, u.expiry(now))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		value := item.UnitPrice.StringFixed(2)
+		for n := 0; n < item.Quantity; n++ {
+			if issued[value] > 0 {
+				issued[value]--
+				continue
+			}
+			if err := u.issue(ctx, orderID, item.UnitPrice, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RefundOrder undoes an order's gift card activity: payments made with cards
+// are credited back and cards sold on the order are voided.
+func (u *giftCardUsecase) RefundOrder(ctx context.Context, orderID uuid.UUID) error {
+	entries, err := u.giftCardRepo.ListOrderTransactions(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	// Voided lines may already have been refunded to a card, so only what is
+	// still owed is given back, and cards already voided are left alone.
+	owed := make(map[uuid.UUID]decimal.Decimal)
+	voided := make(map[uuid.UUID]bool)
+	for _, entry := range entries {
+		switch entry.Type {
+		case domain.GiftCardRedeem, domain.GiftCardRefund:
+			owed[entry.GiftCardID] = owed[entry.GiftCardID].Sub(entry.Amount)
+		case domain.GiftCardVoid:
+			voided[entry.GiftCardID] = true
+		}
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		reversal := &domain.GiftCardTransaction{
+			ID:         uuid.New(),
+			GiftCardID: entry.GiftCardID,
+			OrderID:    &orderID,
+			CreatedAt:  now,
+		}
+		switch entry.Type {
+		case domain.GiftCardRedeem:
+			if !owed[entry.GiftCardID].IsPositive() {
+				continue
+			}
+			reversal.Type = domain.GiftCardRefund
+			reversal.Amount = owed[entry.GiftCardID]
+			owed[entry.GiftCardID] = decimal.Zero
+			err = u.giftCardRepo.Credit(ctx, reversal, nil)
+		case domain.GiftCardIssue, domain.GiftCardReload:
+			if voided[entry.GiftCardID] {
+				continue
+			}
+			reversal.Type = domain.GiftCardVoid
+			reversal.Amount = entry.Amount.Neg()
+			err = u.giftCardRepo.Void(ctx, reversal)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issue creates a card with a fresh code, retrying the rare code collision.
+func (u *giftCardUsecase) issue(ctx context.Context, orderID uuid.UUID, value decimal.Decimal, now time.Time) error {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var code string
+		code, err = generateGiftCardCode()
+		if err != nil {
+			return err
+		}
+
+		card := &domain.GiftCard{
+			ID:            uuid.New(),
+			Code:          code,
+			InitialValue:  value,
+			Balance:       value,
+			ExpiresAt:     u.expiry(now),
+			IssuedOrderID: &orderID,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		err = u.giftCardRepo.Create(ctx, card, &domain.GiftCardTransaction{
+			ID:         uuid.New(),
+			GiftCardID: card.ID,
+			OrderID:    &orderID,
+			Type:       domain.GiftCardIssue,
+			Amount:     value,
+			CreatedAt:  now,
+		})
+		if !errors.Is(err, domain.ErrAlreadyExists) {
+			return err
+		}
+	}
+	return err
+}
+
+func (u *giftCardUsecase) expiry(now time.Time) *time.Time {
+	if u.validity <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(u.validity)
+	return &expiresAt
+}
+
+func giftCardExpired(card *domain.GiftCard, now time.Time) bool {
+	return card.ExpiresAt != nil && !now.Before(*card.ExpiresAt)
+}
+
+// generateGiftCardCode returns a random code formatted as XXXX-XXXX-XXXX-XXXX.
+func generateGiftCardCode() (string, error) {
+	buf := make([]byte, giftCardCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = giftCardAlphabet[int(b)%len(giftCardAlphabet)]
+	}
+	return formatGiftCardCode(string(buf)), nil
+}
+
+// normalizeGiftCardCode accepts codes typed with any case, spacing or dashes.
+func normalizeGiftCardCode(code string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() != giftCardCodeLength {
+		return b.String()
+	}
+	return formatGiftCardCode(b.String())
+}
+
+func formatGiftCardCode(raw string) string {
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGiftCardRepo struct{ mock.Mock }
+
+func (m *mockGiftCardRepo) Create(ctx context.Context, card *domain.GiftCard, issue *domain.GiftCardTransaction) error {
+	args := m.Called(ctx, card, issue)
+	return args.Error(0)
+}
+func (m *mockGiftCardRepo) GetByCode(ctx context.Context, code string) (*domain.GiftCard, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GiftCard), args.Error(1)
+}
+func (m *mockGiftCardRepo) ListByIssuedOrder(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCard, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.GiftCard), args.Error(1)
+}
+func (m *mockGiftCardRepo) ListTransactions(ctx context.Context, cardID uuid.UUID) ([]domain.GiftCardTransaction, error) {
+	args := m.Called(ctx, cardID)
+	return args.Get(0).([]domain.GiftCardTransaction), args.Error(1)
+}
+func (m *mockGiftCardRepo) ListOrderTransactions(ctx context.Context, orderID uuid.UUID) ([]domain.GiftCardTransaction, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.GiftCardTransaction), args.Error(1)
+}
+func (m *mockGiftCardRepo) Credit(ctx context.Context, entry *domain.GiftCardTransaction, expiresAt *time.Time) error {
+	args := m.Called(ctx, entry, expiresAt)
+	return args.Error(0)
+}
+func (m *mockGiftCardRepo) Void(ctx context.Context, entry *domain.GiftCardTransaction) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func TestNormalizeGiftCardCode(t *testing.T) {
+	assert.Equal(t, "ABCD-EFGH-JKLM-NP23", normalizeGiftCardCode(" abcd efgh-jklm np23 "))
+	assert.Equal(t, "SHORT", normalizeGiftCardCode("short"))
+
+	code, err := generateGiftCardCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 19)
+	assert.Equal(t, code, normalizeGiftCardCode(code))
+}
+
+func TestGiftCardUsecase_IssueForOrder(t *testing.T) {
+	giftCardRepo := new(mockGiftCardRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewGiftCardUsecase(giftCardRepo, menuRepo, 365*24*time.Hour)
+
+	orderID := uuid.New()
+	giftID := uuid.New()
+	coffeeID := uuid.New()
+	reloadCard := &domain.GiftCard{ID: uuid.New(), Code: "ABCD-EFGH-JKLM-NP23"}
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory}, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	giftCardRepo.On("ListOrderTransactions", mock.Anything, orderID).Return([]domain.GiftCardTransaction{}, nil)
+	giftCardRepo.On("GetByCode", mock.Anything, reloadCard.Code).Return(reloadCard, nil)
+	giftCardRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.GiftCard) bool {
+		return c.Balance.Equal(decimal.NewFromInt(25)) && c.ExpiresAt != nil && *c.IssuedOrderID == orderID
+	}), mock.AnythingOfType("*domain.GiftCardTransaction")).Return(nil).Twice()
+	giftCardRepo.On("Credit", mock.Anything, mock.MatchedBy(func(e *domain.GiftCardTransaction) bool {
+		return e.Type == domain.GiftCardReload && e.GiftCardID == reloadCard.ID && e.Amount.Equal(decimal.NewFromInt(10))
+	}), mock.AnythingOfType("*time.Time")).Return(nil)
+
+	err := u.IssueForOrder(context.Background(), &domain.Order{
+		ID: orderID,
+		Items: []domain.OrderItem{
+			{MenuItemID: giftID, Quantity: 2, UnitPrice: decimal.NewFromInt(25), LineTotal: decimal.NewFromInt(50)},
+			{MenuItemID: giftID, Quantity: 1, UnitPrice: decimal.NewFromInt(10), LineTotal: decimal.NewFromInt(10), GiftCardCode: reloadCard.Code},
+			{MenuItemID: coffeeID, Quantity: 1, UnitPrice: decimal.NewFromInt(4), LineTotal: decimal.NewFromInt(4)},
+		},
+	})
+
+	assert.NoError(t, err)
+	giftCardRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUsecase_IssueForOrder_ResumesPartialIssue(t *testing.T) {
+	giftCardRepo := new(mockGiftCardRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewGiftCardUsecase(giftCardRepo, menuRepo, 365*24*time.Hour)
+
+	orderID := uuid.New()
+	giftID := uuid.New()
+	reloadCard := &domain.GiftCard{ID: uuid.New(), Code: "ABCD-EFGH-JKLM-NP23"}
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory}, nil)
+	giftCardRepo.On("ListOrderTransactions", mock.Anything, orderID).Return([]domain.GiftCardTransaction{
+		{GiftCardID: uuid.New(), Type: domain.GiftCardIssue, Amount: decimal.RequireFromString("25.00")},
+		{GiftCardID: reloadCard.ID, Type: domain.GiftCardReload, Amount: decimal.NewFromInt(10)},
+	}, nil)
+	giftCardRepo.On("GetByCode", mock.Anything, reloadCard.Code).Return(reloadCard, nil)
+	giftCardRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.GiftCard"), mock.AnythingOfType("*domain.GiftCardTransaction")).Return(nil).Once()
+
+	err := u.IssueForOrder(context.Background(), &domain.Order{
+		ID: orderID,
+		Items: []domain.OrderItem{
+			{MenuItemID: giftID, Quantity: 2, UnitPrice: decimal.NewFromInt(25), LineTotal: decimal.NewFromInt(50)},
+			{MenuItemID: giftID, Quantity: 1, UnitPrice: decimal.NewFromInt(10), LineTotal: decimal.NewFromInt(10), GiftCardCode: reloadCard.Code},
+		},
+	})
+
+	assert.NoError(t, err)
+	giftCardRepo.AssertExpectations(t)
+	giftCardRepo.AssertNotCalled(t, "Credit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGiftCardUsecase_RefundOrder(t *testing.T) {
+	giftCardRepo := new(mockGiftCardRepo)
+	u := NewGiftCardUsecase(giftCardRepo, new(mockMenuRepository), 0)
+
+	orderID := uuid.New()
+	paidWith := uuid.New()
+	sold := uuid.New()
+	giftCardRepo.On("ListOrderTransactions", mock.Anything, orderID).Return([]domain.GiftCardTransaction{
+		{GiftCardID: paidWith, Type: domain.GiftCardRedeem, Amount: decimal.NewFromInt(-6)},
+		{GiftCardID: sold, Type: domain.GiftCardIssue, Amount: decimal.NewFromInt(25)},
+	}, nil)
+	giftCardRepo.On("Credit", mock.Anything, mock.MatchedBy(func(e *domain.GiftCardTransaction) bool {
+		return e.Type == domain.GiftCardRefund && e.GiftCardID == paidWith && e.Amount.Equal(decimal.NewFromInt(6))
+	}), (*time.Time)(nil)).Return(nil)
+	giftCardRepo.On("Void", mock.Anything, mock.MatchedBy(func(e *domain.GiftCardTransaction) bool {
+		return e.Type == domain.GiftCardVoid && e.GiftCardID == sold && e.Amount.Equal(decimal.NewFromInt(-25))
+	})).Return(nil)
+
+	err := u.RefundOrder(context.Background(), orderID)
+
+	assert.NoError(t, err)
+	giftCardRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUsecase_GetByCode(t *testing.T) {
+	giftCardRepo := new(mockGiftCardRepo)
+	u := NewGiftCardUsecase(giftCardRepo, new(mockMenuRepository), 0)
+
+	expiredAt := time.Now().Add(-time.Hour)
+	card := &domain.GiftCard{ID: uuid.New(), Code: "ABCD-EFGH-JKLM-NP23", ExpiresAt: &expiredAt}
+	giftCardRepo.On("GetByCode", mock.Anything, card.Code).Return(card, nil)
+	giftCardRepo.On("GetByCode", mock.Anything, "UNKNOWN").Return(nil, nil)
+	giftCardRepo.On("ListTransactions", mock.Anything, card.ID).Return([]domain.GiftCardTransaction{{Type: domain.GiftCardIssue}}, nil)
+
+	found, err := u.GetByCode(staffCtx(domain.RoleCashier), "abcdefghjklmnp23")
+	assert.NoError(t, err)
+	assert.True(t, found.Expired)
+	assert.Len(t, found.Transactions, 1)
+
+	_, err = u.GetByCode(staffCtx(domain.RoleCashier), "unknown")
+	assert.ErrorIs(t, err, ErrGiftCardNotFound)
+}
+
+func TestGiftCardUsecase_RefundOrder_AfterVoidRefund(t *testing.T) {
+	giftCardRepo := new(mockGiftCardRepo)
+	u := NewGiftCardUsecase(giftCardRepo, new(mockMenuRepository), 0)
+
+	orderID := uuid.New()
+	paidWith := uuid.New()
+	giftCardRepo.On("ListOrderTransactions", mock.Anything, orderID).Return([]domain.GiftCardTransaction{
+		{GiftCardID: paidWith, Type: domain.GiftCardRedeem, Amount: decimal.NewFromInt(-6)},
+		{GiftCardID: paidWith, Type: domain.GiftCardRefund, Amount: decimal.NewFromInt(4)},
+	}, nil).Once()
+	giftCardRepo.On("Credit", mock.Anything, mock.MatchedBy(func(e *domain.GiftCardTransaction) bool {
+		return e.Type == domain.GiftCardRefund && e.GiftCardID == paidWith && e.Amount.Equal(decimal.NewFromInt(2))
+	}), (*time.Time)(nil)).Return(nil).Once()
+	assert.NoError(t, u.RefundOrder(context.Background(), orderID))
+
+	// Once everything has been given back, running it again does nothing.
+	giftCardRepo.On("ListOrderTransactions", mock.Anything, orderID).Return([]domain.GiftCardTransaction{
+		{GiftCardID: paidWith, Type: domain.GiftCardRedeem, Amount: decimal.NewFromInt(-6)},
+		{GiftCardID: paidWith, Type: domain.GiftCardRefund, Amount: decimal.NewFromInt(6)},
+	}, nil).Once()
+	assert.NoError(t, u.RefundOrder(context.Background(), orderID))
+	giftCardRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUsecase_Lookups_Forbidden(t *testing.T) {
+	u := NewGiftCardUsecase(new(mockGiftCardRepo), new(mockMenuRepository), 0)
+
+	_, err := u.GetByCode(context.Background(), "abcdefghjklmnp23")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = u.ListForOrder(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
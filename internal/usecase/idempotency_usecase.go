@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// idempotencyPurgeInterval is how often expired keys are cleared out.
+	idempotencyPurgeInterval = time.Hour
+	// idempotencyLease is how long a request holds its key before a retry may
+	// take it over. It is well past the server's 30 second write timeout, so
+	// a request still holding the key by then has been lost.
+	idempotencyLease = time.Minute
+)
+
+type idempotencyUsecase struct {
+	repo domain.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewIdempotencyUsecase keeps idempotency keys for ttl after first use.
+func NewIdempotencyUsecase(repo domain.IdempotencyRepository, ttl time.Duration) domain.IdempotencyUsecase {
+	return &idempotencyUsecase{repo: repo, ttl: ttl, now: time.Now}
+}
+
+func (u *idempotencyUsecase) Begin(ctx context.Context, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	if !validIdempotencyKey(key) {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+	now := u.now()
+	if err := u.purge(ctx, now); err != nil {
+		return nil, err
+	}
+
+	record := &domain.IdempotencyRecord{
+		OwnerID:     identity.StaffID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LeaseID:     uuid.New(),
+		LockedUntil: now.Add(idempotencyLease),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(u.ttl),
+	}
+	if identity.APIKeyID != nil {
+		record.OwnerID = *identity.APIKeyID
+	}
+
+	existing, err := u.repo.Reserve(ctx, record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return record, nil
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	if existing.CompletedAt == nil {
+		return nil, domain.ErrIdempotencyInProgress
+	}
+	return existing, nil
+}
+
+func (u *idempotencyUsecase) Complete(ctx context.Context, record *domain.IdempotencyRecord, statusCode int, headers map[string]string, response []byte) error {
+	record.Headers = nil
+	if len(headers) > 0 {
+		encoded, err := json.Marshal(headers)
+		if err != nil {
+			return err
+		}
+		record.Headers = encoded
+	}
+	now := u.now()
+	record.StatusCode = &statusCode
+	record.Response = response
+	record.CompletedAt = &now
+	return u.repo.Complete(ctx, record)
+}
+
+func (u *idempotencyUsecase) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return u.repo.Release(ctx, record)
+}
+
+// purge deletes expired keys at most once per idempotencyPurgeInterval.
+func (u *idempotencyUsecase) purge(ctx context.Context, now time.Time) error {
+	u.mu.Lock()
+	if now.Sub(u.lastPurge) < idempotencyPurgeInterval {
+		u.mu.Unlock()
+		return nil
+	}
+	u.lastPurge = now
+	u.mu.Unlock()
+	return u.repo.DeleteExpired(ctx, now)
+}
+
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
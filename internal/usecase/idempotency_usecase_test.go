@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockIdempotencyRepo struct{ mock.Mock }
+
+func (m *mockIdempotencyRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	args := m.Called(ctx, record)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Error(1)
+}
+func (m *mockIdempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+func (m *mockIdempotencyRepo) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+func (m *mockIdempotencyRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+func TestIdempotencyUsecase_Begin(t *testing.T) {
+	repo := new(mockIdempotencyRepo)
+	u := NewIdempotencyUsecase(repo, 24*time.Hour)
+	ctx := managerCtx()
+	identity, _ := domain.IdentityFromContext(ctx)
+
+	repo.On("DeleteExpired", mock.Anything, mock.Anything).Return(nil).Once()
+	repo.On("Reserve", mock.Anything, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+		return r.OwnerID == identity.StaffID && r.Key == "abc-1" && r.ExpiresAt.Sub(r.CreatedAt) == 24*time.Hour &&
+			r.LeaseID != uuid.Nil && r.LockedUntil.Sub(r.CreatedAt) == idempotencyLease
+	})).Return(nil, nil).Once()
+
+	record, err := u.Begin(ctx, "abc-1", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, record.CompletedAt)
+
+	repo.On("Complete", mock.Anything, record).Return(nil)
+	assert.NoError(t, u.Complete(ctx, record, 201, map[string]string{"ETag": `"1"`}, []byte(`{"id":"1"}`)))
+	assert.Equal(t, 201, *record.StatusCode)
+	assert.JSONEq(t, `{"ETag":"\"1\""}`, string(record.Headers))
+	assert.NotNil(t, record.CompletedAt)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyUsecase_Release(t *testing.T) {
+	repo := new(mockIdempotencyRepo)
+	u := NewIdempotencyUsecase(repo, time.Hour)
+	record := &domain.IdempotencyRecord{OwnerID: uuid.New(), Key: "k", LeaseID: uuid.New()}
+
+	repo.On("Release", mock.Anything, record).Return(nil).Once()
+	assert.NoError(t, u.Release(managerCtx(), record))
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyUsecase_Begin_ExistingKey(t *testing.T) {
+	repo := new(mockIdempotencyRepo)
+	u := NewIdempotencyUsecase(repo, time.Hour)
+	status := 201
+	done := time.Now()
+	completed := &domain.IdempotencyRecord{Key: "k", Fingerprint: "fp", StatusCode: &status, Response: []byte(`{}`), CompletedAt: &done}
+
+	repo.On("DeleteExpired", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Reserve", mock.Anything, mock.Anything).Return(completed, nil).Times(2)
+	repo.On("Reserve", mock.Anything, mock.Anything).Return(&domain.IdempotencyRecord{Key: "k", Fingerprint: "fp"}, nil).Once()
+	repo.On("Reserve", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+	record, err := u.Begin(managerCtx(), "k", "fp")
+	assert.NoError(t, err)
+	assert.Equal(t, completed, record)
+
+	_, err = u.Begin(managerCtx(), "k", "other")
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyReused)
+	_, err = u.Begin(managerCtx(), "k", "fp")
+	assert.ErrorIs(t, err, domain.ErrIdempotencyInProgress)
+	_, err = u.Begin(managerCtx(), "k", "fp")
+	assert.ErrorIs(t, err, domain.ErrIdempotencyInProgress)
+	repo.AssertNumberOfCalls(t, "DeleteExpired", 1)
+}
+
+func TestIdempotencyUsecase_Begin_ScopedToAPIKey(t *testing.T) {
+	repo := new(mockIdempotencyRepo)
+	u := NewIdempotencyUsecase(repo, time.Hour)
+	keyID := uuid.New()
+	ctx := domain.WithIdentity(context.Background(), &domain.Identity{APIKeyID: &keyID, Scopes: []string{"orders:write"}})
+
+	repo.On("DeleteExpired", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Reserve", mock.Anything, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+		return r.OwnerID == keyID
+	})).Return(nil, nil)
+
+	_, err := u.Begin(ctx, "k", "fp")
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyUsecase_Begin_InvalidKey(t *testing.T) {
+	u := NewIdempotencyUsecase(new(mockIdempotencyRepo), time.Hour)
+
+	for _, key := range []string{"has space", "tab\tkey", string(make([]byte, 256)), "ключ"} {
+		_, err := u.Begin(managerCtx(), key, "fp")
+		assert.ErrorIs(t, err, domain.ErrInvalidIdempotencyKey, key)
+	}
+	_, err := u.Begin(context.Background(), "k", "fp")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidIngredient    = errors.New("ingredient name and unit are required")
+	ErrInvalidStockQuantity = errors.New("stock quantity must be greater than zero")
+	ErrInvalidMovementType  = errors.New("invalid stock movement type")
+	ErrInvalidCountQuantity = errors.New("counted quantity cannot be negative")
+	ErrStockCountApplied    = errors.New("stock count has already been applied")
+	ErrEmptyStockCount      = errors.New("stock count has no counted quantities")
+	ErrStaleStockCount      = errors.New("a later stock count has already been applied")
+)
+
+type inventoryUsecase struct {
+	inventoryRepo domain.InventoryRepository
+	menuRepo      domain.MenuItemRepository
+}
+
+func NewInventoryUsecase(inventoryRepo domain.InventoryRepository, menuRepo domain.MenuItemRepository) domain.InventoryUsecase {
+	return &inventoryUsecase{
+		inventoryRepo: inventoryRepo,
+		menuRepo:      menuRepo,
+	}
+}
+
+func (u *inventoryUsecase) CreateIngredient(ctx context.Context, ingredient *domain.Ingredient) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	if ingredient.Name == "" || ingredient.Unit == "" {
+		return ErrInvalidIngredient
+	}
+	if ingredient.UnitCost.IsNegative() {
+		return ErrInvalidIngredient
+	}
+
+	now := time.Now()
+	ingredient.ID = uuid.New()
+	ingredient.BaselineAt = now
+	ingredient.CreatedAt = now
+	ingredient.UpdatedAt = now
+	return u.inventoryRepo.CreateIngredient(ctx, ingredient)
+}
+
+func (u *inventoryUsecase) ListIngredients(ctx context.Context) ([]domain.Ingredient, error) {
+	if err := domain.Authorize(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	return u.inventoryRepo.ListIngredients(ctx)
+}
+
+func (u *inventoryUsecase) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]domain.RecipeItem, error) {
+	if err := domain.Authorize(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	return u.inventoryRepo.GetRecipe(ctx, menuItemID)
+}
+
+func (u *inventoryUsecase) SetRecipe(ctx context.Context, menuItemID uuid.UUID, items []domain.RecipeItem) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	menuItem, err := u.menuRepo.GetByID(ctx, menuItemID)
+	if err != nil {
+		return err
+	}
+	if menuItem == nil {
+		return domain.ErrNotFound
+	}
+
+	for i := range items {
+		if !items[i].Quantity.IsPositive() {
+			return ErrInvalidStockQuantity
+		}
+		if err := u.ensureIngredient(ctx, items[i].IngredientID); err != nil {
+			return err
+		}
+		items[i].MenuItemID = menuItemID
+	}
+
+	return u.inventoryRepo.ReplaceRecipe(ctx, menuItemID, items)
+}
+
+func (u *inventoryUsecase) RecordMovement(ctx context.Context, movement *domain.StockMovement) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	if movement.Type != domain.StockMovementReceipt && movement.Type != domain.StockMovementWaste {
+		return ErrInvalidMovementType
+	}
+	if !movement.Quantity.IsPositive() {
+		return ErrInvalidStockQuantity
+	}
+	if movement.UnitCost.IsNegative() {
+		return ErrInvalidStockQuantity
+	}
+	if err := u.ensureIngredient(ctx, movement.IngredientID); err != nil {
+		return err
+	}
+
+	movement.ID = uuid.New()
+	if movement.OccurredAt.IsZero() {
+		movement.OccurredAt = time.Now()
+	}
+	return u.inventoryRepo.CreateMovement(ctx, movement)
+}
+
+func (u *inventoryUsecase) StartStockCount(ctx context.Context, count *domain.StockCount) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	count.ID = uuid.New()
+	count.Status = domain.StockCountStatusOpen
+	count.CountedAt = time.Now()
+	count.AppliedAt = nil
+	count.Lines = nil
+	return u.inventoryRepo.CreateStockCount(ctx, count)
+}
+
+func (u *inventoryUsecase) GetStockCount(ctx context.Context, id uuid.UUID) (*domain.StockCount, error) {
+	if err := domain.Authorize(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	return u.inventoryRepo.GetStockCount(ctx, id)
+}
+
+func (u *inventoryUsecase) RecordCountedQuantities(ctx context.Context, countID uuid.UUID, lines []domain.StockCountLine) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	count, err := u.openStockCount(ctx, countID)
+	if err != nil {
+		return err
+	}
+
+	for i := range lines {
+		if lines[i].CountedQuantity.IsNegative() {
+			return ErrInvalidCountQuantity
+		}
+		if err := u.ensureIngredient(ctx, lines[i].IngredientID); err != nil {
+			return err
+		}
+		lines[i].StockCountID = count.ID
+	}
+
+	return u.inventoryRepo.UpsertStockCountLines(ctx, count.ID, lines)
+}
+
+// VarianceReport compares every counted quantity with the stock expected at
+// the time the count was started.
+func (u *inventoryUsecase) VarianceReport(ctx context.Context, countID uuid.UUID) (*domain.StockVarianceReport, error) {
+	if err := domain.Authorize(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	count, err := u.inventoryRepo.GetStockCount(ctx, countID)
+	if err != nil {
+		return nil, err
+	}
+	if count == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	levels, err := u.inventoryRepo.GetStockLevels(ctx, count.CountedAt)
+	if err != nil {
+		return nil, err
+	}
+	levelByIngredient := make(map[uuid.UUID]domain.StockLevel, len(levels))
+	for _, level := range levels {
+		levelByIngredient[level.IngredientID] = level
+	}
+
+	report := &domain.StockVarianceReport{
+		StockCountID:      count.ID,
+		Status:            count.Status,
+		CountedAt:         count.CountedAt,
+		Lines:             make([]domain.StockVarianceLine, 0, len(count.Lines)),
+		TotalVarianceCost: decimal.Zero,
+	}
+	for _, line := range count.Lines {
+		level, ok := levelByIngredient[line.IngredientID]
+		if !ok {
+			continue
+		}
+		expected := level.Expected()
+		variance := line.CountedQuantity.Sub(expected)
+		varianceCost := variance.Mul(level.UnitCost).Round(2)
+		report.Lines = append(report.Lines, domain.StockVarianceLine{
+			StockLevel:   level,
+			Expected:     expected,
+			Counted:      line.CountedQuantity,
+			Variance:     variance,
+			VarianceCost: varianceCost,
+		})
+		report.TotalVarianceCost = report.TotalVarianceCost.Add(varianceCost)
+	}
+
+	return report, nil
+}
+
+func (u *inventoryUsecase) ApplyStockCount(ctx context.Context, countID uuid.UUID) error {
+	if err := domain.Authorize(ctx, domain.PermInventoryWrite); err != nil {
+		return err
+	}
+	count, err := u.openStockCount(ctx, countID)
+	if err != nil {
+		return err
+	}
+	if len(count.Lines) == 0 {
+		return ErrEmptyStockCount
+	}
+
+	err = u.inventoryRepo.ApplyStockCount(ctx, count.ID, time.Now())
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrStockCountApplied
+	case errors.Is(err, domain.ErrConflict):
+		return ErrStaleStockCount
+	}
+	return err
+}
+
+func (u *inventoryUsecase) openStockCount(ctx context.Context, countID uuid.UUID) (*domain.StockCount, error) {
+	count, err := u.inventoryRepo.GetStockCount(ctx, countID)
+	if err != nil {
+		return nil, err
+	}
+	if count == nil {
+		return nil, domain.ErrNotFound
+	}
+	if count.Status != domain.StockCountStatusOpen {
+		return nil, ErrStockCountApplied
+	}
+	return count, nil
+}
+
+func (u *inventoryUsecase) ensureIngredient(ctx context.Context, id uuid.UUID) error {
+	ingredient, err := u.inventoryRepo.GetIngredientByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ingredient == nil {
+		return domain.ErrNotFound
+	}
+	return nil
+}
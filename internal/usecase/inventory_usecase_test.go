@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockInventoryRepo struct{ mock.Mock }
+
+func (m *mockInventoryRepo) CreateIngredient(ctx context.Context, ingredient *domain.Ingredient) error {
+	args := m.Called(ctx, ingredient)
+	return args.Error(0)
+}
+func (m *mockInventoryRepo) GetIngredientByID(ctx context.Context, id uuid.UUID) (*domain.Ingredient, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Ingredient), args.Error(1)
+}
+func (m *mockInventoryRepo) ListIngredients(ctx context.Context) ([]domain.Ingredient, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Ingredient), args.Error(1)
+}
+func (m *mockInventoryRepo) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]domain.RecipeItem, error) {
+	args := m.Called(ctx, menuItemID)
+	return args.Get(0).([]domain.RecipeItem), args.Error(1)
+}
+func (m *mockInventoryRepo) GetMenuItemCost(ctx context.Context, menuItemID uuid.UUID) (decimal.Decimal, error) {
+	args := m.Called(ctx, menuItemID)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+func (m *mockInventoryRepo) ReplaceRecipe(ctx context.Context, menuItemID uuid.UUID, items []domain.RecipeItem) error {
+	args := m.Called(ctx, menuItemID, items)
+	return args.Error(0)
+}
+func (m *mockInventoryRepo) CreateMovement(ctx context.Context, movement *domain.StockMovement) error {
+	args := m.Called(ctx, movement)
+	return args.Error(0)
+}
+func (m *mockInventoryRepo) CreateStockCount(ctx context.Context, count *domain.StockCount) error {
+	args := m.Called(ctx, count)
+	return args.Error(0)
+}
+func (m *mockInventoryRepo) GetStockCount(ctx context.Context, id uuid.UUID) (*domain.StockCount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockCount), args.Error(1)
+}
+func (m *mockInventoryRepo) UpsertStockCountLines(ctx context.Context, countID uuid.UUID, lines []domain.StockCountLine) error {
+	args := m.Called(ctx, countID, lines)
+	return args.Error(0)
+}
+func (m *mockInventoryRepo) GetStockLevels(ctx context.Context, asOf time.Time) ([]domain.StockLevel, error) {
+	args := m.Called(ctx, asOf)
+	return args.Get(0).([]domain.StockLevel), args.Error(1)
+}
+func (m *mockInventoryRepo) ApplyStockCount(ctx context.Context, countID uuid.UUID, appliedAt time.Time) error {
+	args := m.Called(ctx, countID, appliedAt)
+	return args.Error(0)
+}
+
+func TestInventoryUsecase_RecordMovement_Validation(t *testing.T) {
+	inventoryRepo := new(mockInventoryRepo)
+	u := NewInventoryUsecase(inventoryRepo, new(mockMenuRepository))
+
+	err := u.RecordMovement(managerCtx(), &domain.StockMovement{Type: "theft", Quantity: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, ErrInvalidMovementType)
+
+	err = u.RecordMovement(managerCtx(), &domain.StockMovement{Type: domain.StockMovementWaste, Quantity: decimal.Zero})
+	assert.ErrorIs(t, err, ErrInvalidStockQuantity)
+}
+
+func TestInventoryUsecase_VarianceReport(t *testing.T) {
+	inventoryRepo := new(mockInventoryRepo)
+	u := NewInventoryUsecase(inventoryRepo, new(mockMenuRepository))
+
+	countID := uuid.New()
+	milkID := uuid.New()
+	countedAt := time.Now()
+	inventoryRepo.On("GetStockCount", mock.Anything, countID).Return(&domain.StockCount{
+		ID:        countID,
+		Status:    domain.StockCountStatusOpen,
+		CountedAt: countedAt,
+		Lines:     []domain.StockCountLine{{StockCountID: countID, IngredientID: milkID, CountedQuantity: decimal.NewFromInt(7)}},
+	}, nil)
+	inventoryRepo.On("GetStockLevels", mock.Anything, countedAt).Return([]domain.StockLevel{{
+		IngredientID: milkID,
+		Name:         "Milk",
+		UnitCost:     decimal.NewFromFloat(1.50),
+		Opening:      decimal.NewFromInt(10),
+		Received:     decimal.NewFromInt(5),
+		Sold:         decimal.NewFromInt(6),
+		Waste:        decimal.NewFromInt(1),
+	}}, nil)
+
+	report, err := u.VarianceReport(managerCtx(), countID)
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Lines, 1)
+	assert.Equal(t, "8", report.Lines[0].Expected.String())
+	assert.Equal(t, "-1", report.Lines[0].Variance.String())
+	assert.Equal(t, "-1.50", report.TotalVarianceCost.StringFixed(2))
+}
+
+func TestInventoryUsecase_ApplyStockCount(t *testing.T) {
+	inventoryRepo := new(mockInventoryRepo)
+	u := NewInventoryUsecase(inventoryRepo, new(mockMenuRepository))
+
+	countID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, countID).Return(&domain.StockCount{
+		ID:     countID,
+		Status: domain.StockCountStatusOpen,
+		Lines:  []domain.StockCountLine{{StockCountID: countID, IngredientID: uuid.New(), CountedQuantity: decimal.NewFromInt(3)}},
+	}, nil)
+	inventoryRepo.On("ApplyStockCount", mock.Anything, countID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := u.ApplyStockCount(managerCtx(), countID)
+	assert.NoError(t, err)
+	inventoryRepo.AssertExpectations(t)
+}
+
+func TestInventoryUsecase_ApplyStockCount_Errors(t *testing.T) {
+	inventoryRepo := new(mockInventoryRepo)
+	u := NewInventoryUsecase(inventoryRepo, new(mockMenuRepository))
+
+	appliedID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, appliedID).Return(&domain.StockCount{ID: appliedID, Status: domain.StockCountStatusApplied}, nil)
+	assert.ErrorIs(t, u.ApplyStockCount(managerCtx(), appliedID), ErrStockCountApplied)
+
+	emptyID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, emptyID).Return(&domain.StockCount{ID: emptyID, Status: domain.StockCountStatusOpen}, nil)
+	assert.ErrorIs(t, u.ApplyStockCount(managerCtx(), emptyID), ErrEmptyStockCount)
+
+	racedID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, racedID).Return(&domain.StockCount{
+		ID:     racedID,
+		Status: domain.StockCountStatusOpen,
+		Lines:  []domain.StockCountLine{{IngredientID: uuid.New(), CountedQuantity: decimal.NewFromInt(1)}},
+	}, nil)
+	inventoryRepo.On("ApplyStockCount", mock.Anything, racedID, mock.AnythingOfType("time.Time")).Return(sql.ErrNoRows)
+	assert.ErrorIs(t, u.ApplyStockCount(managerCtx(), racedID), ErrStockCountApplied)
+
+	staleID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, staleID).Return(&domain.StockCount{
+		ID:     staleID,
+		Status: domain.StockCountStatusOpen,
+		Lines:  []domain.StockCountLine{{IngredientID: uuid.New(), CountedQuantity: decimal.NewFromInt(1)}},
+	}, nil)
+	inventoryRepo.On("ApplyStockCount", mock.Anything, staleID, mock.AnythingOfType("time.Time")).Return(domain.ErrConflict)
+	assert.ErrorIs(t, u.ApplyStockCount(managerCtx(), staleID), ErrStaleStockCount)
+
+	missingID := uuid.New()
+	inventoryRepo.On("GetStockCount", mock.Anything, missingID).Return(nil, nil)
+	assert.ErrorIs(t, u.ApplyStockCount(managerCtx(), missingID), domain.ErrNotFound)
+}
+
+func TestInventoryUsecase_SetRecipe_MenuItemNotFound(t *testing.T) {
+	inventoryRepo := new(mockInventoryRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewInventoryUsecase(inventoryRepo, menuRepo)
+
+	menuID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(nil, nil)
+
+	err := u.SetRecipe(managerCtx(), menuID, []domain.RecipeItem{{IngredientID: uuid.New(), Quantity: decimal.NewFromInt(1)}})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	inventoryRepo.AssertNotCalled(t, "ReplaceRecipe")
+}
+
+func TestInventoryUsecase_RequiresInventoryPermissions(t *testing.T) {
+	u := NewInventoryUsecase(new(mockInventoryRepo), new(mockMenuRepository))
+	barista := staffCtx(domain.RoleBarista)
+
+	_, err := u.ListIngredients(context.Background())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.ErrorIs(t, u.CreateIngredient(barista, &domain.Ingredient{Name: "Milk", Unit: "ml"}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.SetRecipe(barista, uuid.New(), nil), domain.ErrForbidden)
+	assert.ErrorIs(t, u.RecordMovement(barista, &domain.StockMovement{Type: domain.StockMovementWaste, Quantity: decimal.NewFromInt(1)}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.StartStockCount(barista, &domain.StockCount{}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.ApplyStockCount(barista, uuid.New()), domain.ErrForbidden)
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// LoyaltyConfig controls how points are earned and what they are worth.
+type LoyaltyConfig struct {
+	// PointsPerUnit is the number of points earned per currency unit spent.
+	PointsPerUnit decimal.Decimal
+	// PointValue is the discount granted per redeemed point.
+	PointValue decimal.Decimal
+	// ExcludedCategories never earn points.
+	ExcludedCategories []string
+}
+
+// ParseLoyaltyConfig builds a LoyaltyConfig from its string settings. Excluded
+// categories are a comma-separated list.
+func ParseLoyaltyConfig(pointsPerUnit, pointValue, excludedCategories string) (LoyaltyConfig, error) {
+	rate, err := decimal.NewFromString(pointsPerUnit)
+	if err != nil || rate.IsNegative() {
+		return LoyaltyConfig{}, fmt.Errorf("invalid loyalty points per unit %q", pointsPerUnit)
+	}
+	value, err := decimal.NewFromString(pointValue)
+	if err != nil || value.IsNegative() {
+		return LoyaltyConfig{}, fmt.Errorf("invalid loyalty point value %q", pointValue)
+	}
+
+	cfg := LoyaltyConfig{PointsPerUnit: rate, PointValue: value}
+	for _, category := range strings.Split(excludedCategories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			cfg.ExcludedCategories = append(cfg.ExcludedCategories, category)
+		}
+	}
+	return cfg, nil
+}
+
+type loyaltyUsecase struct {
+	loyaltyRepo  domain.LoyaltyRepository
+	customerRepo domain.CustomerRepository
+	menuRepo     domain.MenuItemRepository
+	config       LoyaltyConfig
+	excluded     map[string]bool
+}
+
+func NewLoyaltyUsecase(loyaltyRepo domain.LoyaltyRepository, customerRepo domain.CustomerRepository, menuRepo domain.MenuItemRepository, config LoyaltyConfig) domain.LoyaltyUsecase {
+	// Gift cards are stored value, not a purchase, so they never earn points
+	// either.
+	excluded := map[string]bool{strings.ToLower(domain.GiftCardCategory): true}
+	for _, category := range config.ExcludedCategories {
+		excluded[strings.ToLower(category)] = true
+	}
+	return &loyaltyUsecase{
+		loyaltyRepo:  loyaltyRepo,
+		customerRepo: customerRepo,
+		menuRepo:     menuRepo,
+		config:       config,
+		excluded:     excluded,
+	}
+}
+
+func (u *loyaltyUsecase) GetAccount(ctx context.Context, customerID uuid.UUID) (*domain.LoyaltyAccount, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	balance, err := u.loyaltyRepo.Balance(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := u.loyaltyRepo.ListEntries(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []domain.LoyaltyEntry{}
+	}
+
+	return &domain.LoyaltyAccount{CustomerID: customerID, Balance: balance, Entries: entries}, nil
+}
+
+func (u *loyaltyUsecase) RedemptionValue(points int64) decimal.Decimal {
+	return u.config.PointValue.Mul(decimal.NewFromInt(points)).Round(2)
+}
+
+func (u *loyaltyUsecase) RedeemForOrder(ctx context.Context, order *domain.Order) error {
+	if order.RedeemedPoints <= 0 || order.CustomerID == nil {
+		return nil
+	}
+
+	orderID := order.ID
+	return u.loyaltyRepo.Redeem(ctx, &domain.LoyaltyEntry{
+		ID:         uuid.New(),
+		CustomerID: *order.CustomerID,
+		OrderID:    &orderID,
+		Type:       domain.LoyaltyEntryRedeem,
+		Points:     -order.RedeemedPoints,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// EarnForOrder credits points for the eligible part of a paid order. Lines in
+// excluded categories earn nothing and any discount reduces the earning base.
+func (u *loyaltyUsecase) EarnForOrder(ctx context.Context, order *domain.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+	points, err := u.orderPoints(ctx, order)
+	if err != nil || points <= 0 {
+		return err
+	}
+
+	orderID := order.ID
+	return u.loyaltyRepo.AddEntry(ctx, &domain.LoyaltyEntry{
+		ID:         uuid.New(),
+		CustomerID: *order.CustomerID,
+		OrderID:    &orderID,
+		Type:       domain.LoyaltyEntryEarn,
+		Points:     points,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// orderPoints is what an order earns from its lines that are not voided.
+func (u *loyaltyUsecase) orderPoints(ctx context.Context, order *domain.Order) (int64, error) {
+	eligible := decimal.Zero
+	for _, item := range order.Items {
+		if item.VoidedAt != nil {
+			continue
+		}
+		menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return 0, err
+		}
+		if menuItem != nil && u.excluded[strings.ToLower(menuItem.Category)] {
+			continue
+		}
+		eligible = eligible.Add(item.LineTotal)
+	}
+	eligible = eligible.Sub(order.Discount)
+	if !eligible.IsPositive() {
+		return 0, nil
+	}
+	return eligible.Mul(u.config.PointsPerUnit).Floor().IntPart(), nil
+}
+
+// ReverseLine takes the order's points down to what it earns without the
+// voided line. Rounding is applied to the order as a whole, the way
+// EarnForOrder works them out.
+func (u *loyaltyUsecase) ReverseLine(ctx context.Context, order *domain.Order, itemID uuid.UUID) (*domain.LoyaltyEntry, error) {
+	if order.CustomerID == nil {
+		return nil, nil
+	}
+	entries, err := u.loyaltyRepo.ListOrderEntries(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var earned int64
+	for _, entry := range entries {
+		switch entry.Type {
+		case domain.LoyaltyEntryReversal:
+			return nil, nil
+		case domain.LoyaltyEntryEarn, domain.LoyaltyEntryVoid:
+			earned += entry.Points
+		}
+	}
+	if earned <= 0 {
+		return nil, nil
+	}
+	points, err := u.orderPoints(ctx, order)
+	if err != nil || points >= earned {
+		return nil, err
+	}
+
+	orderID := order.ID
+	return &domain.LoyaltyEntry{
+		ID:          uuid.New(),
+		CustomerID:  *order.CustomerID,
+		OrderID:     &orderID,
+		OrderItemID: &itemID,
+		Type:        domain.LoyaltyEntryVoid,
+		Points:      points - earned,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// ReverseOrder offsets every ledger entry recorded against an order, returning
+// redeemed points and taking back earned ones.
+func (u *loyaltyUsecase) ReverseOrder(ctx context.Context, orderID uuid.UUID) error {
+	entries, err := u.loyaltyRepo.ListOrderEntries(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	var net int64
+	for _, entry := range entries {
+		if entry.Type == domain.LoyaltyEntryReversal {
+			return nil
+		}
+		net += entry.Points
+	}
+	if net == 0 {
+		return nil
+	}
+
+	return u.loyaltyRepo.AddEntry(ctx, &domain.LoyaltyEntry{
+		ID:         uuid.New(),
+		CustomerID: entries[0].CustomerID,
+		OrderID:    &orderID,
+		Type:       domain.LoyaltyEntryReversal,
+		Points:     -net,
+		CreatedAt:  time.Now(),
+	})
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLoyaltyRepo struct{ mock.Mock }
+
+func (m *mockLoyaltyRepo) Balance(ctx context.Context, customerID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *mockLoyaltyRepo) ListEntries(ctx context.Context, customerID uuid.UUID) ([]domain.LoyaltyEntry, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).([]domain.LoyaltyEntry), args.Error(1)
+}
+func (m *mockLoyaltyRepo) ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]domain.LoyaltyEntry, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.LoyaltyEntry), args.Error(1)
+}
+func (m *mockLoyaltyRepo) AddEntry(ctx context.Context, entry *domain.LoyaltyEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+func (m *mockLoyaltyRepo) Redeem(ctx context.Context, entry *domain.LoyaltyEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func testLoyaltyConfig() LoyaltyConfig {
+	return LoyaltyConfig{
+		PointsPerUnit:      decimal.NewFromInt(1),
+		PointValue:         decimal.NewFromFloat(0.01),
+		ExcludedCategories: []string{"Merchandise"},
+	}
+}
+
+func TestParseLoyaltyConfig(t *testing.T) {
+	cfg, err := ParseLoyaltyConfig("2", "0.05", "Merchandise, Gift Cards,")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", cfg.PointsPerUnit.String())
+	assert.Equal(t, "0.05", cfg.PointValue.String())
+	assert.Equal(t, []string{"Merchandise", "Gift Cards"}, cfg.ExcludedCategories)
+
+	_, err = ParseLoyaltyConfig("abc", "0.01", "")
+	assert.Error(t, err)
+	_, err = ParseLoyaltyConfig("1", "-1", "")
+	assert.Error(t, err)
+}
+
+func TestLoyaltyUsecase_EarnForOrder_SkipsExcludedCategories(t *testing.T) {
+	loyaltyRepo := new(mockLoyaltyRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, testLoyaltyConfig())
+
+	customerID := uuid.New()
+	coffeeID := uuid.New()
+	mugID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, mugID).Return(&domain.MenuItem{ID: mugID, Category: "merchandise"}, nil)
+	giftID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory}, nil)
+	loyaltyRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Type == domain.LoyaltyEntryEarn && e.Points == 8 && e.CustomerID == customerID
+	})).Return(nil)
+
+	// Gift cards never earn points, even when not listed as excluded.
+	err := u.EarnForOrder(context.Background(), &domain.Order{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Discount:   decimal.NewFromFloat(0.50),
+		Items: []domain.OrderItem{
+			{MenuItemID: coffeeID, LineTotal: decimal.NewFromFloat(9.25)},
+			{MenuItemID: mugID, LineTotal: decimal.NewFromFloat(15)},
+			{MenuItemID: giftID, LineTotal: decimal.NewFromFloat(50)},
+		},
+	})
+
+	assert.NoError(t, err)
+	loyaltyRepo.AssertExpectations(t)
+}
+
+func TestLoyaltyUsecase_ReverseOrder(t *testing.T) {
+	loyaltyRepo := new(mockLoyaltyRepo)
+	u := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), new(mockMenuRepository), testLoyaltyConfig())
+
+	orderID := uuid.New()
+	customerID := uuid.New()
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, orderID).Return([]domain.LoyaltyEntry{
+		{CustomerID: customerID, Type: domain.LoyaltyEntryRedeem, Points: -100},
+		{CustomerID: customerID, Type: domain.LoyaltyEntryEarn, Points: 12},
+	}, nil)
+	loyaltyRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Type == domain.LoyaltyEntryReversal && e.Points == 88 && *e.OrderID == orderID
+	})).Return(nil)
+
+	err := u.ReverseOrder(context.Background(), orderID)
+
+	assert.NoError(t, err)
+	loyaltyRepo.AssertExpectations(t)
+}
+
+func TestLoyaltyUsecase_ReverseLine(t *testing.T) {
+	loyaltyRepo := new(mockLoyaltyRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, testLoyaltyConfig())
+
+	customerID, coffeeID := uuid.New(), uuid.New()
+	now := time.Now()
+	order := &domain.Order{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Items: []domain.OrderItem{
+			{ID: uuid.New(), MenuItemID: coffeeID, LineTotal: decimal.NewFromFloat(9.25), VoidedAt: &now},
+			{ID: uuid.New(), MenuItemID: coffeeID, LineTotal: decimal.NewFromFloat(5.50)},
+		},
+	}
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, order.ID).Return([]domain.LoyaltyEntry{
+		{CustomerID: customerID, Type: domain.LoyaltyEntryRedeem, Points: -100},
+		{CustomerID: customerID, Type: domain.LoyaltyEntryEarn, Points: 14},
+	}, nil).Once()
+
+	entry, err := u.ReverseLine(context.Background(), order, order.Items[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoyaltyEntryVoid, entry.Type)
+	assert.Equal(t, int64(-9), entry.Points)
+	assert.Equal(t, order.Items[0].ID, *entry.OrderItemID)
+
+	// Once the order's points are down to what is left, nothing more is taken.
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, order.ID).Return([]domain.LoyaltyEntry{
+		{CustomerID: customerID, Type: domain.LoyaltyEntryEarn, Points: 14},
+		{CustomerID: customerID, Type: domain.LoyaltyEntryVoid, Points: -9},
+	}, nil).Once()
+	entry, err = u.ReverseLine(context.Background(), order, order.Items[0].ID)
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestLoyaltyUsecase_ReverseOrder_AlreadyReversed(t *testing.T) {
+	loyaltyRepo := new(mockLoyaltyRepo)
+	u := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), new(mockMenuRepository), testLoyaltyConfig())
+
+	orderID := uuid.New()
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, orderID).Return([]domain.LoyaltyEntry{
+		{Type: domain.LoyaltyEntryEarn, Points: 12},
+		{Type: domain.LoyaltyEntryReversal, Points: -12},
+	}, nil)
+
+	err := u.ReverseOrder(context.Background(), orderID)
+
+	assert.NoError(t, err)
+	loyaltyRepo.AssertNotCalled(t, "AddEntry")
+}
+
+func TestLoyaltyUsecase_GetAccount_CustomerNotFound(t *testing.T) {
+	customerRepo := new(mockCustomerRepo)
+	u := NewLoyaltyUsecase(new(mockLoyaltyRepo), customerRepo, new(mockMenuRepository), testLoyaltyConfig())
+
+	id := uuid.New()
+	customerRepo.On("GetByID", mock.Anything, id).Return(nil, nil)
+
+	_, err := u.GetAccount(staffCtx(domain.RoleCashier), id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestLoyaltyUsecase_GetAccount_Forbidden(t *testing.T) {
+	u := NewLoyaltyUsecase(new(mockLoyaltyRepo), new(mockCustomerRepo), new(mockMenuRepository), testLoyaltyConfig())
+
+	_, err := u.GetAccount(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"coffee-shop-pos/internal/domain"
@@ -10,18 +12,42 @@ import (
 
 type menuUsecase struct {
 	menuRepo domain.MenuItemRepository
+	audit    domain.AuditUsecase
 }
 
-func NewMenuUsecase(repo domain.MenuItemRepository) domain.MenuItemUsecase {
-	return &menuUsecase{
+// MenuUsecaseOption wires an optional collaborator into the menu usecase.
+type MenuUsecaseOption func(*menuUsecase)
+
+// WithMenuAudit records every menu item create, update and delete in the audit
+// log.
+func WithMenuAudit(audit domain.AuditUsecase) MenuUsecaseOption {
+	return func(u *menuUsecase) {
+		u.audit = audit
+	}
+}
+
+func NewMenuUsecase(repo domain.MenuItemRepository, opts ...MenuUsecaseOption) domain.MenuItemUsecase {
+	u := &menuUsecase{
 		menuRepo: repo,
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 func (u *menuUsecase) Create(ctx context.Context, item *domain.MenuItem) error {
+	if err := domain.Authorize(ctx, domain.PermMenuWrite); err != nil {
+		return err
+	}
 	item.ID = uuid.New()
+	item.Version = 1
 	item.CreatedAt = time.Now()
 	item.UpdatedAt = time.Now()
+	var err error
+	if item.Audit, err = u.auditEntry(ctx, domain.AuditMenuItemCreate, item.ID, nil, item); err != nil {
+		return err
+	}
 	return u.menuRepo.Create(ctx, item)
 }
 
@@ -34,6 +60,9 @@ func (u *menuUsecase) Fetch(ctx context.Context) ([]domain.MenuItem, error) {
 }
 
 func (u *menuUsecase) Update(ctx context.Context, item *domain.MenuItem) error {
+	if err := domain.Authorize(ctx, domain.PermMenuWrite); err != nil {
+		return err
+	}
 	existingItem, err := u.menuRepo.GetByID(ctx, item.ID)
 	if err != nil {
 		return err
@@ -41,12 +70,67 @@ func (u *menuUsecase) Update(ctx context.Context, item *domain.MenuItem) error {
 	if existingItem == nil {
 		return domain.ErrNotFound
 	}
+	if err := domain.CheckIfMatch(ctx, existingItem.Version); err != nil {
+		return err
+	}
 
+	item.Version = existingItem.Version
 	item.CreatedAt = existingItem.CreatedAt
 	item.UpdatedAt = time.Now()
-	return u.menuRepo.Update(ctx, item)
+	updated := *item
+	updated.Version++
+	if item.Audit, err = u.auditEntry(ctx, domain.AuditMenuItemUpdate, item.ID, existingItem, &updated); err != nil {
+		return err
+	}
+	if err := u.menuRepo.Update(ctx, item); err != nil {
+		return versionedWriteErr(ctx, err)
+	}
+	return nil
 }
 
 func (u *menuUsecase) Delete(ctx context.Context, id uuid.UUID) error {
-	return u.menuRepo.Delete(ctx, id)
+	if err := domain.Authorize(ctx, domain.PermMenuWrite); err != nil {
+		return err
+	}
+	existingItem, err := u.menuRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existingItem == nil {
+		return domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, existingItem.Version); err != nil {
+		return err
+	}
+	if existingItem.Audit, err = u.auditEntry(ctx, domain.AuditMenuItemDelete, id, existingItem, nil); err != nil {
+		return err
+	}
+	if err := u.menuRepo.Delete(ctx, existingItem); err != nil {
+		return versionedWriteErr(ctx, err)
+	}
+	return nil
+}
+
+// auditEntry builds the audit log entry the repository writes with the change,
+// or nil when there is no audit log.
+func (u *menuUsecase) auditEntry(ctx context.Context, action string, id uuid.UUID, before, after *domain.MenuItem) (*domain.AuditEntry, error) {
+	if u.audit == nil {
+		return nil, nil
+	}
+	return u.audit.Entry(ctx, action, domain.AuditEntityMenuItem, id, before, after)
+}
+
+// versionedWriteErr translates the errors of a write made conditional on the
+// version just read. Losing the race to another writer is a failed
+// precondition when the client sent If-Match, and a conflict otherwise.
+func versionedWriteErr(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ErrNotFound
+	case errors.Is(err, domain.ErrConflict):
+		if _, ok := domain.IfMatchFromContext(ctx); ok {
+			return domain.ErrPreconditionFailed
+		}
+	}
+	return err
 }
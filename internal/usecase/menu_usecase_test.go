@@ -40,8 +40,8 @@ func (m *mockMenuRepo) Update(ctx context.Context, item *domain.MenuItem) error
 	return args.Error(0)
 }
 
-func (m *mockMenuRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *mockMenuRepo) Delete(ctx context.Context, item *domain.MenuItem) error {
+	args := m.Called(ctx, item)
 	return args.Error(0)
 }
 
@@ -56,7 +56,7 @@ func TestCreate(t *testing.T) {
 
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.MenuItem")).Return(nil)
 
-	err := u.Create(context.Background(), item)
+	err := u.Create(managerCtx(), item)
 
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, item.ID)
@@ -75,7 +75,7 @@ func TestGetByID(t *testing.T) {
 
 	repo.On("GetByID", mock.Anything, id).Return(expected, nil)
 
-	result, err := u.GetByID(context.Background(), id)
+	result, err := u.GetByID(managerCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -101,7 +101,7 @@ func TestFetch(t *testing.T) {
 
 	repo.On("Fetch", mock.Anything).Return(items, nil)
 
-	result, err := u.Fetch(context.Background())
+	result, err := u.Fetch(managerCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, len(items))
@@ -125,7 +125,7 @@ func TestUpdate(t *testing.T) {
 	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
 	repo.On("Update", mock.Anything, item).Return(nil)
 
-	err := u.Update(context.Background(), item)
+	err := u.Update(managerCtx(), item)
 
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
@@ -143,7 +143,7 @@ func TestUpdate_NotFound(t *testing.T) {
 
 	repo.On("GetByID", mock.Anything, id).Return(nil, nil)
 
-	err := u.Update(context.Background(), item)
+	err := u.Update(managerCtx(), item)
 
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 	repo.AssertExpectations(t)
@@ -162,7 +162,7 @@ func TestUpdate_DBError(t *testing.T) {
 	dbErr := errors.New("db error")
 	repo.On("GetByID", mock.Anything, id).Return(nil, dbErr)
 
-	err := u.Update(context.Background(), item)
+	err := u.Update(managerCtx(), item)
 
 	assert.ErrorIs(t, err, dbErr)
 	repo.AssertExpectations(t)
@@ -173,10 +173,99 @@ func TestDelete(t *testing.T) {
 	u := NewMenuUsecase(repo)
 	id := uuid.New()
 
-	repo.On("Delete", mock.Anything, id).Return(nil)
+	repo.On("GetByID", mock.Anything, id).Return(&domain.MenuItem{ID: id, Version: 3}, nil)
+	repo.On("Delete", mock.Anything, mock.MatchedBy(func(item *domain.MenuItem) bool {
+		return item.ID == id && item.Version == 3
+	})).Return(nil)
 
-	err := u.Delete(context.Background(), id)
+	err := u.Delete(managerCtx(), id)
 
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
+
+func TestUpdate_IfMatch(t *testing.T) {
+	repo := new(mockMenuRepo)
+	u := NewMenuUsecase(repo)
+	id := uuid.New()
+	existing := &domain.MenuItem{ID: id, Name: "Mocha", Version: 2}
+
+	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(item *domain.MenuItem) bool {
+		return item.Version == 2
+	})).Return(nil).Once()
+
+	stale := domain.WithIfMatch(managerCtx(), []int64{1})
+	assert.ErrorIs(t, u.Update(stale, &domain.MenuItem{ID: id, Name: "Mocha"}), domain.ErrPreconditionFailed)
+	assert.ErrorIs(t, u.Delete(stale, id), domain.ErrPreconditionFailed)
+
+	current := domain.WithIfMatch(managerCtx(), []int64{1, 2})
+	assert.NoError(t, u.Update(current, &domain.MenuItem{ID: id, Name: "Mocha"}))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestUpdate_LostRace(t *testing.T) {
+	repo := new(mockMenuRepo)
+	u := NewMenuUsecase(repo)
+	id := uuid.New()
+
+	repo.On("GetByID", mock.Anything, id).Return(&domain.MenuItem{ID: id, Version: 2}, nil)
+	repo.On("Update", mock.Anything, mock.Anything).Return(domain.ErrConflict)
+
+	assert.ErrorIs(t, u.Update(managerCtx(), &domain.MenuItem{ID: id}), domain.ErrConflict)
+	ctx := domain.WithIfMatch(managerCtx(), []int64{2})
+	assert.ErrorIs(t, u.Update(ctx, &domain.MenuItem{ID: id}), domain.ErrPreconditionFailed)
+}
+
+func TestMenuUsecase_WritesRequireManager(t *testing.T) {
+	repo := new(mockMenuRepo)
+	u := NewMenuUsecase(repo)
+	cashier := staffCtx(domain.RoleCashier)
+
+	assert.ErrorIs(t, u.Create(cashier, &domain.MenuItem{Name: "Latte"}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.Update(cashier, &domain.MenuItem{ID: uuid.New(), Name: "Latte"}), domain.ErrForbidden)
+	assert.ErrorIs(t, u.Delete(cashier, uuid.New()), domain.ErrForbidden)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+type mockAuditUsecase struct{ mock.Mock }
+
+func (m *mockAuditUsecase) Entry(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, action, entityType, entityID, before, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+
+func (m *mockAuditUsecase) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func TestMenuUsecase_RecordsAudit(t *testing.T) {
+	repo := new(mockMenuRepo)
+	audit := new(mockAuditUsecase)
+	u := NewMenuUsecase(repo, WithMenuAudit(audit))
+	id := uuid.New()
+	existing := &domain.MenuItem{ID: id, Name: "Mocha", Price: decimal.NewFromFloat(4.00)}
+	item := &domain.MenuItem{ID: id, Name: "Mocha", Price: decimal.NewFromFloat(4.50)}
+
+	updated, deleted := &domain.AuditEntry{ID: uuid.New()}, &domain.AuditEntry{ID: uuid.New()}
+	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+	repo.On("Update", mock.Anything, item).Return(nil)
+	repo.On("Delete", mock.Anything, existing).Return(nil)
+	// The entry is built before the write, so it shows the version the write
+	// moves the item to.
+	audit.On("Entry", mock.Anything, domain.AuditMenuItemUpdate, domain.AuditEntityMenuItem, id, existing, mock.MatchedBy(func(after *domain.MenuItem) bool {
+		return after.Version == 1 && after.Price.Equal(decimal.NewFromFloat(4.50))
+	})).Return(updated, nil)
+	audit.On("Entry", mock.Anything, domain.AuditMenuItemDelete, domain.AuditEntityMenuItem, id, existing, (*domain.MenuItem)(nil)).Return(deleted, nil)
+
+	assert.NoError(t, u.Update(managerCtx(), item))
+	assert.Equal(t, updated, item.Audit)
+	assert.NoError(t, u.Delete(managerCtx(), id))
+	assert.Equal(t, deleted, existing.Audit)
+	audit.AssertExpectations(t)
+}
@@ -2,10 +2,15 @@ package usecase
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"coffee-shop-pos/internal/domain"
 	"github.com/google/uuid"
@@ -13,12 +18,68 @@ import (
 )
 
 var (
-	ErrEmptyOrderItems      = errors.New("order must contain at least one item")
-	ErrInvalidOrderQuantity = errors.New("quantity must be greater than zero")
-	ErrInvalidOrderStatus   = errors.New("invalid order status")
-	ErrInvalidStatusMove    = errors.New("invalid status transition")
+	ErrEmptyOrderItems        = errors.New("order must contain at least one item")
+	ErrInvalidOrderQuantity   = errors.New("quantity must be greater than zero")
+	ErrInvalidOrderStatus     = errors.New("invalid order status")
+	ErrInvalidStatusMove      = errors.New("invalid status transition")
+	ErrCustomerNotFound       = errors.New("customer not found")
+	ErrInvalidRedeemPoints    = errors.New("redeemed points must not be negative")
+	ErrRedeemNeedsCustomer    = errors.New("redeeming points requires a customer")
+	ErrRedeemExceedsTotal     = errors.New("redeemed points exceed the order subtotal excluding gift cards")
+	ErrLoyaltyDisabled        = errors.New("loyalty program is not enabled")
+	ErrGiftCardCodeNotAllowed = errors.New("gift card codes are only allowed on gift card items")
+	ErrInvalidManualDiscount  = errors.New("manual discount must not be negative")
+	ErrDiscountExceedsTotal   = errors.New("discount exceeds the order subtotal excluding gift cards")
+	ErrStatusReasonTooLong    = errors.New("status change reason is too long")
+	ErrOrderNotEditable       = errors.New("only pending orders can be edited")
+	ErrOrderHasPayments       = errors.New("orders with payments cannot be edited")
+	ErrOrderItemNotFound      = errors.New("order item not found")
+	ErrMenuItemNotFound       = errors.New("menu item not found")
+	ErrRewardLineNotEditable  = errors.New("free reward lines cannot be edited")
+	ErrOrderNotVoidable       = errors.New("only lines on paid orders can be voided")
+	ErrLineAlreadyVoided      = errors.New("order item is already voided")
+	ErrGiftCardLineNotVoid    = errors.New("gift card lines cannot be voided, cancel the order instead")
+	ErrVoidLastLine           = errors.New("cannot void the last line, cancel the order instead")
+	ErrVoidReasonRequired     = errors.New("a reason is required to void a line")
+	ErrVoidReasonTooLong      = errors.New("void reason is too long")
+	ErrInvalidOrderType       = errors.New("order type must be dine_in, takeaway or delivery")
+	ErrInvalidTableNumber     = errors.New("table number must be up to 10 letters, digits or dashes")
+	ErrInvalidCallOutName     = errors.New("call-out name must be up to 50 printable characters")
+	ErrNoteTooLong            = errors.New("notes must be up to 200 characters")
+	ErrPreordersDisabled      = errors.New("pre-orders are not enabled")
+	ErrInvalidPickupTime      = errors.New("pickup time must be in the future")
+	ErrOutsideOpeningHours    = errors.New("pickup time is outside opening hours")
+	ErrTabNameRequired        = errors.New("a tab needs a name or a table number")
+	ErrInvalidTabName         = errors.New("tab name must be up to 50 printable characters")
+	ErrTabAlreadyOpen         = errors.New("order is already an open tab")
+	ErrInvalidSplitCount      = errors.New("a bill can be split into 2 to 20 orders")
+	ErrInvalidSplitQuantity   = errors.New("split quantities must be positive with at most 3 decimal places")
+	ErrSplitIncomplete        = errors.New("every line must be split in full")
+	ErrSplitGiftCardLine      = errors.New("gift card lines cannot be shared between split orders")
+	ErrSplitRewardLine        = errors.New("the free reward lines of a stamp card must all go whole to one split order")
+	ErrSplitRedeemedPoints    = errors.New("orders paid with loyalty points cannot be split")
+	ErrSplitHasPayments       = errors.New("orders with payments cannot be split")
+	ErrSplitUnbalanced        = errors.New("split orders cannot be made to add up to the order total")
+	ErrInvalidMergeCount      = errors.New("merge takes 1 to 20 other orders")
+	ErrInvalidMergeSource     = errors.New("an order cannot be merged into itself or twice")
+	ErrMergeRedeemedPoints    = errors.New("orders paid with loyalty points cannot be merged into another")
+	ErrMergeHasPayments       = errors.New("orders with payments cannot be merged into another")
+	ErrMergeCustomerMismatch  = errors.New("orders for different customers cannot be merged")
+	ErrMergeStampRewards      = errors.New("orders with rewards from the same stamp card cannot be merged")
+	ErrTableNumberRequired    = errors.New("a table number is required")
 )
 
+const (
+	maxStatusReasonLength = 500
+	maxCallOutNameLength  = 50
+	maxNoteLength         = 200
+	maxSplits             = 20
+	// pickupSlot is the length of the slots pre-order capacity is counted in.
+	pickupSlot = 5 * time.Minute
+)
+
+var tableNumberPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,10}$`)
+
 var allowedStatusTransitions = map[string]map[string]bool{
 	domain.OrderStatusPending: {
 		domain.OrderStatusPaid:      true,
@@ -30,75 +91,682 @@ var allowedStatusTransitions = map[string]map[string]bool{
 	},
 	domain.OrderStatusCancelled: {},
 	domain.OrderStatusCompleted: {},
+	domain.OrderStatusSplit:     {},
+	domain.OrderStatusMerged:    {},
+}
+
+// statusPermission is what a staff member needs to move an order from one
+// status to another. Cancelling a paid order refunds it, so only managers can.
+func statusPermission(from, to string) domain.Permission {
+	switch to {
+	case domain.OrderStatusPaid:
+		return domain.PermPaymentsTake
+	case domain.OrderStatusCompleted:
+		return domain.PermOrdersPrepare
+	case domain.OrderStatusCancelled:
+		if from == domain.OrderStatusPaid {
+			return domain.PermOrdersRefund
+		}
+	}
+	return domain.PermOrdersCancel
 }
 
 type orderUsecase struct {
-	orderRepo domain.OrderRepository
-	menuRepo  domain.MenuItemRepository
-	taxRate   decimal.Decimal
+	orderRepo     domain.OrderRepository
+	menuRepo      domain.MenuItemRepository
+	inventoryRepo domain.InventoryRepository
+	customerRepo  domain.CustomerRepository
+	loyalty       domain.LoyaltyUsecase
+	stamps        domain.StampUsecase
+	giftCards     domain.GiftCardUsecase
+	paymentRepo   domain.PaymentRepository
+	overrides     domain.OverrideUsecase
+	audit         domain.AuditUsecase
+	// largeDiscount is the share of the subtotal above which a manual
+	// discount needs a manager.
+	largeDiscount decimal.Decimal
+	orderTypes    map[string]OrderTypeRule
+	numbering     OrderNumberConfig
+	preorders     *PreorderConfig
+}
+
+// OrderTypeRule is how orders of one type are charged. Both rates are shares
+// of the order after discounts, leaving out gift cards; the service charge is
+// not taxed.
+type OrderTypeRule struct {
+	TaxRate       decimal.Decimal
+	ServiceCharge decimal.Decimal
+}
+
+// ParseOrderTypeRule builds an OrderTypeRule from rates such as "0.10".
+func ParseOrderTypeRule(taxRate, serviceCharge string) (OrderTypeRule, error) {
+	tax, err := decimal.NewFromString(taxRate)
+	if err != nil || tax.IsNegative() || tax.GreaterThan(decimal.NewFromInt(1)) {
+		return OrderTypeRule{}, fmt.Errorf("invalid tax rate %q", taxRate)
+	}
+	charge, err := decimal.NewFromString(serviceCharge)
+	if err != nil || charge.IsNegative() || charge.GreaterThan(decimal.NewFromInt(1)) {
+		return OrderTypeRule{}, fmt.Errorf("invalid service charge %q", serviceCharge)
+	}
+	return OrderTypeRule{TaxRate: tax, ServiceCharge: charge}, nil
+}
+
+// OrderNumberConfig says how orders are numbered: per store, restarting every
+// business day. A business day starts DayStart after midnight in Location, so
+// orders rung up shortly after midnight can still count towards the day before.
+type OrderNumberConfig struct {
+	StoreCode string
+	Location  *time.Location
+	DayStart  time.Duration
+}
+
+var storeCodePattern = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+
+// ParseOrderNumberConfig builds an OrderNumberConfig from its string settings:
+// a store code of up to 10 capital letters or digits, an IANA time zone name
+// and a duration such as "4h".
+func ParseOrderNumberConfig(storeCode, timezone, dayStart string) (OrderNumberConfig, error) {
+	if !storeCodePattern.MatchString(storeCode) {
+		return OrderNumberConfig{}, fmt.Errorf("invalid store code %q", storeCode)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return OrderNumberConfig{}, fmt.Errorf("invalid store time zone %q", timezone)
+	}
+	start, err := time.ParseDuration(dayStart)
+	if err != nil || start < 0 || start >= 24*time.Hour {
+		return OrderNumberConfig{}, fmt.Errorf("invalid business day start %q", dayStart)
+	}
+	return OrderNumberConfig{StoreCode: storeCode, Location: location, DayStart: start}, nil
+}
+
+// BusinessDate is the business day t falls in, as midnight UTC of that date.
+func (c OrderNumberConfig) BusinessDate(t time.Time) time.Time {
+	local := t.In(c.Location).Add(-c.DayStart)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PreorderConfig says when pre-orders can be picked up and how they are
+// released. Opens and Closes are times of day in the store's time zone.
+// SlotCapacity caps the units of the menu items in Categories (every item but
+// gift cards when empty) picked up in one slot; 0 means no cap.
+type PreorderConfig struct {
+	Opens        time.Duration
+	Closes       time.Duration
+	SlotCapacity int
+	Categories   []string
+	LeadTime     time.Duration
+}
+
+// ParsePreorderConfig builds a PreorderConfig from its string settings:
+// opening hours such as "07:00-19:00", a slot capacity, comma-separated
+// categories and a lead time such as "15m".
+func ParsePreorderConfig(openingHours, slotCapacity, categories, leadTime string) (PreorderConfig, error) {
+	opens, closes, ok := strings.Cut(openingHours, "-")
+	if !ok {
+		return PreorderConfig{}, fmt.Errorf("invalid opening hours %q", openingHours)
+	}
+	cfg := PreorderConfig{}
+	var err error
+	if cfg.Opens, err = parseTimeOfDay(opens); err != nil {
+		return PreorderConfig{}, fmt.Errorf("invalid opening hours %q", openingHours)
+	}
+	if cfg.Closes, err = parseTimeOfDay(closes); err != nil || cfg.Closes <= cfg.Opens {
+		return PreorderConfig{}, fmt.Errorf("invalid opening hours %q", openingHours)
+	}
+	if cfg.SlotCapacity, err = strconv.Atoi(slotCapacity); err != nil || cfg.SlotCapacity < 0 {
+		return PreorderConfig{}, fmt.Errorf("invalid pre-order slot capacity %q", slotCapacity)
+	}
+	for _, category := range strings.Split(categories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			cfg.Categories = append(cfg.Categories, category)
+		}
+	}
+	if cfg.LeadTime, err = time.ParseDuration(leadTime); err != nil || cfg.LeadTime < 0 {
+		return PreorderConfig{}, fmt.Errorf("invalid pre-order lead time %q", leadTime)
+	}
+	return cfg, nil
+}
+
+// parseTimeOfDay parses "HH:MM", allowing "24:00" for midnight at the end of
+// the day.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// OrderUsecaseOption wires an optional collaborator into the order usecase.
+type OrderUsecaseOption func(*orderUsecase)
+
+// WithInventoryRepository snapshots the theoretical recipe cost onto every
+// order item at sale time.
+func WithInventoryRepository(repo domain.InventoryRepository) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.inventoryRepo = repo
+	}
+}
+
+// WithCustomerRepository validates the customer attached to new orders.
+func WithCustomerRepository(repo domain.CustomerRepository) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.customerRepo = repo
+	}
+}
+
+// WithLoyaltyUsecase lets orders redeem points at creation, earn points once
+// paid and reverse both when cancelled.
+func WithLoyaltyUsecase(loyalty domain.LoyaltyUsecase) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.loyalty = loyalty
+	}
+}
+
+// WithStampUsecase gives linked customers a free item when a stamp card is full
+// and stamps their cards once orders are paid.
+func WithStampUsecase(stamps domain.StampUsecase) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.stamps = stamps
+	}
+}
+
+// WithGiftCardUsecase lets orders sell and reload gift cards, issuing them once
+// the order is paid and voiding them if it is cancelled.
+func WithGiftCardUsecase(giftCards domain.GiftCardUsecase) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.giftCards = giftCards
+	}
+}
+
+// WithPaymentRepository refunds voided lines against the payments an order
+// was paid with.
+func WithPaymentRepository(repo domain.PaymentRepository) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.paymentRepo = repo
+	}
+}
+
+// WithOverrideUsecase lets staff without the permission cancel paid orders or
+// give a manual discount above threshold (a share of the subtotal, e.g. 0.2)
+// when a manager approves it.
+func WithOverrideUsecase(overrides domain.OverrideUsecase, threshold decimal.Decimal) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.overrides = overrides
+		u.largeDiscount = threshold
+	}
 }
 
-func NewOrderUsecase(orderRepo domain.OrderRepository, menuRepo domain.MenuItemRepository) domain.OrderUsecase {
-	return &orderUsecase{
+// WithOrderTypeRules sets the tax rate and service charge of each order type.
+// Types left out keep the default of 10% tax and no service charge.
+func WithOrderTypeRules(rules map[string]OrderTypeRule) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		for orderType, rule := range rules {
+			u.orderTypes[orderType] = rule
+		}
+	}
+}
+
+// WithOrderNumbering sets the store code and business day used to number
+// orders. Without it orders are numbered for store "A" by UTC calendar day.
+func WithOrderNumbering(config OrderNumberConfig) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.numbering = config
+	}
+}
+
+// WithPreorders lets orders be scheduled for pickup. Opening hours are read
+// in the time zone of the order numbering.
+func WithPreorders(config PreorderConfig) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.preorders = &config
+	}
+}
+
+// WithAuditUsecase records every order status change in the audit log.
+func WithAuditUsecase(audit domain.AuditUsecase) OrderUsecaseOption {
+	return func(u *orderUsecase) {
+		u.audit = audit
+	}
+}
+
+func NewOrderUsecase(orderRepo domain.OrderRepository, menuRepo domain.MenuItemRepository, opts ...OrderUsecaseOption) domain.OrderUsecase {
+	defaultRule := OrderTypeRule{TaxRate: decimal.NewFromFloat(0.10)}
+	u := &orderUsecase{
 		orderRepo: orderRepo,
 		menuRepo:  menuRepo,
-		taxRate:   decimal.NewFromFloat(0.10),
+		orderTypes: map[string]OrderTypeRule{
+			domain.OrderTypeDineIn:   defaultRule,
+			domain.OrderTypeTakeaway: defaultRule,
+			domain.OrderTypeDelivery: defaultRule,
+		},
+		numbering: OrderNumberConfig{StoreCode: "A", Location: time.UTC},
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
 }
 
 func (u *orderUsecase) Create(ctx context.Context, order *domain.Order) error {
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return err
+	}
 	if len(order.Items) == 0 {
 		return ErrEmptyOrderItems
 	}
+	if order.RedeemedPoints < 0 {
+		return ErrInvalidRedeemPoints
+	}
+	if order.ManualDiscount.IsNegative() {
+		return ErrInvalidManualDiscount
+	}
+	if order.OrderType == "" {
+		order.OrderType = domain.OrderTypeTakeaway
+	}
+	if !domain.IsOrderType(order.OrderType) {
+		return ErrInvalidOrderType
+	}
+	order.TableNumber = strings.TrimSpace(order.TableNumber)
+	if order.TableNumber != "" && !tableNumberPattern.MatchString(order.TableNumber) {
+		return ErrInvalidTableNumber
+	}
+	order.CallOutName = strings.TrimSpace(order.CallOutName)
+	if !validCallOutName(order.CallOutName) {
+		return ErrInvalidCallOutName
+	}
+	note, err := sanitizeNote(order.Note)
+	if err != nil {
+		return err
+	}
+	order.Note = note
+	if order.PickupAt != nil {
+		if err := u.checkPickupTime(*order.PickupAt, time.Now()); err != nil {
+			return err
+		}
+	}
+	order.ReleasedAt = nil
+	if order.RedeemedPoints > 0 {
+		if order.CustomerID == nil {
+			return ErrRedeemNeedsCustomer
+		}
+		if u.loyalty == nil {
+			return ErrLoyaltyDisabled
+		}
+	}
+
+	if order.CustomerID != nil && u.customerRepo != nil {
+		customer, err := u.customerRepo.GetByID(ctx, *order.CustomerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil {
+			return ErrCustomerNotFound
+		}
+	}
 
 	now := time.Now()
 	order.ID = uuid.New()
-	order.OrderNumber = fmt.Sprintf("ORD-%d", now.UnixNano())
+	order.StoreCode = u.numbering.StoreCode
+	order.BusinessDate = u.numbering.BusinessDate(now)
 	order.Status = domain.OrderStatusPending
+	order.Version = 1
+	if identity, ok := domain.IdentityFromContext(ctx); ok {
+		order.CashierID = identity.StaffRef()
+		order.TerminalID = identity.TerminalID
+	}
 	order.CreatedAt = now
 	order.UpdatedAt = now
+	order.StatusHistory = []domain.OrderStatusChange{*newStatusChange(ctx, order.ID, nil, domain.OrderStatusPending, "", now)}
 
-	subtotal := decimal.Zero
+	giftCardItems := make(map[uuid.UUID]bool)
 	for i := range order.Items {
-		if order.Items[i].Quantity <= 0 {
-			return ErrInvalidOrderQuantity
+		isGiftCard, err := u.priceLine(ctx, order.ID, &order.Items[i])
+		if err != nil {
+			return err
 		}
+		if isGiftCard {
+			giftCardItems[order.Items[i].MenuItemID] = true
+		}
+	}
 
-		menuItem, err := u.menuRepo.GetByID(ctx, order.Items[i].MenuItemID)
-		if err != nil {
+	if u.stamps != nil {
+		if err := u.stamps.ApplyRewards(ctx, order); err != nil {
 			return err
 		}
-		if menuItem == nil {
-			return domain.ErrNotFound
+	}
+
+	if err := u.priceTotals(order, giftCardItems); err != nil {
+		return err
+	}
+	if order.PickupAt != nil {
+		if err := u.reservePickupSlot(ctx, order, now); err != nil {
+			return err
+		}
+	}
+	if order.ManualDiscount.IsPositive() {
+		var err error
+		if order.Approval, err = u.approveDiscount(ctx, order); err != nil {
+			return err
 		}
+	}
 
-		lineTotal := menuItem.Price.Mul(decimal.NewFromInt(int64(order.Items[i].Quantity)))
-		order.Items[i].ID = uuid.New()
-		order.Items[i].OrderID = order.ID
-		order.Items[i].UnitPrice = menuItem.Price
-		order.Items[i].LineTotal = lineTotal
-		subtotal = subtotal.Add(lineTotal)
+	if err := u.redeemRewards(ctx, order); err != nil {
+		u.releaseRewards(ctx, order)
+		return err
 	}
+	if err := u.orderRepo.Create(ctx, order); err != nil {
+		u.releaseRewards(ctx, order)
+		return err
+	}
+	return nil
+}
+
+// checkPickupTime checks that a pre-order can be picked up at pickup.
+func (u *orderUsecase) checkPickupTime(pickup, now time.Time) error {
+	if u.preorders == nil {
+		return ErrPreordersDisabled
+	}
+	if !pickup.After(now) {
+		return ErrInvalidPickupTime
+	}
+	local := pickup.In(u.numbering.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, u.numbering.Location)
+	if since := local.Sub(midnight); since < u.preorders.Opens || since >= u.preorders.Closes {
+		return ErrOutsideOpeningHours
+	}
+	return nil
+}
 
+// reservePickupSlot checks the pre-order fits in its pickup slot and releases
+// it straight away if its lead time has already come.
+func (u *orderUsecase) reservePickupSlot(ctx context.Context, order *domain.Order, now time.Time) error {
+	if err := u.bookSlot(ctx, order); err != nil {
+		return err
+	}
+	if !order.PickupAt.Add(-u.preorders.LeadTime).After(now) {
+		order.ReleasedAt = &now
+	}
+	return nil
+}
+
+// bookSlot asks the repository to check the order against its pickup slot's
+// capacity when it is written.
+func (u *orderUsecase) bookSlot(ctx context.Context, order *domain.Order) error {
+	if u.preorders.SlotCapacity <= 0 {
+		return nil
+	}
+	units, err := u.slotUnits(ctx, order.Items)
+	if err != nil || !units.IsPositive() {
+		return err
+	}
+	slot := order.PickupAt.Truncate(pickupSlot)
+	order.SlotBooking = &domain.SlotBooking{
+		From:       slot,
+		To:         slot.Add(pickupSlot),
+		Units:      units,
+		Capacity:   u.preorders.SlotCapacity,
+		Categories: u.preorders.Categories,
+	}
+	return nil
+}
+
+// slotUnits counts the units on the lines that take up pickup slot capacity.
+func (u *orderUsecase) slotUnits(ctx context.Context, items []domain.OrderItem) (decimal.Decimal, error) {
+	units := decimal.Zero
+	for _, item := range items {
+		menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if menuItem == nil || strings.EqualFold(menuItem.Category, domain.GiftCardCategory) {
+			continue
+		}
+		counted := len(u.preorders.Categories) == 0
+		for _, category := range u.preorders.Categories {
+			if strings.EqualFold(menuItem.Category, category) {
+				counted = true
+				break
+			}
+		}
+		if counted {
+			units = units.Add(item.Units())
+		}
+	}
+	return units, nil
+}
+
+func (u *orderUsecase) ReleaseDue(ctx context.Context) (int64, error) {
+	if u.preorders == nil {
+		return 0, nil
+	}
+	now := time.Now()
+	return u.orderRepo.ReleaseScheduled(ctx, now.Add(u.preorders.LeadTime), now)
+}
+
+// priceLine prices a new order line from the menu and snapshots its recipe
+// cost. It reports whether the line sells a gift card.
+func (u *orderUsecase) priceLine(ctx context.Context, orderID uuid.UUID, item *domain.OrderItem) (bool, error) {
+	if item.Quantity <= 0 {
+		return false, ErrInvalidOrderQuantity
+	}
+	note, err := sanitizeNote(item.Note)
+	if err != nil {
+		return false, err
+	}
+	item.Note = note
+
+	menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+	if err != nil {
+		return false, err
+	}
+	if menuItem == nil {
+		return false, domain.ErrNotFound
+	}
+	isGiftCard := strings.EqualFold(menuItem.Category, domain.GiftCardCategory)
+	if item.GiftCardCode != "" {
+		if !isGiftCard || u.giftCards == nil {
+			return false, ErrGiftCardCodeNotAllowed
+		}
+		card, err := u.giftCards.GetByCode(ctx, item.GiftCardCode)
+		if err != nil {
+			return false, err
+		}
+		item.GiftCardCode = card.Code
+	}
+
+	item.ID = uuid.New()
+	item.OrderID = orderID
+	item.UnitPrice = menuItem.Price
+	item.LineTotal = menuItem.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+	item.UnitCost = decimal.Zero
+	if u.inventoryRepo != nil {
+		unitCost, err := u.inventoryRepo.GetMenuItemCost(ctx, menuItem.ID)
+		if err != nil {
+			return false, err
+		}
+		item.UnitCost = unitCost.Round(4)
+	}
+	return isGiftCard, nil
+}
+
+// priceTotals works out the order's subtotal, discount, tax and total from its
+// lines that are not voided. Gift cards are stored value, not a sale, so lines
+// selling the menu items in giftCardItems are neither taxed nor discounted.
+func (u *orderUsecase) priceTotals(order *domain.Order, giftCardItems map[uuid.UUID]bool) error {
+	subtotal := decimal.Zero
+	untaxed := decimal.Zero
+	for _, item := range order.Items {
+		if item.VoidedAt != nil {
+			continue
+		}
+		subtotal = subtotal.Add(item.LineTotal)
+		if giftCardItems[item.MenuItemID] {
+			untaxed = untaxed.Add(item.LineTotal)
+		}
+	}
 	order.Subtotal = subtotal.Round(2)
-	order.Tax = order.Subtotal.Mul(u.taxRate).Round(2)
-	order.Total = order.Subtotal.Add(order.Tax).Round(2)
+	// Gift cards are issued at their full price, so discounts only come off
+	// the rest of the order; otherwise stored value could be bought cheap.
+	discountable := order.Subtotal.Sub(untaxed)
+	order.Discount = decimal.Zero
+	if order.RedeemedPoints > 0 {
+		order.Discount = u.loyalty.RedemptionValue(order.RedeemedPoints)
+		if order.Discount.GreaterThan(discountable) {
+			return ErrRedeemExceedsTotal
+		}
+	}
+	order.ManualDiscount = order.ManualDiscount.Round(2)
+	if order.ManualDiscount.IsPositive() {
+		order.Discount = order.Discount.Add(order.ManualDiscount)
+		if order.Discount.GreaterThan(discountable) {
+			return ErrDiscountExceedsTotal
+		}
+	}
+	rule := u.orderTypes[order.OrderType]
+	net := order.Subtotal.Sub(order.Discount)
+	taxable := discountable.Sub(order.Discount)
+	order.Tax = taxable.Mul(rule.TaxRate).Round(2)
+	order.ServiceCharge = taxable.Mul(rule.ServiceCharge).Round(2)
+	order.Total = net.Add(order.Tax).Add(order.ServiceCharge).Round(2)
+	return nil
+}
+
+// validCallOutName allows any printable text of up to maxCallOutNameLength
+// characters.
+func validCallOutName(name string) bool {
+	if utf8.RuneCountInString(name) > maxCallOutNameLength || !utf8.ValidString(name) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
 
-	return u.orderRepo.Create(ctx, order)
+// sanitizeNote turns line breaks and other whitespace into single spaces and
+// drops control characters, so a note prints on one line and cannot carry
+// printer escape codes.
+func sanitizeNote(note string) (string, error) {
+	note = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case !unicode.IsPrint(r):
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(note, ""))
+	note = strings.Join(strings.Fields(note), " ")
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return "", ErrNoteTooLong
+	}
+	return note, nil
+}
+
+// joinNotes combines the notes of merged orders, cutting the result to
+// maxNoteLength characters.
+func joinNotes(notes ...string) string {
+	var parts []string
+	for _, note := range notes {
+		if note != "" {
+			parts = append(parts, note)
+		}
+	}
+	joined := []rune(strings.Join(parts, "; "))
+	if len(joined) > maxNoteLength {
+		joined = joined[:maxNoteLength]
+	}
+	return strings.TrimSpace(string(joined))
+}
+
+// approveDiscount checks that the caller may give the order's manual discount.
+// Discounts above the threshold need PermOrdersDiscount, either held by the
+// caller or granted by a manager override, and return the approval to record
+// with the order.
+func (u *orderUsecase) approveDiscount(ctx context.Context, order *domain.Order) (*domain.Approval, error) {
+	if u.overrides == nil {
+		return nil, domain.Authorize(ctx, domain.PermOrdersDiscount)
+	}
+	if !order.ManualDiscount.GreaterThan(order.Subtotal.Mul(u.largeDiscount)) {
+		return nil, nil
+	}
+	return u.overrides.Approve(ctx, domain.OverrideLargeDiscount, order.ID)
+}
+
+// newStatusChange is a status history entry for a change made by the caller in
+// ctx.
+func newStatusChange(ctx context.Context, orderID uuid.UUID, from *string, to, reason string, at time.Time) *domain.OrderStatusChange {
+	change := &domain.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+		CreatedAt:  at,
+	}
+	if identity, ok := domain.IdentityFromContext(ctx); ok {
+		change.ActorID = identity.StaffRef()
+		change.APIKeyID = identity.APIKeyID
+	}
+	return change
+}
+
+// redeemRewards spends the points and stamp cards an order was priced with.
+func (u *orderUsecase) redeemRewards(ctx context.Context, order *domain.Order) error {
+	if order.RedeemedPoints > 0 {
+		if err := u.loyalty.RedeemForOrder(ctx, order); err != nil {
+			return err
+		}
+	}
+	if u.stamps != nil {
+		return u.stamps.RedeemRewards(ctx, order)
+	}
+	return nil
+}
+
+// releaseRewards gives back whatever redeemRewards spent for an order that was
+// never stored. It is best effort; the original error is what matters.
+func (u *orderUsecase) releaseRewards(ctx context.Context, order *domain.Order) {
+	if order.RedeemedPoints > 0 {
+		_ = u.loyalty.ReverseOrder(ctx, order.ID)
+	}
+	if u.stamps != nil {
+		_ = u.stamps.ReverseOrder(ctx, order.ID)
+	}
 }
 
 func (u *orderUsecase) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
-	return u.orderRepo.GetByID(ctx, id)
+	order, err := u.orderRepo.GetByID(ctx, id)
+	if err != nil || order == nil {
+		return order, err
+	}
+	order.StatusHistory, err = u.orderRepo.StatusHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
 }
 
-func (u *orderUsecase) List(ctx context.Context) ([]domain.Order, error) {
-	return u.orderRepo.List(ctx)
+func (u *orderUsecase) List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, error) {
+	return u.orderRepo.List(ctx, filter)
 }
 
-func (u *orderUsecase) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+func (u *orderUsecase) UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) error {
 	if _, ok := allowedStatusTransitions[status]; !ok {
 		return ErrInvalidOrderStatus
 	}
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > maxStatusReasonLength {
+		return ErrStatusReasonTooLong
+	}
 
 	order, err := u.orderRepo.GetByID(ctx, id)
 	if err != nil {
@@ -107,18 +775,951 @@ func (u *orderUsecase) UpdateStatus(ctx context.Context, id uuid.UUID, status st
 	if order == nil {
 		return domain.ErrNotFound
 	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return err
+	}
+	// Cancelling a paid order goes through an override, which is only checked
+	// once the move itself is known to be valid.
+	cancelPaid := order.Status == domain.OrderStatusPaid && status == domain.OrderStatusCancelled && u.overrides != nil
+	if !cancelPaid {
+		if err := domain.Authorize(ctx, statusPermission(order.Status, status)); err != nil {
+			return err
+		}
+	}
 
 	if order.Status == status {
-		return nil
+		// Asking again for the status an order already has retries the side
+		// effects of reaching it, in case an earlier attempt failed after the
+		// status was stored. Each of them skips what it already recorded.
+		return u.applyStatusEffects(ctx, order, status)
 	}
 
 	if !allowedStatusTransitions[order.Status][status] {
 		return ErrInvalidStatusMove
 	}
 
-	err = u.orderRepo.UpdateStatus(ctx, id, status, time.Now())
-	if errors.Is(err, sql.ErrNoRows) {
-		return domain.ErrNotFound
+	from := order.Status
+	change := newStatusChange(ctx, id, &from, status, reason, time.Now())
+	if cancelPaid {
+		if change.Approval, err = u.overrides.Approve(ctx, domain.OverrideCancelPaidOrder, id); err != nil {
+			return err
+		}
+	}
+	change.Audit, err = u.auditEntry(ctx, domain.AuditOrderStatusChange, id,
+		map[string]string{"status": order.Status}, map[string]string{"status": status})
+	if err != nil {
+		return err
+	}
+	if err := u.orderRepo.UpdateStatus(ctx, change, order.Version); err != nil {
+		return versionedWriteErr(ctx, err)
+	}
+	return u.applyStatusEffects(ctx, order, status)
+}
+
+// auditEntry builds the audit log entry the repository writes with the
+// change, or nil when there is no audit log.
+func (u *orderUsecase) auditEntry(ctx context.Context, action string, id uuid.UUID, before, after interface{}) (*domain.AuditEntry, error) {
+	if u.audit == nil {
+		return nil, nil
+	}
+	return u.audit.Entry(ctx, action, domain.AuditEntityOrder, id, before, after)
+}
+
+// applyStatusEffects settles loyalty, stamps and gift cards for an order that
+// has reached status. Every step is keyed by order and safe to repeat.
+func (u *orderUsecase) applyStatusEffects(ctx context.Context, order *domain.Order, status string) error {
+	switch status {
+	case domain.OrderStatusPaid:
+		if u.loyalty != nil {
+			if err := u.loyalty.EarnForOrder(ctx, order); err != nil {
+				return err
+			}
+		}
+		if u.stamps != nil {
+			if err := u.stamps.StampOrder(ctx, order); err != nil {
+				return err
+			}
+		}
+		if u.giftCards != nil {
+			return u.giftCards.IssueForOrder(ctx, order)
+		}
+	case domain.OrderStatusCancelled:
+		if u.loyalty != nil {
+			if err := u.loyalty.ReverseOrder(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+		if u.stamps != nil {
+			if err := u.stamps.ReverseOrder(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+		if u.giftCards != nil {
+			return u.giftCards.RefundOrder(ctx, order.ID)
+		}
+	}
+	return nil
+}
+
+func (u *orderUsecase) AddItem(ctx context.Context, orderID uuid.UUID, item *domain.OrderItem) (*domain.Order, error) {
+	return u.editItems(ctx, orderID, func(order *domain.Order) error {
+		return u.addLine(ctx, order, item)
+	})
+}
+
+func (u *orderUsecase) AddRound(ctx context.Context, orderID uuid.UUID, items []domain.OrderItem) (*domain.Order, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrderItems
+	}
+	return u.editItems(ctx, orderID, func(order *domain.Order) error {
+		for i := range items {
+			if err := u.addLine(ctx, order, &items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (u *orderUsecase) addLine(ctx context.Context, order *domain.Order, item *domain.OrderItem) error {
+	item.StampProgramID = nil
+	if _, err := u.priceLine(ctx, order.ID, item); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrMenuItemNotFound
+		}
+		return err
+	}
+	order.Items = append(order.Items, *item)
+	return nil
+}
+
+func (u *orderUsecase) UpdateItemQuantity(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (*domain.Order, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidOrderQuantity
+	}
+	return u.editItems(ctx, orderID, func(order *domain.Order) error {
+		i, err := editableLine(order, itemID)
+		if err != nil {
+			return err
+		}
+		order.Items[i].Quantity = quantity
+		order.Items[i].LineTotal = order.Items[i].UnitPrice.Mul(decimal.NewFromInt(int64(quantity)))
+		return nil
+	})
+}
+
+// UpdateItemNote changes only the note, so free reward lines can take one too.
+func (u *orderUsecase) UpdateItemNote(ctx context.Context, orderID, itemID uuid.UUID, note string) (*domain.Order, error) {
+	note, err := sanitizeNote(note)
+	if err != nil {
+		return nil, err
+	}
+	return u.editItems(ctx, orderID, func(order *domain.Order) error {
+		for i := range order.Items {
+			if order.Items[i].ID == itemID {
+				order.Items[i].Note = note
+				return nil
+			}
+		}
+		return ErrOrderItemNotFound
+	})
+}
+
+func (u *orderUsecase) RemoveItem(ctx context.Context, orderID, itemID uuid.UUID) (*domain.Order, error) {
+	return u.editItems(ctx, orderID, func(order *domain.Order) error {
+		i, err := editableLine(order, itemID)
+		if err != nil {
+			return err
+		}
+		order.Items = append(order.Items[:i], order.Items[i+1:]...)
+		return nil
+	})
+}
+
+// editItems applies edit to the lines of a pending order and reprices it the
+// way Create does. Lines already on the order keep the unit price they were
+// rung up at.
+func (u *orderUsecase) editItems(ctx context.Context, orderID uuid.UUID, edit func(order *domain.Order) error) (*domain.Order, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return nil, err
+	}
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotEditable
+	}
+	if order.RedeemedPoints > 0 && u.loyalty == nil {
+		return nil, ErrLoyaltyDisabled
+	}
+	// A part payment was taken against the current total; changing the lines
+	// under it could leave the order overpaid. A payment taken after this
+	// check bumps the order's version, so the write below is refused.
+	if u.paymentRepo != nil {
+		payments, err := u.paymentRepo.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(payments) > 0 {
+			return nil, ErrOrderHasPayments
+		}
+	}
+
+	previousSubtotal := order.Subtotal
+	// A pre-order still held for later has its place in the pickup slot
+	// checked again if the edit adds to it.
+	held := order.PickupAt != nil && order.ReleasedAt == nil && u.preorders != nil && u.preorders.SlotCapacity > 0
+	previousUnits := decimal.Zero
+	if held {
+		if previousUnits, err = u.slotUnits(ctx, order.Items); err != nil {
+			return nil, err
+		}
+	}
+	if err := edit(order); err != nil {
+		return nil, err
+	}
+	if len(order.Items) == 0 {
+		return nil, ErrEmptyOrderItems
+	}
+	if held {
+		units, err := u.slotUnits(ctx, order.Items)
+		if err != nil {
+			return nil, err
+		}
+		if units.GreaterThan(previousUnits) {
+			if err := u.bookSlot(ctx, order); err != nil {
+				return nil, err
+			}
+		}
+	}
+	giftCardItems, err := u.giftCardItems(ctx, order.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.priceTotals(order, giftCardItems); err != nil {
+		return nil, err
+	}
+	// A smaller order makes the same manual discount a larger share of it.
+	if order.ManualDiscount.IsPositive() && order.Subtotal.LessThan(previousSubtotal) {
+		if order.Approval, err = u.approveDiscount(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	order.UpdatedAt = time.Now()
+	if err := u.orderRepo.UpdateItems(ctx, order, order.Version); err != nil {
+		return nil, versionedWriteErr(ctx, err)
+	}
+	return order, nil
+}
+
+func (u *orderUsecase) OpenTab(ctx context.Context, orderID uuid.UUID, name string) (*domain.Order, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if !validCallOutName(name) {
+		return nil, ErrInvalidTabName
+	}
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotEditable
+	}
+	if order.TabOpenedAt != nil {
+		return nil, ErrTabAlreadyOpen
+	}
+	if name == "" && order.TableNumber == "" {
+		return nil, ErrTabNameRequired
+	}
+
+	now := time.Now()
+	order.TabName = name
+	order.TabOpenedAt = &now
+	order.UpdatedAt = now
+	if err := u.orderRepo.OpenTab(ctx, order, order.Version); err != nil {
+		return nil, versionedWriteErr(ctx, err)
+	}
+	return order, nil
+}
+
+// editableLine finds the line itemID on the order. Free lines given by a stamp
+// card were redeemed when the order was created, so they cannot be changed.
+func editableLine(order *domain.Order, itemID uuid.UUID) (int, error) {
+	for i, item := range order.Items {
+		if item.ID != itemID {
+			continue
+		}
+		if item.StampProgramID != nil {
+			return -1, ErrRewardLineNotEditable
+		}
+		return i, nil
+	}
+	return -1, ErrOrderItemNotFound
+}
+
+// giftCardItems reports which of the menu items on the lines are gift cards.
+func (u *orderUsecase) giftCardItems(ctx context.Context, items []domain.OrderItem) (map[uuid.UUID]bool, error) {
+	giftCardItems := make(map[uuid.UUID]bool)
+	seen := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		if seen[item.MenuItemID] {
+			continue
+		}
+		seen[item.MenuItemID] = true
+		menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return nil, err
+		}
+		if menuItem != nil && strings.EqualFold(menuItem.Category, domain.GiftCardCategory) {
+			giftCardItems[item.MenuItemID] = true
+		}
+	}
+	return giftCardItems, nil
+}
+
+func (u *orderUsecase) VoidItem(ctx context.Context, orderID, itemID uuid.UUID, reason string) (*domain.Order, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrVoidReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > maxStatusReasonLength {
+		return nil, ErrVoidReasonTooLong
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return nil, err
+	}
+	if u.overrides == nil {
+		if err := domain.Authorize(ctx, domain.PermOrdersRefund); err != nil {
+			return nil, err
+		}
+	}
+	if order.Status != domain.OrderStatusPaid && order.Status != domain.OrderStatusCompleted {
+		return nil, ErrOrderNotVoidable
+	}
+	if order.RedeemedPoints > 0 && u.loyalty == nil {
+		return nil, ErrLoyaltyDisabled
+	}
+
+	line := -1
+	remaining := 0
+	for i, item := range order.Items {
+		if item.ID == itemID {
+			line = i
+		} else if item.VoidedAt == nil {
+			remaining++
+		}
+	}
+	if line < 0 {
+		return nil, ErrOrderItemNotFound
+	}
+	item := &order.Items[line]
+	if item.VoidedAt != nil {
+		return nil, ErrLineAlreadyVoided
+	}
+	giftCardItems, err := u.giftCardItems(ctx, order.Items)
+	if err != nil {
+		return nil, err
+	}
+	if giftCardItems[item.MenuItemID] {
+		return nil, ErrGiftCardLineNotVoid
+	}
+	if remaining == 0 {
+		return nil, ErrVoidLastLine
+	}
+	// The override is only asked for once the void itself is known to be valid.
+	if u.overrides != nil {
+		if order.Approval, err = u.overrides.Approve(ctx, domain.OverrideVoidLine, orderID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	previousTotal := order.Total
+	item.VoidedAt = &now
+	item.VoidReason = reason
+	if identity, ok := domain.IdentityFromContext(ctx); ok {
+		item.VoidedBy = identity.StaffRef()
+	}
+	if err := u.priceTotals(order, giftCardItems); err != nil {
+		return nil, err
+	}
+	order.UpdatedAt = now
+	void := &domain.LineVoid{}
+	if void.Refunds, err = u.allocateRefund(ctx, order.ID, item.ID, previousTotal.Sub(order.Total), reason, now); err != nil {
+		return nil, err
+	}
+	// Points and stamps the line earned are taken back in the same
+	// transaction as the void.
+	if u.loyalty != nil {
+		if void.Points, err = u.loyalty.ReverseLine(ctx, order, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if u.stamps != nil {
+		if void.Stamps, err = u.stamps.ReverseLine(ctx, order, item.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	order.Audit, err = u.auditEntry(ctx, domain.AuditOrderItemVoid, order.ID, map[string]interface{}{"total": previousTotal},
+		map[string]interface{}{"item_id": item.ID, "void_reason": reason, "total": order.Total})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.orderRepo.VoidItem(ctx, order, item, void, order.Version); err != nil {
+		return nil, versionedWriteErr(ctx, err)
+	}
+	return order, nil
+}
+
+// allocateRefund spreads amount over the order's payments, newest first, so a
+// void is refunded to whatever was used last and never refunds a payment more
+// than it paid.
+func (u *orderUsecase) allocateRefund(ctx context.Context, orderID, itemID uuid.UUID, amount decimal.Decimal, reason string, at time.Time) ([]domain.Refund, error) {
+	if u.paymentRepo == nil || !amount.IsPositive() {
+		return nil, nil
+	}
+	payments, err := u.paymentRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := u.paymentRepo.ListRefunds(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	refunded := make(map[uuid.UUID]decimal.Decimal)
+	for _, refund := range previous {
+		refunded[refund.PaymentID] = refunded[refund.PaymentID].Add(refund.Amount)
+	}
+
+	var refunds []domain.Refund
+	for i := len(payments) - 1; i >= 0 && amount.IsPositive(); i-- {
+		payment := payments[i]
+		share := decimal.Min(amount, payment.Amount.Sub(refunded[payment.ID]))
+		if !share.IsPositive() {
+			continue
+		}
+		refunds = append(refunds, domain.Refund{
+			ID:          uuid.New(),
+			OrderID:     orderID,
+			PaymentID:   payment.ID,
+			OrderItemID: &itemID,
+			Tender:      payment.Tender,
+			Amount:      share,
+			GiftCardID:  payment.GiftCardID,
+			Reason:      reason,
+			CreatedAt:   at,
+		})
+		amount = amount.Sub(share)
+	}
+	return refunds, nil
+}
+
+func (u *orderUsecase) Split(ctx context.Context, orderID uuid.UUID, splits []domain.OrderSplit) ([]domain.Order, error) {
+	if len(splits) < 2 || len(splits) > maxSplits {
+		return nil, ErrInvalidSplitCount
+	}
+	return u.split(ctx, orderID, func(order *domain.Order, giftCardItems map[uuid.UUID]bool) ([][]domain.OrderItem, error) {
+		lines := make(map[uuid.UUID]domain.OrderItem)
+		for _, item := range order.Items {
+			if item.VoidedAt == nil {
+				lines[item.ID] = item
+			}
+		}
+		// assigned holds, for each line, the quantity given to each split.
+		assigned := make(map[uuid.UUID][]decimal.Decimal)
+		for n, split := range splits {
+			if len(split.Items) == 0 {
+				return nil, ErrEmptyOrderItems
+			}
+			for _, splitItem := range split.Items {
+				line, ok := lines[splitItem.ItemID]
+				if !ok {
+					return nil, ErrOrderItemNotFound
+				}
+				quantity := splitItem.Quantity
+				if quantity.IsZero() {
+					quantity = decimal.NewFromInt(int64(line.Quantity))
+				}
+				if !quantity.IsPositive() || !quantity.Equal(quantity.Round(3)) {
+					return nil, ErrInvalidSplitQuantity
+				}
+				if assigned[line.ID] == nil {
+					assigned[line.ID] = make([]decimal.Decimal, len(splits))
+				}
+				assigned[line.ID][n] = assigned[line.ID][n].Add(quantity)
+			}
+		}
+
+		children := make([][]domain.OrderItem, len(splits))
+		for _, item := range order.Items {
+			if item.VoidedAt != nil {
+				continue
+			}
+			quantities := assigned[item.ID]
+			total, parts := decimal.Zero, 0
+			for _, quantity := range quantities {
+				total = total.Add(quantity)
+				if quantity.IsPositive() {
+					parts++
+				}
+			}
+			if !total.Equal(decimal.NewFromInt(int64(item.Quantity))) {
+				return nil, ErrSplitIncomplete
+			}
+			if parts > 1 && giftCardItems[item.MenuItemID] {
+				return nil, ErrSplitGiftCardLine
+			}
+			amounts := allocateAmount(item.LineTotal, quantities)
+			for n, quantity := range quantities {
+				if quantity.IsPositive() {
+					children[n] = append(children[n], splitLine(item, quantity, amounts[n]))
+				}
+			}
+		}
+		return children, nil
+	})
+}
+
+func (u *orderUsecase) SplitEqually(ctx context.Context, orderID uuid.UUID, shares int) ([]domain.Order, error) {
+	if shares < 2 || shares > maxSplits {
+		return nil, ErrInvalidSplitCount
+	}
+	return u.split(ctx, orderID, func(order *domain.Order, giftCardItems map[uuid.UUID]bool) ([][]domain.OrderItem, error) {
+		children := make([][]domain.OrderItem, shares)
+		for _, item := range order.Items {
+			if item.VoidedAt != nil {
+				continue
+			}
+			if giftCardItems[item.MenuItemID] {
+				return nil, ErrSplitGiftCardLine
+			}
+			whole := decimal.NewFromInt(1)
+			if item.Share != nil {
+				whole = *item.Share
+			}
+			parts := equalShares(whole, shares)
+			if !parts[0].IsPositive() {
+				return nil, ErrInvalidSplitQuantity
+			}
+			amounts := allocateAmount(item.LineTotal, parts)
+			for n := range children {
+				line := item
+				line.ID = uuid.New()
+				line.LineTotal = amounts[n]
+				if units := decimal.NewFromInt(int64(item.Quantity)).Mul(parts[n]); item.Share == nil && units.IsInteger() {
+					line.Quantity = int(units.IntPart())
+				} else {
+					line.Share = &parts[n]
+				}
+				children[n] = append(children[n], line)
+			}
+		}
+		return children, nil
+	})
+}
+
+// split replaces a pending order with new pending orders holding the lines
+// assign gives them. Each is priced on its own, and rounding differences are
+// then moved between their taxes and service charges so that their totals add
+// up to the order's total.
+func (u *orderUsecase) split(ctx context.Context, orderID uuid.UUID, assign func(order *domain.Order, giftCardItems map[uuid.UUID]bool) ([][]domain.OrderItem, error)) ([]domain.Order, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return nil, err
+	}
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotEditable
+	}
+	if order.RedeemedPoints > 0 {
+		return nil, ErrSplitRedeemedPoints
+	}
+	if u.paymentRepo != nil {
+		payments, err := u.paymentRepo.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(payments) > 0 {
+			return nil, ErrSplitHasPayments
+		}
+	}
+	giftCardItems, err := u.giftCardItems(ctx, order.Items)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := assign(order, giftCardItems)
+	if err != nil {
+		return nil, err
+	}
+	// The stamp cards spent on the free lines move to the split order that
+	// takes them, so it can give them back if it is cancelled.
+	rewards := make(map[uuid.UUID]int)
+	for n, items := range lines {
+		for _, item := range items {
+			if item.StampProgramID == nil {
+				continue
+			}
+			if m, ok := rewards[*item.StampProgramID]; item.Share != nil || ok && m != n {
+				return nil, ErrSplitRewardLine
+			}
+			rewards[*item.StampProgramID] = n
+		}
+	}
+
+	now := time.Now()
+	children := make([]domain.Order, len(lines))
+	subtotals := make([]decimal.Decimal, len(lines))
+	for n := range children {
+		child := &children[n]
+		*child = domain.Order{
+			ID:            uuid.New(),
+			StoreCode:     u.numbering.StoreCode,
+			BusinessDate:  u.numbering.BusinessDate(now),
+			Status:        domain.OrderStatusPending,
+			OrderType:     order.OrderType,
+			TableNumber:   order.TableNumber,
+			CallOutName:   order.CallOutName,
+			Note:          order.Note,
+			PickupAt:      order.PickupAt,
+			ReleasedAt:    order.ReleasedAt,
+			ParentOrderID: &order.ID,
+			CustomerID:    order.CustomerID,
+			CashierID:     order.CashierID,
+			TerminalID:    order.TerminalID,
+			Items:         lines[n],
+			Version:       1,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		for i := range child.Items {
+			child.Items[i].OrderID = child.ID
+			subtotals[n] = subtotals[n].Add(child.Items[i].LineTotal)
+		}
+		child.StatusHistory = []domain.OrderStatusChange{*newStatusChange(ctx, child.ID, nil, domain.OrderStatusPending, "split from "+order.Reference, now)}
+	}
+	discounts := allocateAmount(order.ManualDiscount, subtotals)
+	for n := range children {
+		children[n].ManualDiscount = discounts[n]
+		if err := u.priceTotals(&children[n], giftCardItems); err != nil {
+			return nil, err
+		}
+	}
+	if err := reconcileSplitTotals(order.Total, children); err != nil {
+		return nil, err
+	}
+
+	from := order.Status
+	change := newStatusChange(ctx, order.ID, &from, domain.OrderStatusSplit, fmt.Sprintf("split into %d orders", len(children)), now)
+	if err := u.orderRepo.Split(ctx, change, order.Version, children); err != nil {
+		return nil, versionedWriteErr(ctx, err)
+	}
+	return children, nil
+}
+
+// splitLine copies line for a split order that pays for quantity of it. Whole
+// units keep an integer quantity; anything else becomes a share of the line.
+func splitLine(line domain.OrderItem, quantity, lineTotal decimal.Decimal) domain.OrderItem {
+	line.ID = uuid.New()
+	line.LineTotal = lineTotal
+	if line.Share == nil && quantity.IsInteger() {
+		line.Quantity = int(quantity.IntPart())
+		return line
+	}
+	share := quantity.DivRound(decimal.NewFromInt(int64(line.Quantity)), 6)
+	if line.Share != nil {
+		share = share.Mul(*line.Share).Round(6)
+	}
+	line.Share = &share
+	return line
+}
+
+// allocateAmount divides amount in proportion to weights, in whole cents that
+// add up to it exactly. Leftover cents go to the largest remainders.
+func allocateAmount(amount decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	parts := make([]decimal.Decimal, len(weights))
+	total := decimal.Zero
+	for _, weight := range weights {
+		total = total.Add(weight)
+	}
+	if !total.IsPositive() {
+		return parts
+	}
+
+	cents := amount.Shift(2).Round(0)
+	remainders := make([]decimal.Decimal, len(weights))
+	given := decimal.Zero
+	for i, weight := range weights {
+		exact := cents.Mul(weight).Div(total)
+		whole := exact.Floor()
+		parts[i] = whole.Shift(-2)
+		remainders[i] = exact.Sub(whole)
+		given = given.Add(whole)
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].GreaterThan(remainders[order[b]]) })
+	for k := 0; k < int(cents.Sub(given).IntPart()); k++ {
+		i := order[k%len(order)]
+		parts[i] = parts[i].Add(decimal.New(1, -2))
+	}
+	return parts
+}
+
+// equalShares divides whole into n shares of a line, to the 6 decimal places
+// a share is stored with. The last share takes what rounding leaves over, so
+// the shares add up to whole exactly.
+func equalShares(whole decimal.Decimal, n int) []decimal.Decimal {
+	parts := make([]decimal.Decimal, n)
+	part := whole.Div(decimal.NewFromInt(int64(n))).Truncate(6)
+	for i := range parts {
+		parts[i] = part
+	}
+	parts[n-1] = whole.Sub(part.Mul(decimal.NewFromInt(int64(n - 1))))
+	return parts
+}
+
+// reconcileSplitTotals moves the cents lost or gained by rounding each split
+// order's tax and service charge on their own onto the largest of those
+// charges, so the split totals add up to total. It returns ErrSplitUnbalanced
+// if there is no charge left to take a cent from.
+func reconcileSplitTotals(total decimal.Decimal, children []domain.Order) error {
+	diff := total
+	for _, child := range children {
+		diff = diff.Sub(child.Total)
+	}
+	for !diff.IsZero() {
+		step := decimal.New(1, -2)
+		if diff.IsNegative() {
+			step = step.Neg()
+		}
+		var charge *decimal.Decimal
+		var owner *domain.Order
+		for n := range children {
+			for _, c := range []*decimal.Decimal{&children[n].Tax, &children[n].ServiceCharge} {
+				if charge == nil || c.GreaterThan(*charge) {
+					charge, owner = c, &children[n]
+				}
+			}
+		}
+		if charge == nil || (step.IsNegative() && !charge.IsPositive()) {
+			return ErrSplitUnbalanced
+		}
+		*charge = charge.Add(step)
+		owner.Total = owner.Total.Add(step)
+		diff = diff.Sub(step)
+	}
+	return nil
+}
+
+func (u *orderUsecase) Merge(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) (*domain.Order, error) {
+	if len(sourceIDs) == 0 || len(sourceIDs) > maxSplits {
+		return nil, ErrInvalidMergeCount
+	}
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return nil, err
+	}
+	target, err := u.orderRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, target.Version); err != nil {
+		return nil, err
+	}
+	if target.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotEditable
+	}
+	if target.RedeemedPoints > 0 && u.loyalty == nil {
+		return nil, ErrLoyaltyDisabled
+	}
+
+	// A stamp card pays for at most one free line per order, so the rewards
+	// redeemed for the orders being merged must come from different cards.
+	rewards := make(map[uuid.UUID]bool)
+	for _, item := range target.Items {
+		if item.StampProgramID != nil {
+			rewards[*item.StampProgramID] = true
+		}
+	}
+	seen := map[uuid.UUID]bool{targetID: true}
+	sources := make([]domain.Order, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if seen[id] {
+			return nil, ErrInvalidMergeSource
+		}
+		seen[id] = true
+		source, err := u.orderRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, domain.ErrNotFound
+		}
+		if source.Status != domain.OrderStatusPending {
+			return nil, ErrOrderNotEditable
+		}
+		if source.RedeemedPoints > 0 {
+			return nil, ErrMergeRedeemedPoints
+		}
+		for _, item := range source.Items {
+			if item.StampProgramID == nil {
+				continue
+			}
+			if rewards[*item.StampProgramID] {
+				return nil, ErrMergeStampRewards
+			}
+			rewards[*item.StampProgramID] = true
+		}
+		if source.CustomerID != nil {
+			if target.CustomerID != nil && *target.CustomerID != *source.CustomerID {
+				return nil, ErrMergeCustomerMismatch
+			}
+			target.CustomerID = source.CustomerID
+		}
+		if u.paymentRepo != nil {
+			payments, err := u.paymentRepo.ListByOrder(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if len(payments) > 0 {
+				return nil, ErrMergeHasPayments
+			}
+		}
+		sources = append(sources, *source)
+	}
+
+	previousTotal := target.Total
+	previousDiscount := target.ManualDiscount
+	now := time.Now()
+	from := domain.OrderStatusPending
+	changes := make([]domain.OrderStatusChange, len(sources))
+	var merged []domain.OrderItem
+	for i := range sources {
+		for _, item := range sources[i].Items {
+			item.OrderID = target.ID
+			merged = append(merged, item)
+		}
+		target.ManualDiscount = target.ManualDiscount.Add(sources[i].ManualDiscount)
+		target.Note = joinNotes(target.Note, sources[i].Note)
+		sources[i].MergedIntoID = &target.ID
+		changes[i] = *newStatusChange(ctx, sources[i].ID, &from, domain.OrderStatusMerged, "merged into "+target.Reference, now)
+	}
+	target.Items = append(target.Items, merged...)
+	// A target still held for later takes the merged lines into its pickup
+	// slot, so the slot is checked again like an edit adding to it.
+	if target.PickupAt != nil && target.ReleasedAt == nil && u.preorders != nil && u.preorders.SlotCapacity > 0 {
+		added, err := u.slotUnits(ctx, merged)
+		if err != nil {
+			return nil, err
+		}
+		if added.IsPositive() {
+			if err := u.bookSlot(ctx, target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	giftCardItems, err := u.giftCardItems(ctx, target.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.priceTotals(target, giftCardItems); err != nil {
+		return nil, err
+	}
+	// The merged order gives the discounts of all the orders at once.
+	if target.ManualDiscount.GreaterThan(previousDiscount) {
+		if target.Approval, err = u.approveDiscount(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	target.UpdatedAt = now
+	target.Audit, err = u.auditEntry(ctx, domain.AuditOrderMerge, target.ID, map[string]interface{}{"total": previousTotal},
+		map[string]interface{}{"merged_order_ids": sourceIDs, "total": target.Total})
+	if err != nil {
+		return nil, err
+	}
+	if err := u.orderRepo.Merge(ctx, target, target.Version, sources, changes); err != nil {
+		return nil, versionedWriteErr(ctx, err)
+	}
+	return target, nil
+}
+
+func (u *orderUsecase) Transfer(ctx context.Context, orderID uuid.UUID, tableNumber string) (*domain.Order, error) {
+	if err := domain.Authorize(ctx, domain.PermOrdersCreate); err != nil {
+		return nil, err
+	}
+	tableNumber = strings.TrimSpace(tableNumber)
+	if tableNumber == "" {
+		return nil, ErrTableNumberRequired
+	}
+	if !tableNumberPattern.MatchString(tableNumber) {
+		return nil, ErrInvalidTableNumber
+	}
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckIfMatch(ctx, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotEditable
+	}
+	if order.TableNumber == tableNumber {
+		return order, nil
+	}
+
+	previous := order.TableNumber
+	order.TableNumber = tableNumber
+	order.UpdatedAt = time.Now()
+	order.Audit, err = u.auditEntry(ctx, domain.AuditOrderTransfer, order.ID,
+		map[string]string{"table_number": previous}, map[string]string{"table_number": tableNumber})
+	if err != nil {
+		return nil, err
+	}
+	if err := u.orderRepo.UpdateTable(ctx, order, order.Version); err != nil {
+		return nil, versionedWriteErr(ctx, err)
 	}
-	return err
+	return order, nil
 }
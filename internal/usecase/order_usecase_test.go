@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,14 +29,53 @@ func (m *mockOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Orde
 	}
 	return args.Get(0).(*domain.Order), args.Error(1)
 }
-func (m *mockOrderRepo) List(ctx context.Context) ([]domain.Order, error) {
-	args := m.Called(ctx)
+func (m *mockOrderRepo) List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).([]domain.Order), args.Error(1)
 }
-func (m *mockOrderRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time) error {
-	args := m.Called(ctx, id, status, updatedAt)
+func (m *mockOrderRepo) UpdateStatus(ctx context.Context, change *domain.OrderStatusChange, version int64) error {
+	args := m.Called(ctx, change, version)
 	return args.Error(0)
 }
+func (m *mockOrderRepo) UpdateItems(ctx context.Context, order *domain.Order, version int64) error {
+	args := m.Called(ctx, order, version)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) VoidItem(ctx context.Context, order *domain.Order, item *domain.OrderItem, void *domain.LineVoid, version int64) error {
+	args := m.Called(ctx, order, item, void, version)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) OpenTab(ctx context.Context, order *domain.Order, version int64) error {
+	args := m.Called(ctx, order, version)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) Split(ctx context.Context, change *domain.OrderStatusChange, version int64, children []domain.Order) error {
+	args := m.Called(ctx, change, version, children)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) Merge(ctx context.Context, target *domain.Order, version int64, sources []domain.Order, changes []domain.OrderStatusChange) error {
+	args := m.Called(ctx, target, version, sources, changes)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) UpdateTable(ctx context.Context, order *domain.Order, version int64) error {
+	args := m.Called(ctx, order, version)
+	return args.Error(0)
+}
+func (m *mockOrderRepo) ReleaseScheduled(ctx context.Context, before, now time.Time) (int64, error) {
+	args := m.Called(ctx, before, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *mockOrderRepo) StatusHistory(ctx context.Context, orderID uuid.UUID) ([]domain.OrderStatusChange, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.OrderStatusChange), args.Error(1)
+}
+
+// statusChange matches a status change of an order to status.
+func statusChange(orderID uuid.UUID, status string) interface{} {
+	return mock.MatchedBy(func(change *domain.OrderStatusChange) bool {
+		return change.OrderID == orderID && change.ToStatus == status
+	})
+}
 
 func (m *mockMenuRepository) Create(ctx context.Context, item *domain.MenuItem) error { return nil }
 func (m *mockMenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MenuItem, error) {
@@ -47,7 +87,7 @@ func (m *mockMenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain
 }
 func (m *mockMenuRepository) Fetch(ctx context.Context) ([]domain.MenuItem, error)    { return nil, nil }
 func (m *mockMenuRepository) Update(ctx context.Context, item *domain.MenuItem) error { return nil }
-func (m *mockMenuRepository) Delete(ctx context.Context, id uuid.UUID) error          { return nil }
+func (m *mockMenuRepository) Delete(ctx context.Context, item *domain.MenuItem) error { return nil }
 
 func TestOrderUsecase_Create(t *testing.T) {
 	orderRepo := new(mockOrderRepo)
@@ -59,7 +99,7 @@ func TestOrderUsecase_Create(t *testing.T) {
 	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(5.50)}, nil)
 	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
 
-	err := u.Create(context.Background(), order)
+	err := u.Create(managerCtx(), order)
 
 	assert.NoError(t, err)
 	assert.Equal(t, decimal.NewFromFloat(11).StringFixed(2), order.Subtotal.StringFixed(2))
@@ -69,15 +109,302 @@ func TestOrderUsecase_Create(t *testing.T) {
 	menuRepo.AssertExpectations(t)
 }
 
+func TestOrderUsecase_Create_NumbersByStoreAndBusinessDay(t *testing.T) {
+	numbering, err := ParseOrderNumberConfig("B2", "Europe/Berlin", "4h")
+	assert.NoError(t, err)
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithOrderNumbering(numbering))
+
+	menuID := uuid.New()
+	order := &domain.Order{Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(3)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.Equal(t, "B2", order.StoreCode)
+	assert.Equal(t, numbering.BusinessDate(order.CreatedAt), order.BusinessDate)
+
+	// 02:30 in Berlin is still the previous business day; 04:00 starts a new one.
+	berlin := numbering.Location
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), numbering.BusinessDate(time.Date(2026, 10, 18, 2, 30, 0, 0, berlin)))
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), numbering.BusinessDate(time.Date(2026, 10, 18, 4, 0, 0, 0, berlin)))
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), numbering.BusinessDate(time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)))
+}
+
+func TestOrderUsecase_Create_ChargesByOrderType(t *testing.T) {
+	dineIn, err := ParseOrderTypeRule("0.20", "0.125")
+	assert.NoError(t, err)
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithOrderTypeRules(map[string]OrderTypeRule{domain.OrderTypeDineIn: dineIn}))
+
+	menuID := uuid.New()
+	order := &domain.Order{
+		OrderType:   domain.OrderTypeDineIn,
+		TableNumber: " 12 ",
+		CallOutName: " Sam ",
+		Items:       []domain.OrderItem{{MenuItemID: menuID, Quantity: 2}},
+	}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(5)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.Equal(t, "12", order.TableNumber)
+	assert.Equal(t, "Sam", order.CallOutName)
+	assert.Equal(t, "2.00", order.Tax.StringFixed(2))
+	assert.Equal(t, "1.25", order.ServiceCharge.StringFixed(2))
+	assert.Equal(t, "13.25", order.Total.StringFixed(2))
+
+	// Takeaway keeps the default rule.
+	takeaway := &domain.Order{Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 2}}}
+	assert.NoError(t, u.Create(managerCtx(), takeaway))
+	assert.Equal(t, domain.OrderTypeTakeaway, takeaway.OrderType)
+	assert.Equal(t, "1.00", takeaway.Tax.StringFixed(2))
+	assert.True(t, takeaway.ServiceCharge.IsZero())
+}
+
+func TestOrderUsecase_Create_InvalidOrderDetails(t *testing.T) {
+	u := NewOrderUsecase(new(mockOrderRepo), new(mockMenuRepository))
+	items := []domain.OrderItem{{MenuItemID: uuid.New(), Quantity: 1}}
+
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{OrderType: "drive_thru", Items: items}), ErrInvalidOrderType)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{TableNumber: "table 12", Items: items}), ErrInvalidTableNumber)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{CallOutName: "Sam\tLee", Items: items}), ErrInvalidCallOutName)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{CallOutName: strings.Repeat("a", 51), Items: items}), ErrInvalidCallOutName)
+}
+
+func TestOrderUsecase_Create_SanitizesNotes(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+
+	menuID := uuid.New()
+	order := &domain.Order{
+		Note:  " Birthday,\r\n bring\ta candle ",
+		Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1, Note: "extra hot\x1b@ \u200bno lid"}},
+	}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(5)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.Equal(t, "Birthday, bring a candle", order.Note)
+	assert.Equal(t, "extra hot@ no lid", order.Items[0].Note)
+
+	long := strings.Repeat("a", 201)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{Note: long, Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}), ErrNoteTooLong)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1, Note: long}}}), ErrNoteTooLong)
+	orderRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestParseOrderTypeRule_Invalid(t *testing.T) {
+	for _, args := range [][2]string{{"x", "0"}, {"-0.1", "0"}, {"0.1", "1.5"}} {
+		_, err := ParseOrderTypeRule(args[0], args[1])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestParseOrderNumberConfig_Invalid(t *testing.T) {
+	for _, args := range [][3]string{
+		{"a", "UTC", "0h"},
+		{"TOOLONGCODE1", "UTC", "0h"},
+		{"A", "Mars/Olympus", "0h"},
+		{"A", "UTC", "24h"},
+		{"A", "UTC", "-1h"},
+	} {
+		_, err := ParseOrderNumberConfig(args[0], args[1], args[2])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestOrderUsecase_Create_SnapshotsUnitCost(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	inventoryRepo := new(mockInventoryRepo)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithInventoryRepository(inventoryRepo))
+
+	menuID := uuid.New()
+	order := &domain.Order{Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(4)}, nil)
+	inventoryRepo.On("GetMenuItemCost", mock.Anything, menuID).Return(decimal.NewFromFloat(0.87654), nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	err := u.Create(managerCtx(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0.8765", order.Items[0].UnitCost.String())
+}
+
+func TestOrderUsecase_Create_CustomerNotFound(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	customerRepo := new(mockCustomerRepo)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithCustomerRepository(customerRepo))
+
+	customerID := uuid.New()
+	customerRepo.On("GetByID", mock.Anything, customerID).Return(nil, nil)
+
+	err := u.Create(managerCtx(), &domain.Order{
+		CustomerID: &customerID,
+		Items:      []domain.OrderItem{{MenuItemID: uuid.New(), Quantity: 1}},
+	})
+
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+	orderRepo.AssertNotCalled(t, "Create")
+}
+
+func TestOrderUsecase_Create_RedeemsPoints(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	loyaltyRepo := new(mockLoyaltyRepo)
+	loyalty := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, testLoyaltyConfig())
+	u := NewOrderUsecase(orderRepo, menuRepo, WithLoyaltyUsecase(loyalty))
+
+	customerID := uuid.New()
+	menuID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(5)}, nil)
+	loyaltyRepo.On("Redeem", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Points == -200 && e.CustomerID == customerID
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	order := &domain.Order{CustomerID: &customerID, RedeemedPoints: 200, Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 2}}}
+	err := u.Create(managerCtx(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2.00", order.Discount.StringFixed(2))
+	assert.Equal(t, "0.80", order.Tax.StringFixed(2))
+	assert.Equal(t, "8.80", order.Total.StringFixed(2))
+	loyaltyRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_Create_RedeemErrors(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	loyaltyRepo := new(mockLoyaltyRepo)
+	loyalty := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, testLoyaltyConfig())
+	u := NewOrderUsecase(orderRepo, menuRepo, WithLoyaltyUsecase(loyalty))
+
+	customerID := uuid.New()
+	menuID := uuid.New()
+	items := []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(5)}, nil)
+
+	err := u.Create(managerCtx(), &domain.Order{RedeemedPoints: 10, Items: items})
+	assert.ErrorIs(t, err, ErrRedeemNeedsCustomer)
+
+	err = u.Create(managerCtx(), &domain.Order{CustomerID: &customerID, RedeemedPoints: 1000, Items: items})
+	assert.ErrorIs(t, err, ErrRedeemExceedsTotal)
+
+	loyaltyRepo.On("Redeem", mock.Anything, mock.Anything).Return(domain.ErrInsufficientPoints)
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, mock.Anything).Return([]domain.LoyaltyEntry{}, nil)
+	err = u.Create(managerCtx(), &domain.Order{CustomerID: &customerID, RedeemedPoints: 100, Items: items})
+	assert.ErrorIs(t, err, domain.ErrInsufficientPoints)
+
+	orderRepo.AssertNotCalled(t, "Create")
+}
+
+func TestOrderUsecase_Create_AppliesFullStampCard(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	stampRepo := new(mockStampRepo)
+	stamps := NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithStampUsecase(stamps))
+
+	customerID := uuid.New()
+	programID := uuid.New()
+	menuID := uuid.New()
+	program := domain.StampProgram{ID: programID, Categories: []string{"Coffee"}, StampsRequired: 9}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Category: "Coffee", Price: decimal.NewFromFloat(4)}, nil)
+	stampRepo.On("ListPrograms", mock.Anything, true).Return([]domain.StampProgram{program}, nil)
+	stampRepo.On("Balances", mock.Anything, customerID).Return(map[uuid.UUID]int{programID: 10}, nil)
+	stampRepo.On("GetProgram", mock.Anything, programID).Return(&program, nil)
+	stampRepo.On("Redeem", mock.Anything, mock.MatchedBy(func(e *domain.StampEntry) bool {
+		return e.Stamps == -9 && e.ProgramID == programID
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	order := &domain.Order{CustomerID: &customerID, Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	err := u.Create(managerCtx(), order)
+
+	assert.NoError(t, err)
+	assert.Len(t, order.Items, 1)
+	assert.Equal(t, programID, *order.Items[0].StampProgramID)
+	assert.True(t, order.Subtotal.IsZero())
+	assert.True(t, order.Total.IsZero())
+	stampRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_Create_GiftCardsAreNotTaxed(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+
+	coffeeID := uuid.New()
+	giftID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee", Price: decimal.NewFromFloat(5)}, nil)
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory, Price: decimal.NewFromFloat(25)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	order := &domain.Order{Items: []domain.OrderItem{{MenuItemID: coffeeID, Quantity: 1}, {MenuItemID: giftID, Quantity: 1}}}
+	err := u.Create(managerCtx(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "30.00", order.Subtotal.StringFixed(2))
+	assert.Equal(t, "0.50", order.Tax.StringFixed(2))
+	assert.Equal(t, "30.50", order.Total.StringFixed(2))
+}
+
+func TestOrderUsecase_Create_GiftCardsAreNotDiscounted(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+
+	coffeeID := uuid.New()
+	giftID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee", Price: decimal.NewFromFloat(5)}, nil)
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory, Price: decimal.NewFromFloat(25)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	// A discount larger than the rest of the order would come off the gift card.
+	order := &domain.Order{
+		ManualDiscount: decimal.NewFromFloat(10),
+		Items:          []domain.OrderItem{{MenuItemID: coffeeID, Quantity: 1}, {MenuItemID: giftID, Quantity: 1}},
+	}
+	assert.ErrorIs(t, u.Create(managerCtx(), order), ErrDiscountExceedsTotal)
+
+	order = &domain.Order{
+		ManualDiscount: decimal.NewFromFloat(2),
+		Items:          []domain.OrderItem{{MenuItemID: coffeeID, Quantity: 1}, {MenuItemID: giftID, Quantity: 1}},
+	}
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.Equal(t, "30.00", order.Subtotal.StringFixed(2))
+	assert.Equal(t, "0.30", order.Tax.StringFixed(2))
+	assert.Equal(t, "28.30", order.Total.StringFixed(2))
+}
+
+func TestOrderUsecase_Create_GiftCardCodeOnRegularItem(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithGiftCardUsecase(NewGiftCardUsecase(new(mockGiftCardRepo), menuRepo, 0)))
+
+	coffeeID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee", Price: decimal.NewFromFloat(5)}, nil)
+
+	err := u.Create(managerCtx(), &domain.Order{Items: []domain.OrderItem{{MenuItemID: coffeeID, Quantity: 1, GiftCardCode: "ABCD"}}})
+	assert.ErrorIs(t, err, ErrGiftCardCodeNotAllowed)
+	orderRepo.AssertNotCalled(t, "Create")
+}
+
 func TestOrderUsecase_Create_ValidationErrors(t *testing.T) {
 	orderRepo := new(mockOrderRepo)
 	menuRepo := new(mockMenuRepository)
 	u := NewOrderUsecase(orderRepo, menuRepo)
 
-	err := u.Create(context.Background(), &domain.Order{})
+	err := u.Create(managerCtx(), &domain.Order{})
 	assert.ErrorIs(t, err, ErrEmptyOrderItems)
 
-	err = u.Create(context.Background(), &domain.Order{Items: []domain.OrderItem{{MenuItemID: uuid.New(), Quantity: 0}}})
+	err = u.Create(managerCtx(), &domain.Order{Items: []domain.OrderItem{{MenuItemID: uuid.New(), Quantity: 0}}})
 	assert.ErrorIs(t, err, ErrInvalidOrderQuantity)
 }
 
@@ -88,13 +415,78 @@ func TestOrderUsecase_UpdateStatus(t *testing.T) {
 	id := uuid.New()
 
 	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
-	orderRepo.On("UpdateStatus", mock.Anything, id, domain.OrderStatusPaid, mock.AnythingOfType("time.Time")).Return(nil)
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusPaid), mock.Anything).Return(nil)
 
-	err := u.UpdateStatus(context.Background(), id, domain.OrderStatusPaid)
+	err := u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, "")
 	assert.NoError(t, err)
 	orderRepo.AssertExpectations(t)
 }
 
+func TestOrderUsecase_UpdateStatus_LoyaltyHooks(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	loyaltyRepo := new(mockLoyaltyRepo)
+	loyalty := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, LoyaltyConfig{PointsPerUnit: decimal.NewFromInt(1)})
+	u := NewOrderUsecase(orderRepo, menuRepo, WithLoyaltyUsecase(loyalty))
+
+	customerID := uuid.New()
+	id := uuid.New()
+	order := &domain.Order{
+		ID:         id,
+		CustomerID: &customerID,
+		Status:     domain.OrderStatusPending,
+		Items:      []domain.OrderItem{{MenuItemID: uuid.New(), LineTotal: decimal.NewFromFloat(7.90)}},
+	}
+	menuRepo.On("GetByID", mock.Anything, order.Items[0].MenuItemID).Return(&domain.MenuItem{Category: "Coffee"}, nil)
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil).Once()
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusPaid), mock.Anything).Return(nil)
+	loyaltyRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Type == domain.LoyaltyEntryEarn && e.Points == 7
+	})).Return(nil)
+
+	assert.NoError(t, u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, ""))
+
+	paid := *order
+	paid.Status = domain.OrderStatusPaid
+	orderRepo.On("GetByID", mock.Anything, id).Return(&paid, nil).Once()
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusCancelled), mock.Anything).Return(nil)
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, id).Return([]domain.LoyaltyEntry{
+		{CustomerID: customerID, Type: domain.LoyaltyEntryEarn, Points: 7},
+	}, nil)
+	loyaltyRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Type == domain.LoyaltyEntryReversal && e.Points == -7
+	})).Return(nil)
+
+	assert.NoError(t, u.UpdateStatus(managerCtx(), id, domain.OrderStatusCancelled, ""))
+	loyaltyRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_UpdateStatus_RetriesSideEffects(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	loyaltyRepo := new(mockLoyaltyRepo)
+	loyalty := NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, LoyaltyConfig{PointsPerUnit: decimal.NewFromInt(1)})
+	u := NewOrderUsecase(orderRepo, menuRepo, WithLoyaltyUsecase(loyalty))
+
+	customerID := uuid.New()
+	id := uuid.New()
+	coffeeID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{
+		ID:         id,
+		CustomerID: &customerID,
+		Status:     domain.OrderStatusPaid,
+		Items:      []domain.OrderItem{{MenuItemID: coffeeID, LineTotal: decimal.NewFromInt(5)}},
+	}, nil)
+	loyaltyRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.LoyaltyEntry) bool {
+		return e.Type == domain.LoyaltyEntryEarn && e.Points == 5
+	})).Return(nil)
+
+	assert.NoError(t, u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, ""))
+	orderRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	loyaltyRepo.AssertExpectations(t)
+}
+
 func TestOrderUsecase_UpdateStatus_InvalidTransition(t *testing.T) {
 	orderRepo := new(mockOrderRepo)
 	menuRepo := new(mockMenuRepository)
@@ -103,7 +495,7 @@ func TestOrderUsecase_UpdateStatus_InvalidTransition(t *testing.T) {
 
 	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
 
-	err := u.UpdateStatus(context.Background(), id, domain.OrderStatusCompleted)
+	err := u.UpdateStatus(managerCtx(), id, domain.OrderStatusCompleted, "")
 	assert.ErrorIs(t, err, ErrInvalidStatusMove)
 }
 
@@ -115,7 +507,7 @@ func TestOrderUsecase_UpdateStatus_NotFound(t *testing.T) {
 
 	orderRepo.On("GetByID", mock.Anything, id).Return(nil, nil)
 
-	err := u.UpdateStatus(context.Background(), id, domain.OrderStatusPaid)
+	err := u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, "")
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
@@ -124,7 +516,7 @@ func TestOrderUsecase_UpdateStatus_InvalidStatus(t *testing.T) {
 	menuRepo := new(mockMenuRepository)
 	u := NewOrderUsecase(orderRepo, menuRepo)
 
-	err := u.UpdateStatus(context.Background(), uuid.New(), "unknown")
+	err := u.UpdateStatus(managerCtx(), uuid.New(), "unknown", "")
 	assert.ErrorIs(t, err, ErrInvalidOrderStatus)
 }
 
@@ -136,8 +528,1065 @@ func TestOrderUsecase_UpdateStatus_RepoError(t *testing.T) {
 	repoErr := errors.New("repo error")
 
 	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
-	orderRepo.On("UpdateStatus", mock.Anything, id, domain.OrderStatusPaid, mock.AnythingOfType("time.Time")).Return(repoErr)
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusPaid), mock.Anything).Return(repoErr)
 
-	err := u.UpdateStatus(context.Background(), id, domain.OrderStatusPaid)
+	err := u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, "")
 	assert.ErrorIs(t, err, repoErr)
 }
+
+func TestOrderUsecase_UpdateStatus_Conflict(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	audit := new(mockAuditUsecase)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithAuditUsecase(audit))
+	id := uuid.New()
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusCancelled), mock.Anything).Return(domain.ErrConflict)
+	audit.On("Entry", mock.Anything, domain.AuditOrderStatusChange, domain.AuditEntityOrder, id, mock.Anything, mock.Anything).
+		Return(&domain.AuditEntry{ID: uuid.New()}, nil)
+
+	err := u.UpdateStatus(managerCtx(), id, domain.OrderStatusCancelled, "")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestOrderUsecase_UpdateStatus_IfMatch(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id := uuid.New()
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending, Version: 4}, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(id, domain.OrderStatusCancelled), int64(4)).Return(nil).Once()
+
+	stale := domain.WithIfMatch(managerCtx(), []int64{3})
+	assert.ErrorIs(t, u.UpdateStatus(stale, id, domain.OrderStatusCancelled, ""), domain.ErrPreconditionFailed)
+	current := domain.WithIfMatch(managerCtx(), []int64{4})
+	assert.NoError(t, u.UpdateStatus(current, id, domain.OrderStatusCancelled, ""))
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_UpdateStatus_RolePermissions(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	pendingID := uuid.New()
+	paidID := uuid.New()
+
+	orderRepo.On("GetByID", mock.Anything, pendingID).Return(&domain.Order{ID: pendingID, Status: domain.OrderStatusPending}, nil)
+	orderRepo.On("GetByID", mock.Anything, paidID).Return(&domain.Order{ID: paidID, Status: domain.OrderStatusPaid}, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*domain.OrderStatusChange"), mock.Anything).Return(nil)
+
+	cashier := staffCtx(domain.RoleCashier)
+	barista := staffCtx(domain.RoleBarista)
+
+	assert.ErrorIs(t, u.UpdateStatus(context.Background(), pendingID, domain.OrderStatusPaid, ""), domain.ErrForbidden)
+	assert.ErrorIs(t, u.UpdateStatus(barista, pendingID, domain.OrderStatusPaid, ""), domain.ErrForbidden)
+	assert.ErrorIs(t, u.UpdateStatus(cashier, paidID, domain.OrderStatusCompleted, ""), domain.ErrForbidden)
+	assert.ErrorIs(t, u.UpdateStatus(cashier, paidID, domain.OrderStatusCancelled, ""), domain.ErrForbidden)
+
+	assert.NoError(t, u.UpdateStatus(cashier, pendingID, domain.OrderStatusPaid, ""))
+	assert.NoError(t, u.UpdateStatus(cashier, pendingID, domain.OrderStatusCancelled, ""))
+	assert.NoError(t, u.UpdateStatus(barista, paidID, domain.OrderStatusCompleted, ""))
+	assert.NoError(t, u.UpdateStatus(managerCtx(), paidID, domain.OrderStatusCancelled, ""))
+}
+
+func TestOrderUsecase_Create_RecordsCashierAndTerminal(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+
+	menuID := uuid.New()
+	terminalID := uuid.New()
+	cashier := &domain.Identity{StaffID: uuid.New(), Username: "sam", Role: domain.RoleCashier, TerminalID: &terminalID}
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(3)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	order := &domain.Order{Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	err := u.Create(domain.WithIdentity(context.Background(), cashier), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, cashier.StaffID, *order.CashierID)
+	assert.Equal(t, terminalID, *order.TerminalID)
+	assert.Len(t, order.StatusHistory, 1)
+	assert.Nil(t, order.StatusHistory[0].FromStatus)
+	assert.Equal(t, cashier.StaffID, *order.StatusHistory[0].ActorID)
+}
+
+func TestOrderUsecase_Create_Forbidden(t *testing.T) {
+	u := NewOrderUsecase(new(mockOrderRepo), new(mockMenuRepository))
+
+	err := u.Create(staffCtx(domain.RoleBarista), &domain.Order{Items: []domain.OrderItem{{MenuItemID: uuid.New(), Quantity: 1}}})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+type mockOverrideUsecase struct{ mock.Mock }
+
+func (m *mockOverrideUsecase) IssueToken(ctx context.Context, action string, entityID *uuid.UUID) (*domain.ApprovalToken, error) {
+	args := m.Called(ctx, action, entityID)
+	return args.Get(0).(*domain.ApprovalToken), args.Error(1)
+}
+func (m *mockOverrideUsecase) Approve(ctx context.Context, action string, entityID uuid.UUID) (*domain.Approval, error) {
+	args := m.Called(ctx, action, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Approval), args.Error(1)
+}
+func (m *mockOverrideUsecase) List(ctx context.Context, filter domain.ApprovalFilter) ([]domain.Approval, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Approval), args.Error(1)
+}
+
+func TestOrderUsecase_UpdateStatus_CancelPaidWithOverride(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	overrides := new(mockOverrideUsecase)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)))
+	id := uuid.New()
+	approval := &domain.Approval{ID: uuid.New(), Action: domain.OverrideCancelPaidOrder, EntityID: id, Method: domain.ApprovalMethodPIN}
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPaid}, nil)
+	// The approval is recorded with the status change, not on its own.
+	orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.OrderStatusChange) bool {
+		return c.ToStatus == domain.OrderStatusCancelled && c.Approval == approval
+	}), mock.Anything).Return(nil).Once()
+	overrides.On("Approve", mock.Anything, domain.OverrideCancelPaidOrder, id).Return(approval, nil).Once()
+	overrides.On("Approve", mock.Anything, domain.OverrideCancelPaidOrder, id).Return(nil, domain.ErrOverrideRequired).Once()
+
+	cashier := staffCtx(domain.RoleCashier)
+	assert.NoError(t, u.UpdateStatus(cashier, id, domain.OrderStatusCancelled, ""))
+	assert.ErrorIs(t, u.UpdateStatus(cashier, id, domain.OrderStatusCancelled, ""), domain.ErrForbidden)
+	orderRepo.AssertExpectations(t)
+	overrides.AssertExpectations(t)
+}
+
+func TestOrderUsecase_Create_ManualDiscount(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	overrides := new(mockOverrideUsecase)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)))
+
+	menuID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(10)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+	approval := &domain.Approval{ID: uuid.New(), Method: domain.ApprovalMethodToken}
+	overrides.On("Approve", mock.Anything, domain.OverrideLargeDiscount, mock.AnythingOfType("uuid.UUID")).Return(approval, nil).Once()
+	cashier := staffCtx(domain.RoleCashier)
+
+	// 20% of the subtotal is within the threshold and needs nobody.
+	order := &domain.Order{ManualDiscount: decimal.NewFromFloat(2), Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	assert.NoError(t, u.Create(cashier, order))
+	assert.True(t, order.Discount.Equal(decimal.NewFromFloat(2)))
+	assert.True(t, order.Total.Equal(decimal.NewFromFloat(8.8)))
+
+	order = &domain.Order{ManualDiscount: decimal.NewFromFloat(5), Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	assert.NoError(t, u.Create(cashier, order))
+	assert.Equal(t, approval, order.Approval)
+	overrides.AssertExpectations(t)
+
+	order = &domain.Order{ManualDiscount: decimal.NewFromFloat(11), Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	assert.ErrorIs(t, u.Create(cashier, order), ErrDiscountExceedsTotal)
+	order = &domain.Order{ManualDiscount: decimal.NewFromFloat(-1), Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	assert.ErrorIs(t, u.Create(cashier, order), ErrInvalidManualDiscount)
+}
+
+func TestOrderUsecase_UpdateStatus_RecordsAudit(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	audit := new(mockAuditUsecase)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithAuditUsecase(audit))
+	id := uuid.New()
+
+	entry := &domain.AuditEntry{ID: uuid.New()}
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
+	// The entry goes to the repository with the change, to be written in the
+	// same transaction.
+	orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(change *domain.OrderStatusChange) bool {
+		return change.ToStatus == domain.OrderStatusPaid && change.Audit == entry
+	}), mock.Anything).Return(nil)
+	audit.On("Entry", mock.Anything, domain.AuditOrderStatusChange, domain.AuditEntityOrder, id,
+		map[string]string{"status": domain.OrderStatusPending}, map[string]string{"status": domain.OrderStatusPaid}).Return(entry, nil)
+
+	assert.NoError(t, u.UpdateStatus(managerCtx(), id, domain.OrderStatusPaid, ""))
+	audit.AssertExpectations(t)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_UpdateStatus_RecordsHistory(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id := uuid.New()
+	manager := &domain.Identity{StaffID: uuid.New(), Username: "max", Role: domain.RoleManager}
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPaid}, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(change *domain.OrderStatusChange) bool {
+		return *change.FromStatus == domain.OrderStatusPaid && change.ToStatus == domain.OrderStatusCancelled &&
+			*change.ActorID == manager.StaffID && change.Reason == "wrong milk"
+	}), mock.Anything).Return(nil)
+
+	// The limit is in characters, not bytes.
+	accented := strings.Repeat("é", 500)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(change *domain.OrderStatusChange) bool {
+		return change.Reason == accented
+	}), mock.Anything).Return(nil)
+
+	ctx := domain.WithIdentity(context.Background(), manager)
+	assert.NoError(t, u.UpdateStatus(ctx, id, domain.OrderStatusCancelled, "  wrong milk "))
+	assert.NoError(t, u.UpdateStatus(ctx, id, domain.OrderStatusCancelled, accented))
+	assert.ErrorIs(t, u.UpdateStatus(ctx, id, domain.OrderStatusCancelled, strings.Repeat("x", 501)), ErrStatusReasonTooLong)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_GetByID_IncludesTimeline(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id := uuid.New()
+	history := []domain.OrderStatusChange{{OrderID: id, ToStatus: domain.OrderStatusPending}}
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(&domain.Order{ID: id, Status: domain.OrderStatusPending}, nil)
+	orderRepo.On("StatusHistory", mock.Anything, id).Return(history, nil)
+
+	order, err := u.GetByID(managerCtx(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, history, order.StatusHistory)
+}
+
+func pendingOrder(id, menuID uuid.UUID, price float64, quantities ...int) *domain.Order {
+	order := &domain.Order{ID: id, Status: domain.OrderStatusPending, OrderType: domain.OrderTypeTakeaway, Version: 2}
+	for _, quantity := range quantities {
+		unitPrice := decimal.NewFromFloat(price)
+		order.Items = append(order.Items, domain.OrderItem{
+			ID: uuid.New(), OrderID: id, MenuItemID: menuID, Quantity: quantity,
+			UnitPrice: unitPrice, LineTotal: unitPrice.Mul(decimal.NewFromInt(int64(quantity))),
+		})
+	}
+	return order
+}
+
+func TestOrderUsecase_UpdateItemNote(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, menuID, programID := uuid.New(), uuid.New(), uuid.New()
+	order := pendingOrder(id, menuID, 4, 1, 1)
+	order.Items[1].StampProgramID = &programID
+	order.Items[1].UnitPrice, order.Items[1].LineTotal = decimal.Zero, decimal.Zero
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(4)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, order, int64(2)).Return(nil)
+
+	// Free reward lines can take a note too.
+	updated, err := u.UpdateItemNote(staffCtx(domain.RoleCashier), id, order.Items[1].ID, " name on cup:\n Sam ")
+	assert.NoError(t, err)
+	assert.Equal(t, "name on cup: Sam", updated.Items[1].Note)
+	assert.True(t, updated.Subtotal.Equal(decimal.NewFromInt(4)))
+
+	_, err = u.UpdateItemNote(staffCtx(domain.RoleCashier), id, order.Items[0].ID, strings.Repeat("a", 201))
+	assert.ErrorIs(t, err, ErrNoteTooLong)
+	_, err = u.UpdateItemNote(staffCtx(domain.RoleCashier), id, uuid.New(), "no lid")
+	assert.ErrorIs(t, err, ErrOrderItemNotFound)
+	orderRepo.AssertNumberOfCalls(t, "UpdateItems", 1)
+}
+
+func TestOrderUsecase_AddItem_Reprices(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, coffeeID, cakeID := uuid.New(), uuid.New(), uuid.New()
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(pendingOrder(id, coffeeID, 4, 1), nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Price: decimal.NewFromFloat(4.5)}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Price: decimal.NewFromFloat(3)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, mock.AnythingOfType("*domain.Order"), int64(2)).Return(nil).Once()
+
+	order, err := u.AddItem(staffCtx(domain.RoleCashier), id, &domain.OrderItem{MenuItemID: cakeID, Quantity: 2})
+	assert.NoError(t, err)
+	assert.Len(t, order.Items, 2)
+	// The coffee keeps the price it was rung up at.
+	assert.True(t, order.Subtotal.Equal(decimal.NewFromFloat(10)))
+	assert.True(t, order.Tax.Equal(decimal.NewFromFloat(1)))
+	assert.True(t, order.Total.Equal(decimal.NewFromFloat(11)))
+	assert.Equal(t, id, order.Items[1].OrderID)
+	orderRepo.AssertExpectations(t)
+
+	missingID := uuid.New()
+	menuRepo.On("GetByID", mock.Anything, missingID).Return(nil, nil)
+	_, err = u.AddItem(staffCtx(domain.RoleCashier), id, &domain.OrderItem{MenuItemID: missingID, Quantity: 1})
+	assert.ErrorIs(t, err, ErrMenuItemNotFound)
+}
+
+func TestOrderUsecase_AddRound(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, coffeeID, cakeID := uuid.New(), uuid.New(), uuid.New()
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(pendingOrder(id, coffeeID, 4, 1), nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Price: decimal.NewFromFloat(4)}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Price: decimal.NewFromFloat(3)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, mock.AnythingOfType("*domain.Order"), int64(2)).Return(nil).Once()
+
+	order, err := u.AddRound(staffCtx(domain.RoleCashier), id, []domain.OrderItem{
+		{MenuItemID: coffeeID, Quantity: 2},
+		{MenuItemID: cakeID, Quantity: 1},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, order.Items, 3)
+	assert.True(t, order.Subtotal.Equal(decimal.NewFromFloat(15)))
+	orderRepo.AssertExpectations(t)
+
+	_, err = u.AddRound(staffCtx(domain.RoleCashier), id, nil)
+	assert.ErrorIs(t, err, ErrEmptyOrderItems)
+}
+
+func TestOrderUsecase_OpenTab(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id := uuid.New()
+	order := pendingOrder(id, uuid.New(), 4, 1)
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	orderRepo.On("OpenTab", mock.Anything, order, int64(2)).Return(nil).Once()
+
+	// Without a table number the tab needs a name.
+	_, err := u.OpenTab(staffCtx(domain.RoleCashier), id, "  ")
+	assert.ErrorIs(t, err, ErrTabNameRequired)
+	_, err = u.OpenTab(staffCtx(domain.RoleCashier), id, strings.Repeat("a", 51))
+	assert.ErrorIs(t, err, ErrInvalidTabName)
+
+	tab, err := u.OpenTab(staffCtx(domain.RoleCashier), id, " Jo ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jo", tab.TabName)
+	assert.NotNil(t, tab.TabOpenedAt)
+
+	_, err = u.OpenTab(staffCtx(domain.RoleCashier), id, "Jo")
+	assert.ErrorIs(t, err, ErrTabAlreadyOpen)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_OpenTab_UnderTableNumber(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id := uuid.New()
+	order := pendingOrder(id, uuid.New(), 4, 1)
+	order.TableNumber = "7"
+	paid := pendingOrder(uuid.New(), uuid.New(), 4, 1)
+	paid.Status = domain.OrderStatusPaid
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	orderRepo.On("GetByID", mock.Anything, paid.ID).Return(paid, nil)
+	orderRepo.On("OpenTab", mock.Anything, order, int64(2)).Return(nil)
+
+	tab, err := u.OpenTab(staffCtx(domain.RoleCashier), id, "")
+	assert.NoError(t, err)
+	assert.Empty(t, tab.TabName)
+	assert.NotNil(t, tab.TabOpenedAt)
+
+	_, err = u.OpenTab(staffCtx(domain.RoleCashier), paid.ID, "Jo")
+	assert.ErrorIs(t, err, ErrOrderNotEditable)
+}
+
+func TestOrderUsecase_UpdateItemQuantityAndRemoveItem(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, menuID := uuid.New(), uuid.New()
+	order := pendingOrder(id, menuID, 4, 1, 2)
+	first, second := order.Items[0].ID, order.Items[1].ID
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(4)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, order, int64(2)).Return(nil)
+
+	updated, err := u.UpdateItemQuantity(managerCtx(), id, first, 3)
+	assert.NoError(t, err)
+	assert.True(t, updated.Subtotal.Equal(decimal.NewFromFloat(20)))
+
+	updated, err = u.RemoveItem(managerCtx(), id, second)
+	assert.NoError(t, err)
+	assert.Len(t, updated.Items, 1)
+	assert.True(t, updated.Total.Equal(decimal.NewFromFloat(13.2)))
+
+	_, err = u.RemoveItem(managerCtx(), id, first)
+	assert.ErrorIs(t, err, ErrEmptyOrderItems)
+	_, err = u.UpdateItemQuantity(managerCtx(), id, first, 0)
+	assert.ErrorIs(t, err, ErrInvalidOrderQuantity)
+	_, err = u.RemoveItem(managerCtx(), id, uuid.New())
+	assert.ErrorIs(t, err, ErrOrderItemNotFound)
+}
+
+func TestOrderUsecase_EditItems_Rejected(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	paidID, pendingID, menuID := uuid.New(), uuid.New(), uuid.New()
+	paid := pendingOrder(paidID, menuID, 4, 1)
+	paid.Status = domain.OrderStatusPaid
+	pending := pendingOrder(pendingID, menuID, 4, 1, 1)
+	programID := uuid.New()
+	pending.Items[1].StampProgramID = &programID
+
+	orderRepo.On("GetByID", mock.Anything, paidID).Return(paid, nil)
+	orderRepo.On("GetByID", mock.Anything, pendingID).Return(pending, nil)
+
+	_, err := u.RemoveItem(managerCtx(), paidID, paid.Items[0].ID)
+	assert.ErrorIs(t, err, ErrOrderNotEditable)
+	_, err = u.RemoveItem(managerCtx(), pendingID, pending.Items[1].ID)
+	assert.ErrorIs(t, err, ErrRewardLineNotEditable)
+	_, err = u.RemoveItem(domain.WithIfMatch(managerCtx(), []int64{1}), pendingID, pending.Items[0].ID)
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	_, err = u.RemoveItem(staffCtx(domain.RoleBarista), pendingID, pending.Items[0].ID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	// A pending order that has taken a part payment is refused too.
+	paymentRepo := new(mockPaymentRepo)
+	u = NewOrderUsecase(orderRepo, new(mockMenuRepository), WithPaymentRepository(paymentRepo))
+	paymentRepo.On("ListByOrder", mock.Anything, pendingID).Return([]domain.Payment{{ID: uuid.New(), Amount: decimal.NewFromInt(2)}}, nil)
+	_, err = u.RemoveItem(managerCtx(), pendingID, pending.Items[0].ID)
+	assert.ErrorIs(t, err, ErrOrderHasPayments)
+	orderRepo.AssertNotCalled(t, "UpdateItems", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_EditItems_Conflict(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, menuID := uuid.New(), uuid.New()
+	order := pendingOrder(id, menuID, 4, 1)
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(4)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, order, int64(2)).Return(domain.ErrConflict)
+
+	_, err := u.UpdateItemQuantity(managerCtx(), id, order.Items[0].ID, 2)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestOrderUsecase_EditItems_HeldPreorderRechecksSlot(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	cfg, err := ParsePreorderConfig("07:00-19:00", "10", "Coffee", "15m")
+	assert.NoError(t, err)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPreorders(cfg))
+	id, menuID := uuid.New(), uuid.New()
+	pickup := time.Now().UTC().Add(24 * time.Hour)
+	held := func() *domain.Order {
+		order := pendingOrder(id, menuID, 4, 2)
+		order.PickupAt = &pickup
+		return order
+	}
+	grown, shrunk := held(), held()
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(grown, nil).Once()
+	orderRepo.On("GetByID", mock.Anything, id).Return(shrunk, nil).Once()
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Category: "Coffee", Price: decimal.NewFromFloat(4)}, nil)
+	orderRepo.On("UpdateItems", mock.Anything, grown, int64(2)).Return(domain.ErrPickupSlotFull).Once()
+	orderRepo.On("UpdateItems", mock.Anything, shrunk, int64(2)).Return(nil).Once()
+
+	// Adding to a held pre-order books the whole order against its slot.
+	_, err = u.UpdateItemQuantity(managerCtx(), id, grown.Items[0].ID, 5)
+	assert.ErrorIs(t, err, domain.ErrPickupSlotFull)
+	if assert.NotNil(t, grown.SlotBooking) {
+		assert.True(t, grown.SlotBooking.Units.Equal(decimal.NewFromInt(5)))
+		assert.True(t, grown.SlotBooking.From.Equal(pickup.Truncate(5*time.Minute)))
+	}
+
+	// Taking units away never needs a place in the slot.
+	_, err = u.UpdateItemQuantity(managerCtx(), id, shrunk.Items[0].ID, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, shrunk.SlotBooking)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_VoidItem_RefundsNewestPaymentsFirst(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	paymentRepo := new(mockPaymentRepo)
+	overrides := new(mockOverrideUsecase)
+	audit := new(mockAuditUsecase)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPaymentRepository(paymentRepo),
+		WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)), WithAuditUsecase(audit))
+
+	id, latteID, cakeID := uuid.New(), uuid.New(), uuid.New()
+	order := pendingOrder(id, latteID, 4, 1)
+	order.Items = append(order.Items, pendingOrder(id, cakeID, 6, 1).Items...)
+	order.Status = domain.OrderStatusPaid
+	order.Subtotal, order.Tax, order.Total = decimal.NewFromInt(10), decimal.NewFromInt(1), decimal.NewFromInt(11)
+	latte := order.Items[0].ID
+
+	cardID := uuid.New()
+	cash := domain.Payment{ID: uuid.New(), Tender: domain.TenderCash, Amount: decimal.NewFromInt(5)}
+	card := domain.Payment{ID: uuid.New(), Tender: domain.TenderGiftCard, Amount: decimal.NewFromInt(6), GiftCardID: &cardID}
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, mock.Anything).Return(&domain.MenuItem{Price: decimal.NewFromInt(4)}, nil)
+	paymentRepo.On("ListByOrder", mock.Anything, id).Return([]domain.Payment{cash, card}, nil)
+	// An earlier void already gave 3.00 back to the gift card.
+	paymentRepo.On("ListRefunds", mock.Anything, id).Return([]domain.Refund{{PaymentID: card.ID, Amount: decimal.NewFromInt(3)}}, nil)
+	approval := &domain.Approval{ID: uuid.New(), Action: domain.OverrideVoidLine, EntityID: id, Method: domain.ApprovalMethodPIN}
+	overrides.On("Approve", mock.Anything, domain.OverrideVoidLine, id).Return(approval, nil)
+	entry := &domain.AuditEntry{ID: uuid.New()}
+	audit.On("Entry", mock.Anything, domain.AuditOrderItemVoid, domain.AuditEntityOrder, id, mock.Anything, mock.Anything).Return(entry, nil).Once()
+	orderRepo.On("VoidItem", mock.Anything, order, mock.AnythingOfType("*domain.OrderItem"), mock.MatchedBy(func(void *domain.LineVoid) bool {
+		refunds := void.Refunds
+		return len(refunds) == 2 && void.Points == nil && len(void.Stamps) == 0 &&
+			refunds[0].PaymentID == card.ID && refunds[0].Amount.Equal(decimal.NewFromInt(3)) && *refunds[0].GiftCardID == cardID &&
+			refunds[1].PaymentID == cash.ID && refunds[1].Amount.Equal(decimal.NewFromFloat(1.4)) && refunds[1].Tender == domain.TenderCash
+	}), int64(2)).Return(nil).Once()
+
+	voided, err := u.VoidItem(staffCtx(domain.RoleCashier), id, latte, " wrong milk ")
+	assert.NoError(t, err)
+	assert.Len(t, voided.Items, 2)
+	assert.NotNil(t, voided.Items[0].VoidedAt)
+	assert.Equal(t, "wrong milk", voided.Items[0].VoidReason)
+	assert.Equal(t, approval, voided.Approval)
+	assert.Equal(t, entry, voided.Audit)
+	assert.True(t, voided.Subtotal.Equal(decimal.NewFromInt(6)))
+	assert.True(t, voided.Total.Equal(decimal.NewFromFloat(6.6)))
+	orderRepo.AssertExpectations(t)
+	overrides.AssertExpectations(t)
+	audit.AssertExpectations(t)
+
+	_, err = u.VoidItem(staffCtx(domain.RoleCashier), id, latte, "again")
+	assert.ErrorIs(t, err, ErrLineAlreadyVoided)
+	_, err = u.VoidItem(staffCtx(domain.RoleCashier), id, order.Items[1].ID, "last one")
+	assert.ErrorIs(t, err, ErrVoidLastLine)
+}
+
+func TestOrderUsecase_VoidItem_Rejected(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository))
+	id, menuID := uuid.New(), uuid.New()
+	order := pendingOrder(id, menuID, 4, 1, 1)
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+
+	_, err := u.VoidItem(managerCtx(), id, order.Items[0].ID, "  ")
+	assert.ErrorIs(t, err, ErrVoidReasonRequired)
+	_, err = u.VoidItem(managerCtx(), id, order.Items[0].ID, strings.Repeat("x", 501))
+	assert.ErrorIs(t, err, ErrVoidReasonTooLong)
+	_, err = u.VoidItem(managerCtx(), id, order.Items[0].ID, strings.Repeat("é", 500))
+	assert.ErrorIs(t, err, ErrOrderNotVoidable)
+	_, err = u.VoidItem(managerCtx(), id, order.Items[0].ID, "spilled")
+	assert.ErrorIs(t, err, ErrOrderNotVoidable)
+	order.Status = domain.OrderStatusPaid
+	_, err = u.VoidItem(staffCtx(domain.RoleCashier), id, order.Items[0].ID, "spilled")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = u.VoidItem(managerCtx(), id, uuid.New(), "spilled")
+	assert.ErrorIs(t, err, ErrOrderItemNotFound)
+	orderRepo.AssertNotCalled(t, "VoidItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_VoidItem_TakesBackPointsAndStamps(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	loyaltyRepo := new(mockLoyaltyRepo)
+	stampRepo := new(mockStampRepo)
+	overrides := new(mockOverrideUsecase)
+	u := NewOrderUsecase(orderRepo, menuRepo,
+		WithLoyaltyUsecase(NewLoyaltyUsecase(loyaltyRepo, new(mockCustomerRepo), menuRepo, testLoyaltyConfig())),
+		WithStampUsecase(NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)),
+		WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)))
+
+	id, latteID, cakeID, customerID, programID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	order := pendingOrder(id, latteID, 4, 2)
+	order.Items = append(order.Items, pendingOrder(id, cakeID, 6, 1).Items...)
+	order.CustomerID = &customerID
+	latte := order.Items[0].ID
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, latteID).Return(&domain.MenuItem{ID: latteID, Category: "Hot Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Category: "Bakery"}, nil)
+
+	// A void that would be refused never asks for a manager's approval.
+	_, err := u.VoidItem(staffCtx(domain.RoleCashier), id, latte, "spilled")
+	assert.ErrorIs(t, err, ErrOrderNotVoidable)
+	order.Status = domain.OrderStatusPaid
+	_, err = u.VoidItem(staffCtx(domain.RoleCashier), id, uuid.New(), "spilled")
+	assert.ErrorIs(t, err, ErrOrderItemNotFound)
+	overrides.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything, mock.Anything)
+
+	overrides.On("Approve", mock.Anything, domain.OverrideVoidLine, id).Return(&domain.Approval{ID: uuid.New()}, nil)
+	loyaltyRepo.On("ListOrderEntries", mock.Anything, id).Return([]domain.LoyaltyEntry{
+		{CustomerID: customerID, OrderID: &id, Type: domain.LoyaltyEntryEarn, Points: 14},
+	}, nil)
+	stampRepo.On("ListOrderEntries", mock.Anything, id).Return([]domain.StampEntry{
+		{ProgramID: programID, CustomerID: customerID, OrderID: &id, Type: domain.StampEntryStamp, Stamps: 2},
+	}, nil)
+	stampRepo.On("ListPrograms", mock.Anything, false).Return([]domain.StampProgram{
+		{ID: programID, Categories: []string{"Hot Coffee"}, StampsRequired: 9, Active: true},
+	}, nil)
+	orderRepo.On("VoidItem", mock.Anything, order, mock.AnythingOfType("*domain.OrderItem"), mock.MatchedBy(func(void *domain.LineVoid) bool {
+		return void.Points != nil && void.Points.Points == -8 && *void.Points.OrderItemID == latte &&
+			len(void.Stamps) == 1 && void.Stamps[0].Stamps == -2 && void.Stamps[0].ProgramID == programID
+	}), int64(2)).Return(nil).Once()
+
+	_, err = u.VoidItem(staffCtx(domain.RoleCashier), id, latte, "spilled")
+	assert.NoError(t, err)
+	orderRepo.AssertExpectations(t)
+	overrides.AssertExpectations(t)
+}
+
+func sumTotals(orders []domain.Order) decimal.Decimal {
+	total := decimal.Zero
+	for _, order := range orders {
+		total = total.Add(order.Total)
+	}
+	return total
+}
+
+func TestOrderUsecase_Split_ByItems(t *testing.T) {
+	rule, err := ParseOrderTypeRule("0.08", "0.10")
+	assert.NoError(t, err)
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithOrderTypeRules(map[string]OrderTypeRule{domain.OrderTypeDineIn: rule}))
+	id, coffeeID, pizzaID := uuid.New(), uuid.New(), uuid.New()
+
+	order := pendingOrder(id, coffeeID, 3.35, 3)
+	pizza := pendingOrder(id, pizzaID, 12, 1).Items[0]
+	order.Items = append(order.Items, pizza)
+	order.OrderType = domain.OrderTypeDineIn
+	order.TableNumber = "4"
+	order.ManualDiscount = decimal.NewFromInt(1)
+	assert.NoError(t, u.(*orderUsecase).priceTotals(order, nil))
+	coffee := order.Items[0]
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, pizzaID).Return(&domain.MenuItem{ID: pizzaID, Category: "Food"}, nil)
+	orderRepo.On("Split", mock.Anything, mock.MatchedBy(func(change *domain.OrderStatusChange) bool {
+		return change.OrderID == id && change.ToStatus == domain.OrderStatusSplit
+	}), int64(2), mock.Anything).Return(nil)
+
+	third := decimal.RequireFromString("0.333")
+	splits := []domain.OrderSplit{
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: pizza.ID, Quantity: third}}},
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: pizza.ID, Quantity: third}}},
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: pizza.ID, Quantity: decimal.RequireFromString("0.334")}}},
+	}
+	children, err := u.Split(managerCtx(), id, splits)
+	assert.NoError(t, err)
+	assert.Len(t, children, 3)
+	assert.Equal(t, order.Total.StringFixed(2), sumTotals(children).StringFixed(2))
+
+	pizzaTotal, discount := decimal.Zero, decimal.Zero
+	for _, child := range children {
+		assert.Equal(t, &id, child.ParentOrderID)
+		assert.Equal(t, "4", child.TableNumber)
+		assert.Equal(t, domain.OrderStatusPending, child.Status)
+		assert.Equal(t, 1, child.Items[0].Quantity)
+		assert.Nil(t, child.Items[0].Share)
+		assert.Equal(t, child.ID, child.Items[1].OrderID)
+		pizzaTotal = pizzaTotal.Add(child.Items[1].LineTotal)
+		discount = discount.Add(child.Discount)
+	}
+	assert.Equal(t, "0.333", children[0].Items[1].Share.String())
+	assert.Equal(t, "12.00", pizzaTotal.StringFixed(2))
+	assert.Equal(t, "1.00", discount.StringFixed(2))
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_SplitEqually(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo)
+	id, menuID := uuid.New(), uuid.New()
+	order := pendingOrder(id, menuID, 10, 1, 7, 3)
+	half := decimal.NewFromFloat(0.5)
+	order.Items[2].Share = &half
+	order.Items[2].LineTotal = decimal.NewFromInt(15)
+	assert.NoError(t, u.(*orderUsecase).priceTotals(order, nil))
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Category: "Food"}, nil)
+	orderRepo.On("Split", mock.Anything, mock.Anything, int64(2), mock.Anything).Return(nil)
+
+	children, err := u.SplitEqually(staffCtx(domain.RoleCashier), id, 3)
+	assert.NoError(t, err)
+	assert.Len(t, children, 3)
+	assert.True(t, sumTotals(children).Equal(order.Total))
+	assert.Equal(t, "0.333333", children[0].Items[0].Share.String())
+	assert.Equal(t, "0.333334", children[2].Items[0].Share.String())
+	assert.Equal(t, "0.166668", children[2].Items[2].Share.String())
+	// Every line's shares add back up to exactly its quantity and amount.
+	for i, item := range order.Items {
+		units, amount := decimal.Zero, decimal.Zero
+		for _, child := range children {
+			units = units.Add(child.Items[i].Units())
+			amount = amount.Add(child.Items[i].LineTotal)
+		}
+		assert.True(t, units.Equal(item.Units()), "line %d units %s", i, units)
+		assert.True(t, amount.Equal(item.LineTotal), "line %d amount %s", i, amount)
+	}
+
+	// Lines that divide evenly keep whole quantities.
+	evenID := uuid.New()
+	even := pendingOrder(evenID, menuID, 2, 4)
+	assert.NoError(t, u.(*orderUsecase).priceTotals(even, nil))
+	orderRepo.On("GetByID", mock.Anything, evenID).Return(even, nil)
+	children, err = u.SplitEqually(staffCtx(domain.RoleCashier), evenID, 2)
+	assert.NoError(t, err)
+	for _, child := range children {
+		assert.Nil(t, child.Items[0].Share)
+		assert.Equal(t, 2, child.Items[0].Quantity)
+	}
+}
+
+func TestReconcileSplitTotals_Unbalanced(t *testing.T) {
+	children := []domain.Order{{Total: decimal.NewFromFloat(5.01)}, {Total: decimal.NewFromInt(5)}}
+	assert.ErrorIs(t, reconcileSplitTotals(decimal.NewFromInt(10), children), ErrSplitUnbalanced)
+
+	children = []domain.Order{{Total: decimal.NewFromFloat(5.01), Tax: decimal.NewFromFloat(0.5)}, {Total: decimal.NewFromInt(5)}}
+	assert.NoError(t, reconcileSplitTotals(decimal.NewFromInt(10), children))
+	assert.Equal(t, "0.49", children[0].Tax.StringFixed(2))
+	assert.True(t, sumTotals(children).Equal(decimal.NewFromInt(10)))
+}
+
+func TestOrderUsecase_Split_Rejected(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	paymentRepo := new(mockPaymentRepo)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPaymentRepository(paymentRepo))
+	id, paidID, coffeeID, giftID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	order := pendingOrder(id, coffeeID, 4, 3)
+	order.Items = append(order.Items, pendingOrder(id, giftID, 25, 1).Items[0])
+	coffee, gift := order.Items[0], order.Items[1]
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	orderRepo.On("GetByID", mock.Anything, paidID).Return(pendingOrder(paidID, coffeeID, 4, 1), nil)
+	paymentRepo.On("ListByOrder", mock.Anything, id).Return([]domain.Payment{}, nil)
+	paymentRepo.On("ListByOrder", mock.Anything, paidID).Return([]domain.Payment{{ID: uuid.New()}}, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, giftID).Return(&domain.MenuItem{ID: giftID, Category: domain.GiftCardCategory}, nil)
+
+	whole := func(item domain.OrderItem) domain.SplitItem { return domain.SplitItem{ItemID: item.ID} }
+	part := func(item domain.OrderItem, quantity string) domain.SplitItem {
+		return domain.SplitItem{ItemID: item.ID, Quantity: decimal.RequireFromString(quantity)}
+	}
+
+	_, err := u.Split(managerCtx(), id, []domain.OrderSplit{{Items: []domain.SplitItem{whole(coffee), whole(gift)}}})
+	assert.ErrorIs(t, err, ErrInvalidSplitCount)
+	_, err = u.SplitEqually(managerCtx(), id, 21)
+	assert.ErrorIs(t, err, ErrInvalidSplitCount)
+	_, err = u.Split(managerCtx(), id, []domain.OrderSplit{{Items: []domain.SplitItem{part(coffee, "2")}}, {Items: []domain.SplitItem{whole(gift)}}})
+	assert.ErrorIs(t, err, ErrSplitIncomplete)
+	_, err = u.Split(managerCtx(), id, []domain.OrderSplit{{Items: []domain.SplitItem{part(coffee, "1.5"), part(gift, "0.5")}}, {Items: []domain.SplitItem{part(coffee, "1.5"), part(gift, "0.5")}}})
+	assert.ErrorIs(t, err, ErrSplitGiftCardLine)
+	_, err = u.Split(managerCtx(), id, []domain.OrderSplit{{Items: []domain.SplitItem{part(coffee, "1.0005"), whole(gift)}}, {Items: []domain.SplitItem{part(coffee, "1.9995")}}})
+	assert.ErrorIs(t, err, ErrInvalidSplitQuantity)
+	_, err = u.SplitEqually(managerCtx(), id, 2)
+	assert.ErrorIs(t, err, ErrSplitGiftCardLine)
+	_, err = u.SplitEqually(managerCtx(), paidID, 2)
+	assert.ErrorIs(t, err, ErrSplitHasPayments)
+	orderRepo.AssertNotCalled(t, "Split", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_Split_RewardLineCancelledChild(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	stampRepo := new(mockStampRepo)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithStampUsecase(NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)))
+	id, coffeeID, customerID, programID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	order := pendingOrder(id, coffeeID, 4, 2, 1)
+	order.CustomerID = &customerID
+	order.Items[1].StampProgramID = &programID
+	order.Items[1].UnitPrice, order.Items[1].LineTotal = decimal.Zero, decimal.Zero
+	assert.NoError(t, u.(*orderUsecase).priceTotals(order, nil))
+	coffee, reward := order.Items[0], order.Items[1]
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	orderRepo.On("Split", mock.Anything, mock.Anything, int64(2), mock.Anything).Return(nil).Once()
+
+	// A free line cannot be shared out, nor its card's lines spread over orders.
+	_, err := u.SplitEqually(managerCtx(), id, 2)
+	assert.ErrorIs(t, err, ErrSplitRewardLine)
+	_, err = u.Split(managerCtx(), id, []domain.OrderSplit{
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: reward.ID, Quantity: decimal.RequireFromString("0.5")}}},
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: reward.ID, Quantity: decimal.RequireFromString("0.5")}}},
+	})
+	assert.ErrorIs(t, err, ErrSplitRewardLine)
+
+	children, err := u.Split(managerCtx(), id, []domain.OrderSplit{
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}}},
+		{Items: []domain.SplitItem{{ItemID: coffee.ID, Quantity: decimal.NewFromInt(1)}, {ItemID: reward.ID}}},
+	})
+	assert.NoError(t, err)
+	child := children[1]
+	assert.Equal(t, &programID, child.Items[1].StampProgramID)
+
+	// The split moved the card's redeem entry to the child with the free line,
+	// so cancelling that child gives the card back.
+	orderRepo.On("GetByID", mock.Anything, child.ID).Return(&child, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*domain.OrderStatusChange"), int64(1)).Return(nil)
+	stampRepo.On("ListOrderEntries", mock.Anything, child.ID).Return([]domain.StampEntry{
+		{ProgramID: programID, CustomerID: customerID, OrderID: &child.ID, Type: domain.StampEntryRedeem, Stamps: -9},
+	}, nil)
+	stampRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(entry *domain.StampEntry) bool {
+		return *entry.OrderID == child.ID && entry.Type == domain.StampEntryReversal && entry.Stamps == 9
+	})).Return(nil).Once()
+
+	assert.NoError(t, u.UpdateStatus(managerCtx(), child.ID, domain.OrderStatusCancelled, ""))
+	orderRepo.AssertExpectations(t)
+	stampRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_Merge(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	audit := new(mockAuditUsecase)
+	overrides := new(mockOverrideUsecase)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithAuditUsecase(audit), WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)))
+	targetID, sourceID, coffeeID, cakeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	customerID := uuid.New()
+	target := pendingOrder(targetID, coffeeID, 4, 2)
+	target.Reference = "A-20261018-001"
+	source := pendingOrder(sourceID, cakeID, 3, 1)
+	source.CustomerID = &customerID
+	source.ManualDiscount = decimal.NewFromFloat(1)
+	target.Note = "window seat"
+	source.Note = "one bill"
+	assert.NoError(t, u.(*orderUsecase).priceTotals(target, nil))
+
+	orderRepo.On("GetByID", mock.Anything, targetID).Return(target, nil)
+	orderRepo.On("GetByID", mock.Anything, sourceID).Return(source, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Category: "Food"}, nil)
+	orderRepo.On("Merge", mock.Anything, target, int64(2), mock.Anything, mock.Anything).Return(nil)
+	entry := &domain.AuditEntry{ID: uuid.New()}
+	audit.On("Entry", mock.Anything, domain.AuditOrderMerge, domain.AuditEntityOrder, targetID, mock.Anything, mock.Anything).Return(entry, nil)
+
+	merged, err := u.Merge(staffCtx(domain.RoleCashier), targetID, []uuid.UUID{sourceID})
+	assert.NoError(t, err)
+	assert.Equal(t, entry, merged.Audit)
+	assert.Len(t, merged.Items, 2)
+	assert.Equal(t, targetID, merged.Items[1].OrderID)
+	assert.Equal(t, &customerID, merged.CustomerID)
+	assert.Equal(t, "window seat; one bill", merged.Note)
+	assert.Equal(t, "11", merged.Subtotal.String())
+	assert.Equal(t, "11.00", merged.Total.StringFixed(2))
+
+	sources := orderRepo.Calls[len(orderRepo.Calls)-1].Arguments.Get(3).([]domain.Order)
+	changes := orderRepo.Calls[len(orderRepo.Calls)-1].Arguments.Get(4).([]domain.OrderStatusChange)
+	assert.Equal(t, &targetID, sources[0].MergedIntoID)
+	assert.Equal(t, domain.OrderStatusMerged, changes[0].ToStatus)
+	assert.Equal(t, "merged into A-20261018-001", changes[0].Reason)
+	audit.AssertExpectations(t)
+	// 1.00 off 11.00 is within what a cashier may give.
+	overrides.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_Merge_HeldTargetRechecksSlot(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	cfg, err := ParsePreorderConfig("07:00-19:00", "10", "Coffee", "15m")
+	assert.NoError(t, err)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPreorders(cfg))
+	targetID, sourceID, coffeeID := uuid.New(), uuid.New(), uuid.New()
+	pickup := time.Now().UTC().Add(24 * time.Hour)
+	target := pendingOrder(targetID, coffeeID, 4, 2)
+	target.PickupAt = &pickup
+	source := pendingOrder(sourceID, coffeeID, 4, 3)
+
+	orderRepo.On("GetByID", mock.Anything, targetID).Return(target, nil)
+	orderRepo.On("GetByID", mock.Anything, sourceID).Return(source, nil)
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee"}, nil)
+	orderRepo.On("Merge", mock.Anything, target, int64(2), mock.Anything, mock.Anything).Return(domain.ErrPickupSlotFull)
+
+	// The merged lines join the held target in its pickup slot.
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{sourceID})
+	assert.ErrorIs(t, err, domain.ErrPickupSlotFull)
+	if assert.NotNil(t, target.SlotBooking) {
+		assert.True(t, target.SlotBooking.Units.Equal(decimal.NewFromInt(5)))
+		assert.True(t, target.SlotBooking.From.Equal(pickup.Truncate(5*time.Minute)))
+	}
+}
+
+func TestOrderUsecase_Merge_ApprovesCombinedDiscount(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	overrides := new(mockOverrideUsecase)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithOverrideUsecase(overrides, decimal.NewFromFloat(0.2)))
+	targetID, sourceID, menuID := uuid.New(), uuid.New(), uuid.New()
+	// Each discount is within a cashier's limit on its own order, but 3.00
+	// off the merged 10.00 is not.
+	order := func(id uuid.UUID, discount int64) *domain.Order {
+		o := pendingOrder(id, menuID, 5, 1)
+		o.ManualDiscount = decimal.NewFromInt(discount)
+		return o
+	}
+	target := order(targetID, 1)
+	orderRepo.On("GetByID", mock.Anything, targetID).Return(order(targetID, 1), nil).Once()
+	orderRepo.On("GetByID", mock.Anything, targetID).Return(target, nil).Once()
+	orderRepo.On("GetByID", mock.Anything, sourceID).Return(order(sourceID, 2), nil).Once()
+	orderRepo.On("GetByID", mock.Anything, sourceID).Return(order(sourceID, 2), nil).Once()
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Category: "Coffee"}, nil)
+	overrides.On("Approve", mock.Anything, domain.OverrideLargeDiscount, targetID).Return(nil, domain.ErrOverrideRequired).Once()
+
+	_, err := u.Merge(staffCtx(domain.RoleCashier), targetID, []uuid.UUID{sourceID})
+	assert.ErrorIs(t, err, domain.ErrOverrideRequired)
+	orderRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	approval := &domain.Approval{ID: uuid.New(), Action: domain.OverrideLargeDiscount, EntityID: targetID}
+	overrides.On("Approve", mock.Anything, domain.OverrideLargeDiscount, targetID).Return(approval, nil).Once()
+	orderRepo.On("Merge", mock.Anything, target, int64(2), mock.Anything, mock.Anything).Return(nil).Once()
+	merged, err := u.Merge(staffCtx(domain.RoleCashier), targetID, []uuid.UUID{sourceID})
+	assert.NoError(t, err)
+	assert.Equal(t, approval, merged.Approval)
+	assert.Equal(t, "3", merged.ManualDiscount.String())
+	overrides.AssertExpectations(t)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_Merge_Rejected(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	paymentRepo := new(mockPaymentRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithPaymentRepository(paymentRepo))
+	targetID, paidID, pointsID, otherID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	customerID, otherCustomerID := uuid.New(), uuid.New()
+	target := pendingOrder(targetID, uuid.New(), 4, 1)
+	target.CustomerID = &customerID
+	points := pendingOrder(pointsID, uuid.New(), 4, 1)
+	points.RedeemedPoints = 50
+	other := pendingOrder(otherID, uuid.New(), 4, 1)
+	other.CustomerID = &otherCustomerID
+	programID, rewardID := uuid.New(), uuid.New()
+	target.Items[0].StampProgramID = &programID
+	reward := pendingOrder(rewardID, uuid.New(), 4, 1)
+	reward.CustomerID = &customerID
+	reward.Items[0].StampProgramID = &programID
+
+	orderRepo.On("GetByID", mock.Anything, targetID).Return(target, nil)
+	orderRepo.On("GetByID", mock.Anything, paidID).Return(pendingOrder(paidID, uuid.New(), 4, 1), nil)
+	orderRepo.On("GetByID", mock.Anything, pointsID).Return(points, nil)
+	orderRepo.On("GetByID", mock.Anything, otherID).Return(other, nil)
+	orderRepo.On("GetByID", mock.Anything, rewardID).Return(reward, nil)
+	paymentRepo.On("ListByOrder", mock.Anything, paidID).Return([]domain.Payment{{ID: uuid.New()}}, nil)
+
+	_, err := u.Merge(managerCtx(), targetID, nil)
+	assert.ErrorIs(t, err, ErrInvalidMergeCount)
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{targetID})
+	assert.ErrorIs(t, err, ErrInvalidMergeSource)
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{paidID})
+	assert.ErrorIs(t, err, ErrMergeHasPayments)
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{pointsID})
+	assert.ErrorIs(t, err, ErrMergeRedeemedPoints)
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{otherID})
+	assert.ErrorIs(t, err, ErrMergeCustomerMismatch)
+	_, err = u.Merge(managerCtx(), targetID, []uuid.UUID{rewardID})
+	assert.ErrorIs(t, err, ErrMergeStampRewards)
+	orderRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_Transfer(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	audit := new(mockAuditUsecase)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithAuditUsecase(audit))
+	id := uuid.New()
+	order := pendingOrder(id, uuid.New(), 4, 1)
+	order.OrderType = domain.OrderTypeDineIn
+	order.TableNumber = "4"
+
+	orderRepo.On("GetByID", mock.Anything, id).Return(order, nil)
+	orderRepo.On("UpdateTable", mock.Anything, order, int64(2)).Return(nil)
+	entry := &domain.AuditEntry{ID: uuid.New()}
+	audit.On("Entry", mock.Anything, domain.AuditOrderTransfer, domain.AuditEntityOrder, id,
+		map[string]string{"table_number": "4"}, map[string]string{"table_number": "7"}).Return(entry, nil)
+
+	_, err := u.Transfer(staffCtx(domain.RoleCashier), id, " ")
+	assert.ErrorIs(t, err, ErrTableNumberRequired)
+	_, err = u.Transfer(staffCtx(domain.RoleCashier), id, "7; DROP")
+	assert.ErrorIs(t, err, ErrInvalidTableNumber)
+
+	moved, err := u.Transfer(staffCtx(domain.RoleCashier), id, " 7 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "7", moved.TableNumber)
+	assert.Equal(t, entry, moved.Audit)
+	audit.AssertExpectations(t)
+}
+
+func TestParsePreorderConfig_Invalid(t *testing.T) {
+	for _, args := range [][4]string{
+		{"07:00", "10", "", "15m"},
+		{"19:00-07:00", "10", "", "15m"},
+		{"7am-7pm", "10", "", "15m"},
+		{"07:00-19:00", "-1", "", "15m"},
+		{"07:00-19:00", "10", "", "soon"},
+	} {
+		_, err := ParsePreorderConfig(args[0], args[1], args[2], args[3])
+		assert.Error(t, err, args)
+	}
+	cfg, err := ParsePreorderConfig("06:30-24:00", "12", "Coffee, Tea", "20m")
+	assert.NoError(t, err)
+	assert.Equal(t, 6*time.Hour+30*time.Minute, cfg.Opens)
+	assert.Equal(t, 24*time.Hour, cfg.Closes)
+	assert.Equal(t, []string{"Coffee", "Tea"}, cfg.Categories)
+}
+
+func TestOrderUsecase_Create_Preorder(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	cfg, err := ParsePreorderConfig("07:00-19:00", "10", "Coffee", "15m")
+	assert.NoError(t, err)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPreorders(cfg))
+
+	coffeeID, cakeID := uuid.New(), uuid.New()
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	pickup := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 8, 32, 0, 0, time.UTC)
+	slot := pickup.Add(-2 * time.Minute)
+	items := func() []domain.OrderItem {
+		return []domain.OrderItem{{MenuItemID: coffeeID, Quantity: 3}, {MenuItemID: cakeID, Quantity: 4}}
+	}
+	menuRepo.On("GetByID", mock.Anything, coffeeID).Return(&domain.MenuItem{ID: coffeeID, Category: "Coffee", Price: decimal.NewFromFloat(4)}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Category: "Food", Price: decimal.NewFromFloat(3)}, nil)
+	booked := mock.MatchedBy(func(o *domain.Order) bool {
+		b := o.SlotBooking
+		return b != nil && b.From.Equal(slot) && b.To.Equal(slot.Add(5*time.Minute)) &&
+			b.Units.Equal(decimal.NewFromInt(3)) && b.Capacity == 10 && assert.ObjectsAreEqual([]string{"Coffee"}, b.Categories)
+	})
+	orderRepo.On("Create", mock.Anything, booked).Return(nil).Once()
+	orderRepo.On("Create", mock.Anything, booked).Return(domain.ErrPickupSlotFull).Once()
+
+	order := &domain.Order{PickupAt: &pickup, Items: items()}
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.Nil(t, order.ReleasedAt)
+
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{PickupAt: &pickup, Items: items()}), domain.ErrPickupSlotFull)
+
+	past := time.Now().Add(-time.Minute)
+	late := pickup.Add(11 * time.Hour)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{PickupAt: &past, Items: items()}), ErrInvalidPickupTime)
+	assert.ErrorIs(t, u.Create(managerCtx(), &domain.Order{PickupAt: &late, Items: items()}), ErrOutsideOpeningHours)
+	disabled := NewOrderUsecase(orderRepo, menuRepo)
+	assert.ErrorIs(t, disabled.Create(managerCtx(), &domain.Order{PickupAt: &pickup, Items: items()}), ErrPreordersDisabled)
+	orderRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestOrderUsecase_Create_PreorderWithinLeadTime(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewOrderUsecase(orderRepo, menuRepo, WithPreorders(PreorderConfig{Closes: 24 * time.Hour, LeadTime: 48 * time.Hour}))
+
+	menuID := uuid.New()
+	pickup := time.Now().Add(time.Hour)
+	menuRepo.On("GetByID", mock.Anything, menuID).Return(&domain.MenuItem{ID: menuID, Price: decimal.NewFromFloat(4)}, nil)
+	orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil)
+
+	order := &domain.Order{PickupAt: &pickup, Items: []domain.OrderItem{{MenuItemID: menuID, Quantity: 1}}}
+	assert.NoError(t, u.Create(managerCtx(), order))
+	assert.NotNil(t, order.ReleasedAt)
+	assert.Nil(t, order.SlotBooking)
+}
+
+func TestOrderUsecase_ReleaseDue(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	u := NewOrderUsecase(orderRepo, new(mockMenuRepository), WithPreorders(PreorderConfig{Closes: 24 * time.Hour, LeadTime: 15 * time.Minute}))
+
+	orderRepo.On("ReleaseScheduled", mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+
+	released, err := u.ReleaseDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), released)
+	args := orderRepo.Calls[0].Arguments
+	assert.Equal(t, 15*time.Minute, args.Get(1).(time.Time).Sub(args.Get(2).(time.Time)))
+
+	released, err = NewOrderUsecase(orderRepo, new(mockMenuRepository)).ReleaseDue(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, released)
+	orderRepo.AssertNumberOfCalls(t, "ReleaseScheduled", 1)
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+)
+
+const approvalTokenPrefix = "apr_"
+
+var ErrUnknownOverrideAction = errors.New("unknown override action")
+
+type OverrideConfig struct {
+	TokenTTL       time.Duration
+	PINMaxAttempts int
+	PINLockout     time.Duration
+}
+
+type overrideUsecase struct {
+	overrideRepo domain.OverrideRepository
+	staffRepo    domain.StaffRepository
+	pins         *pinVerifier
+	config       OverrideConfig
+	now          func() time.Time
+}
+
+func NewOverrideUsecase(overrideRepo domain.OverrideRepository, staffRepo domain.StaffRepository, config OverrideConfig) domain.OverrideUsecase {
+	return &overrideUsecase{
+		overrideRepo: overrideRepo,
+		staffRepo:    staffRepo,
+		pins:         newPINVerifier(staffRepo, config.PINMaxAttempts, config.PINLockout),
+		config:       config,
+		now:          time.Now,
+	}
+}
+
+func (u *overrideUsecase) IssueToken(ctx context.Context, action string, entityID *uuid.UUID) (*domain.ApprovalToken, error) {
+	permission, ok := domain.OverridePermissions[action]
+	if !ok {
+		return nil, ErrUnknownOverrideAction
+	}
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok || !identity.Can(permission) {
+		return nil, domain.ErrForbidden
+	}
+
+	token, hash, err := newOpaqueToken(approvalTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	approvalToken := &domain.ApprovalToken{
+		ID:        uuid.New(),
+		TokenHash: hash,
+		ManagerID: identity.StaffID,
+		Action:    action,
+		EntityID:  entityID,
+		ExpiresAt: now.Add(u.config.TokenTTL),
+		CreatedAt: now,
+	}
+	if err := u.overrideRepo.CreateToken(ctx, approvalToken); err != nil {
+		return nil, err
+	}
+	approvalToken.Token = token
+	return approvalToken, nil
+}
+
+func (u *overrideUsecase) Approve(ctx context.Context, action string, entityID uuid.UUID) (*domain.Approval, error) {
+	permission, ok := domain.OverridePermissions[action]
+	if !ok {
+		return nil, ErrUnknownOverrideAction
+	}
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+
+	approval := &domain.Approval{
+		ID:        uuid.New(),
+		Action:    action,
+		EntityID:  entityID,
+		ActorID:   identity.StaffRef(),
+		CreatedAt: u.now(),
+	}
+	if identity.Can(permission) {
+		approval.ApproverID = identity.StaffID
+		approval.Method = domain.ApprovalMethodRole
+		return approval, nil
+	}
+
+	credentials, ok := domain.OverrideFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrOverrideRequired
+	}
+
+	var approver *domain.Staff
+	switch {
+	case credentials.ApprovalToken != "":
+		token, err := u.overrideRepo.FindToken(ctx, hashToken(credentials.ApprovalToken), action, entityID, approval.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, domain.ErrInvalidOverride
+		}
+		approval.TokenID = &token.ID
+		// The manager may have been demoted or deactivated since issuing it.
+		approver, err = u.staffRepo.GetByID(ctx, token.ManagerID)
+		if err != nil {
+			return nil, err
+		}
+		approval.Method = domain.ApprovalMethodToken
+	case credentials.ManagerUsername != "" && credentials.PIN != "":
+		var err error
+		approver, err = u.pins.verify(ctx, credentials.ManagerUsername, credentials.PIN)
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			return nil, domain.ErrInvalidOverride
+		}
+		if err != nil {
+			return nil, err
+		}
+		approval.Method = domain.ApprovalMethodPIN
+	default:
+		return nil, domain.ErrOverrideRequired
+	}
+
+	if approver == nil || !approver.Active || !(&domain.Identity{Role: approver.Role}).Can(permission) {
+		return nil, domain.ErrInvalidOverride
+	}
+	approval.ApproverID = approver.ID
+	return approval, nil
+}
+
+func (u *overrideUsecase) List(ctx context.Context, filter domain.ApprovalFilter) ([]domain.Approval, error) {
+	if err := domain.Authorize(ctx, domain.PermReportsRead); err != nil {
+		return nil, err
+	}
+	return u.overrideRepo.List(ctx, filter)
+}
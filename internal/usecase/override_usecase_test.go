@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOverrideRepo struct{ mock.Mock }
+
+func (m *mockOverrideRepo) CreateToken(ctx context.Context, token *domain.ApprovalToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+func (m *mockOverrideRepo) FindToken(ctx context.Context, tokenHash, action string, entityID uuid.UUID, at time.Time) (*domain.ApprovalToken, error) {
+	args := m.Called(ctx, tokenHash, action, entityID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApprovalToken), args.Error(1)
+}
+func (m *mockOverrideRepo) Record(ctx context.Context, approval *domain.Approval) error {
+	args := m.Called(ctx, approval)
+	return args.Error(0)
+}
+func (m *mockOverrideRepo) List(ctx context.Context, filter domain.ApprovalFilter) ([]domain.Approval, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Approval), args.Error(1)
+}
+
+func testOverrideConfig() OverrideConfig {
+	return OverrideConfig{TokenTTL: 2 * time.Minute, PINMaxAttempts: 5, PINLockout: 15 * time.Minute}
+}
+
+func TestOverrideUsecase_IssueToken(t *testing.T) {
+	overrideRepo := new(mockOverrideRepo)
+	u := NewOverrideUsecase(overrideRepo, new(mockStaffRepo), testOverrideConfig())
+	orderID := uuid.New()
+
+	overrideRepo.On("CreateToken", mock.Anything, mock.AnythingOfType("*domain.ApprovalToken")).Return(nil)
+
+	token, err := u.IssueToken(managerCtx(), domain.OverrideCancelPaidOrder, &orderID)
+	assert.NoError(t, err)
+	assert.Contains(t, token.Token, approvalTokenPrefix)
+	assert.Equal(t, hashToken(token.Token), token.TokenHash)
+	assert.Equal(t, orderID, *token.EntityID)
+
+	_, err = u.IssueToken(staffCtx(domain.RoleCashier), domain.OverrideCancelPaidOrder, &orderID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = u.IssueToken(managerCtx(), "order.anything", nil)
+	assert.ErrorIs(t, err, ErrUnknownOverrideAction)
+}
+
+func TestOverrideUsecase_Approve_ByRole(t *testing.T) {
+	u := NewOverrideUsecase(new(mockOverrideRepo), new(mockStaffRepo), testOverrideConfig())
+
+	approval, err := u.Approve(managerCtx(), domain.OverrideLargeDiscount, uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalMethodRole, approval.Method)
+	assert.Equal(t, *approval.ActorID, approval.ApproverID)
+
+	_, err = u.Approve(staffCtx(domain.RoleCashier), domain.OverrideLargeDiscount, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrOverrideRequired)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestOverrideUsecase_Approve_ByPIN(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewOverrideUsecase(new(mockOverrideRepo), staffRepo, testOverrideConfig())
+	manager := testPINStaff(t, "4821")
+	manager.Role = domain.RoleManager
+	barista := testPINStaff(t, "1111")
+	barista.Username = "bo"
+
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(manager, nil)
+	staffRepo.On("GetByUsername", mock.Anything, "bo").Return(barista, nil)
+	staffRepo.On("ResetPINFailures", mock.Anything, mock.Anything).Return(nil)
+	staffRepo.On("ClaimPINAttempt", mock.Anything, mock.Anything, 5, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	cashier := staffCtx(domain.RoleCashier)
+	orderID := uuid.New()
+	approval, err := u.Approve(domain.WithOverride(cashier, &domain.OverrideCredentials{ManagerUsername: "sam", PIN: "4821"}), domain.OverrideCancelPaidOrder, orderID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalMethodPIN, approval.Method)
+	assert.Equal(t, manager.ID, approval.ApproverID)
+	assert.Equal(t, orderID, approval.EntityID)
+
+	_, err = u.Approve(domain.WithOverride(cashier, &domain.OverrideCredentials{ManagerUsername: "sam", PIN: "0000"}), domain.OverrideCancelPaidOrder, orderID)
+	assert.ErrorIs(t, err, domain.ErrInvalidOverride)
+	// A correct PIN from someone who could not do it themselves is no approval.
+	_, err = u.Approve(domain.WithOverride(cashier, &domain.OverrideCredentials{ManagerUsername: "bo", PIN: "1111"}), domain.OverrideCancelPaidOrder, orderID)
+	assert.ErrorIs(t, err, domain.ErrInvalidOverride)
+}
+
+func TestOverrideUsecase_Approve_ByToken(t *testing.T) {
+	overrideRepo := new(mockOverrideRepo)
+	staffRepo := new(mockStaffRepo)
+	u := NewOverrideUsecase(overrideRepo, staffRepo, testOverrideConfig())
+	manager := &domain.Staff{ID: uuid.New(), Role: domain.RoleManager, Active: true}
+	orderID := uuid.New()
+
+	tokenID := uuid.New()
+	overrideRepo.On("FindToken", mock.Anything, hashToken("apr_good"), domain.OverrideCancelPaidOrder, orderID, mock.AnythingOfType("time.Time")).
+		Return(&domain.ApprovalToken{ID: tokenID, ManagerID: manager.ID}, nil).Once()
+	overrideRepo.On("FindToken", mock.Anything, hashToken("apr_good"), domain.OverrideCancelPaidOrder, orderID, mock.AnythingOfType("time.Time")).
+		Return(nil, nil)
+	staffRepo.On("GetByID", mock.Anything, manager.ID).Return(manager, nil)
+
+	ctx := domain.WithOverride(staffCtx(domain.RoleCashier), &domain.OverrideCredentials{ApprovalToken: "apr_good"})
+	approval, err := u.Approve(ctx, domain.OverrideCancelPaidOrder, orderID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalMethodToken, approval.Method)
+	assert.Equal(t, manager.ID, approval.ApproverID)
+	// The token is only used up when the approval is recorded with the action.
+	assert.Equal(t, tokenID, *approval.TokenID)
+	overrideRepo.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+
+	// A token that has been used is no longer found.
+	_, err = u.Approve(ctx, domain.OverrideCancelPaidOrder, orderID)
+	assert.ErrorIs(t, err, domain.ErrInvalidOverride)
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidTender        = errors.New("tender must be cash, card or gift_card")
+	ErrInvalidPaymentAmount = errors.New("payment amount must be greater than zero")
+	ErrGiftCardCodeRequired = errors.New("gift card payments require a gift card code")
+)
+
+type paymentUsecase struct {
+	paymentRepo  domain.PaymentRepository
+	orderRepo    domain.OrderRepository
+	giftCardRepo domain.GiftCardRepository
+	orderUsecase domain.OrderUsecase
+}
+
+// NewPaymentUsecase creates the payment usecase. Orders are moved to paid
+// through orderUsecase so the usual paid-order side effects run.
+func NewPaymentUsecase(paymentRepo domain.PaymentRepository, orderRepo domain.OrderRepository, giftCardRepo domain.GiftCardRepository, orderUsecase domain.OrderUsecase) domain.PaymentUsecase {
+	return &paymentUsecase{
+		paymentRepo:  paymentRepo,
+		orderRepo:    orderRepo,
+		giftCardRepo: giftCardRepo,
+		orderUsecase: orderUsecase,
+	}
+}
+
+func (u *paymentUsecase) Pay(ctx context.Context, orderID uuid.UUID, payment *domain.Payment) (*domain.OrderBalance, error) {
+	if err := domain.Authorize(ctx, domain.PermPaymentsTake); err != nil {
+		return nil, err
+	}
+	switch payment.Tender {
+	case domain.TenderCash, domain.TenderCard, domain.TenderGiftCard:
+	default:
+		return nil, ErrInvalidTender
+	}
+	payment.Amount = payment.Amount.Round(2)
+	if !payment.Amount.IsPositive() {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	payment.GiftCardID = nil
+	if payment.Tender == domain.TenderGiftCard {
+		if payment.GiftCardCode == "" {
+			return nil, ErrGiftCardCodeRequired
+		}
+		card, err := u.giftCardRepo.GetByCode(ctx, normalizeGiftCardCode(payment.GiftCardCode))
+		if err != nil {
+			return nil, err
+		}
+		if card == nil {
+			return nil, ErrGiftCardNotFound
+		}
+		payment.GiftCardID = &card.ID
+		payment.GiftCardCode = card.Code
+	} else {
+		payment.GiftCardCode = ""
+	}
+
+	payment.ID = uuid.New()
+	payment.OrderID = orderID
+	payment.CreatedAt = time.Now()
+	if err := u.paymentRepo.Create(ctx, payment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	balance, err := u.balance(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Due.IsZero() {
+		if err := u.orderUsecase.UpdateStatus(ctx, orderID, domain.OrderStatusPaid, ""); err != nil {
+			return nil, err
+		}
+		balance.Status = domain.OrderStatusPaid
+	}
+	return balance, nil
+}
+
+func (u *paymentUsecase) GetBalance(ctx context.Context, orderID uuid.UUID) (*domain.OrderBalance, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	return u.balance(ctx, order)
+}
+
+func (u *paymentUsecase) balance(ctx context.Context, order *domain.Order) (*domain.OrderBalance, error) {
+	payments, err := u.paymentRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := u.paymentRepo.ListRefunds(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := decimal.Zero
+	for _, payment := range payments {
+		paid = paid.Add(payment.Amount)
+	}
+	refunded := decimal.Zero
+	for _, refund := range refunds {
+		refunded = refunded.Add(refund.Amount)
+	}
+	return &domain.OrderBalance{
+		OrderID:  order.ID,
+		Status:   order.Status,
+		Total:    order.Total,
+		Paid:     paid,
+		Refunded: refunded,
+		Due:      order.Total.Sub(paid).Add(refunded),
+		Payments: payments,
+		Refunds:  refunds,
+	}, nil
+}
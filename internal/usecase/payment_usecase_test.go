@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPaymentRepo struct{ mock.Mock }
+
+func (m *mockPaymentRepo) Create(ctx context.Context, payment *domain.Payment) error {
+	args := m.Called(ctx, payment)
+	return args.Error(0)
+}
+func (m *mockPaymentRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+func (m *mockPaymentRepo) ListRefunds(ctx context.Context, orderID uuid.UUID) ([]domain.Refund, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.Refund), args.Error(1)
+}
+
+func TestPaymentUsecase_Pay_SplitTenders(t *testing.T) {
+	paymentRepo := new(mockPaymentRepo)
+	orderRepo := new(mockOrderRepo)
+	giftCardRepo := new(mockGiftCardRepo)
+	u := NewPaymentUsecase(paymentRepo, orderRepo, giftCardRepo, NewOrderUsecase(orderRepo, new(mockMenuRepository)))
+
+	orderID := uuid.New()
+	card := &domain.GiftCard{ID: uuid.New(), Code: "ABCD-EFGH-JKLM-NP23"}
+	order := &domain.Order{ID: orderID, Status: domain.OrderStatusPending, Total: decimal.NewFromInt(10)}
+	orderRepo.On("GetByID", mock.Anything, orderID).Return(order, nil)
+	giftCardRepo.On("GetByCode", mock.Anything, card.Code).Return(card, nil)
+	paymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Tender == domain.TenderGiftCard && *p.GiftCardID == card.ID
+	})).Return(nil).Once()
+	paymentRepo.On("ListByOrder", mock.Anything, orderID).Return([]domain.Payment{{Amount: decimal.NewFromInt(4)}}, nil).Once()
+	paymentRepo.On("ListRefunds", mock.Anything, orderID).Return([]domain.Refund{}, nil)
+
+	balance, err := u.Pay(managerCtx(), orderID, &domain.Payment{
+		Tender:       domain.TenderGiftCard,
+		Amount:       decimal.NewFromInt(4),
+		GiftCardCode: "abcd efgh jklm np23",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "6", balance.Due.String())
+	assert.Equal(t, domain.OrderStatusPending, balance.Status)
+
+	paymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Tender == domain.TenderCash && p.GiftCardID == nil
+	})).Return(nil).Once()
+	paymentRepo.On("ListByOrder", mock.Anything, orderID).Return([]domain.Payment{
+		{Amount: decimal.NewFromInt(4)},
+		{Amount: decimal.NewFromInt(6)},
+	}, nil).Once()
+	orderRepo.On("UpdateStatus", mock.Anything, statusChange(orderID, domain.OrderStatusPaid), mock.Anything).Return(nil)
+
+	balance, err = u.Pay(managerCtx(), orderID, &domain.Payment{Tender: domain.TenderCash, Amount: decimal.NewFromInt(6)})
+	assert.NoError(t, err)
+	assert.True(t, balance.Due.IsZero())
+	assert.Equal(t, domain.OrderStatusPaid, balance.Status)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestPaymentUsecase_Pay_Validation(t *testing.T) {
+	orderRepo := new(mockOrderRepo)
+	giftCardRepo := new(mockGiftCardRepo)
+	u := NewPaymentUsecase(new(mockPaymentRepo), orderRepo, giftCardRepo, NewOrderUsecase(orderRepo, new(mockMenuRepository)))
+
+	_, err := u.Pay(staffCtx(domain.RoleBarista), uuid.New(), &domain.Payment{Tender: domain.TenderCash, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = u.Pay(managerCtx(), uuid.New(), &domain.Payment{Tender: "cheque", Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, ErrInvalidTender)
+
+	_, err = u.Pay(managerCtx(), uuid.New(), &domain.Payment{Tender: domain.TenderCash, Amount: decimal.Zero})
+	assert.ErrorIs(t, err, ErrInvalidPaymentAmount)
+
+	paidID := uuid.New()
+	orderRepo.On("GetByID", mock.Anything, paidID).Return(&domain.Order{ID: paidID, Status: domain.OrderStatusPaid}, nil)
+	_, err = u.Pay(managerCtx(), paidID, &domain.Payment{Tender: domain.TenderCash, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, domain.ErrOrderNotPayable)
+
+	pendingID := uuid.New()
+	orderRepo.On("GetByID", mock.Anything, pendingID).Return(&domain.Order{ID: pendingID, Status: domain.OrderStatusPending}, nil)
+	giftCardRepo.On("GetByCode", mock.Anything, "NOPE").Return(nil, nil)
+	_, err = u.Pay(managerCtx(), pendingID, &domain.Payment{Tender: domain.TenderGiftCard, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, ErrGiftCardCodeRequired)
+	_, err = u.Pay(managerCtx(), pendingID, &domain.Payment{Tender: domain.TenderGiftCard, Amount: decimal.NewFromInt(1), GiftCardCode: "nope"})
+	assert.ErrorIs(t, err, ErrGiftCardNotFound)
+}
+
+func TestPaymentUsecase_GetBalance_Refunds(t *testing.T) {
+	paymentRepo := new(mockPaymentRepo)
+	orderRepo := new(mockOrderRepo)
+	u := NewPaymentUsecase(paymentRepo, orderRepo, new(mockGiftCardRepo), NewOrderUsecase(orderRepo, new(mockMenuRepository)))
+
+	orderID := uuid.New()
+	// A 4.40 line was voided from an 11.00 order paid in full.
+	orderRepo.On("GetByID", mock.Anything, orderID).Return(&domain.Order{ID: orderID, Status: domain.OrderStatusPaid, Total: decimal.NewFromFloat(6.6)}, nil)
+	paymentRepo.On("ListByOrder", mock.Anything, orderID).Return([]domain.Payment{{Amount: decimal.NewFromInt(11)}}, nil)
+	paymentRepo.On("ListRefunds", mock.Anything, orderID).Return([]domain.Refund{{Amount: decimal.NewFromFloat(4.4)}}, nil)
+
+	balance, err := u.GetBalance(managerCtx(), orderID)
+	assert.NoError(t, err)
+	assert.True(t, balance.Refunded.Equal(decimal.NewFromFloat(4.4)))
+	assert.True(t, balance.Due.IsZero())
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidDateRange = errors.New("report start must be before its end")
+
+type reportUsecase struct {
+	reportRepo domain.ReportRepository
+}
+
+func NewReportUsecase(reportRepo domain.ReportRepository) domain.ReportUsecase {
+	return &reportUsecase{reportRepo: reportRepo}
+}
+
+func (u *reportUsecase) MenuItemCosts(ctx context.Context) ([]domain.MenuItemCost, error) {
+	if err := domain.Authorize(ctx, domain.PermReportsRead); err != nil {
+		return nil, err
+	}
+	costs, err := u.reportRepo.MenuItemCosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range costs {
+		costs[i].Cost = costs[i].Cost.Round(4)
+		costs[i].Margin = costs[i].Price.Sub(costs[i].Cost).Round(2)
+		costs[i].MarginPercent = marginPercent(costs[i].Margin, costs[i].Price)
+	}
+	return costs, nil
+}
+
+func (u *reportUsecase) MarginReport(ctx context.Context, from, to time.Time) (*domain.MarginReport, error) {
+	if err := domain.Authorize(ctx, domain.PermReportsRead); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidDateRange
+	}
+
+	sales, err := u.reportRepo.ItemSales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.MarginReport{
+		From:       from,
+		To:         to,
+		Items:      make([]domain.ItemSales, 0, len(sales)),
+		Categories: []domain.CategorySales{},
+		Revenue:    decimal.Zero,
+		Cost:       decimal.Zero,
+	}
+	categoryIndex := make(map[string]int)
+	for _, item := range sales {
+		item.Cost = item.Cost.Round(2)
+		item.Margin = item.Revenue.Sub(item.Cost)
+		item.MarginPercent = marginPercent(item.Margin, item.Revenue)
+		report.Items = append(report.Items, item)
+
+		idx, ok := categoryIndex[item.Category]
+		if !ok {
+			idx = len(report.Categories)
+			categoryIndex[item.Category] = idx
+			report.Categories = append(report.Categories, domain.CategorySales{
+				Category: item.Category,
+				Revenue:  decimal.Zero,
+				Cost:     decimal.Zero,
+			})
+		}
+		category := &report.Categories[idx]
+		category.Quantity += item.Quantity
+		category.Revenue = category.Revenue.Add(item.Revenue)
+		category.Cost = category.Cost.Add(item.Cost)
+
+		report.Revenue = report.Revenue.Add(item.Revenue)
+		report.Cost = report.Cost.Add(item.Cost)
+	}
+
+	for i := range report.Categories {
+		report.Categories[i].Margin = report.Categories[i].Revenue.Sub(report.Categories[i].Cost)
+		report.Categories[i].MarginPercent = marginPercent(report.Categories[i].Margin, report.Categories[i].Revenue)
+	}
+	report.Margin = report.Revenue.Sub(report.Cost)
+	report.MarginPercent = marginPercent(report.Margin, report.Revenue)
+
+	return report, nil
+}
+
+// marginPercent returns margin as a percentage of revenue, or zero when there
+// is no revenue.
+func marginPercent(margin, revenue decimal.Decimal) decimal.Decimal {
+	if revenue.IsZero() {
+		return decimal.Zero
+	}
+	return margin.Div(revenue).Mul(decimal.NewFromInt(100)).Round(2)
+}
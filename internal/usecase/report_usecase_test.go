@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportRepo struct{ mock.Mock }
+
+func (m *mockReportRepo) MenuItemCosts(ctx context.Context) ([]domain.MenuItemCost, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.MenuItemCost), args.Error(1)
+}
+func (m *mockReportRepo) ItemSales(ctx context.Context, from, to time.Time) ([]domain.ItemSales, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.ItemSales), args.Error(1)
+}
+
+func TestReportUsecase_MenuItemCosts(t *testing.T) {
+	reportRepo := new(mockReportRepo)
+	u := NewReportUsecase(reportRepo)
+
+	reportRepo.On("MenuItemCosts", mock.Anything).Return([]domain.MenuItemCost{
+		{MenuItemID: uuid.New(), Name: "Latte", Price: decimal.NewFromFloat(4.00), Cost: decimal.NewFromFloat(1.00)},
+		{MenuItemID: uuid.New(), Name: "Water", Price: decimal.NewFromFloat(1.00), Cost: decimal.Zero},
+	}, nil)
+
+	costs, err := u.MenuItemCosts(managerCtx())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3.00", costs[0].Margin.StringFixed(2))
+	assert.Equal(t, "75.00", costs[0].MarginPercent.StringFixed(2))
+	assert.Equal(t, "100.00", costs[1].MarginPercent.StringFixed(2))
+}
+
+func TestReportUsecase_MarginReport(t *testing.T) {
+	reportRepo := new(mockReportRepo)
+	u := NewReportUsecase(reportRepo)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	reportRepo.On("ItemSales", mock.Anything, from, to).Return([]domain.ItemSales{
+		{MenuItemID: uuid.New(), Name: "Latte", Category: "Coffee", Quantity: 10, Revenue: decimal.NewFromInt(40), Cost: decimal.NewFromInt(10)},
+		{MenuItemID: uuid.New(), Name: "Mocha", Category: "Coffee", Quantity: 5, Revenue: decimal.NewFromInt(25), Cost: decimal.NewFromInt(10)},
+		{MenuItemID: uuid.New(), Name: "Muffin", Category: "Bakery", Quantity: 4, Revenue: decimal.NewFromInt(12), Cost: decimal.NewFromInt(6)},
+	}, nil)
+
+	report, err := u.MarginReport(managerCtx(), from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Items, 3)
+	assert.Len(t, report.Categories, 2)
+	assert.Equal(t, "Coffee", report.Categories[0].Category)
+	assert.Equal(t, 15, report.Categories[0].Quantity)
+	assert.Equal(t, "45.00", report.Categories[0].Margin.StringFixed(2))
+	assert.Equal(t, "51.00", report.Margin.StringFixed(2))
+	assert.Equal(t, "66.23", report.MarginPercent.StringFixed(2))
+}
+
+func TestReportUsecase_MarginReport_InvalidRange(t *testing.T) {
+	u := NewReportUsecase(new(mockReportRepo))
+	now := time.Now()
+
+	_, err := u.MarginReport(managerCtx(), now, now)
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
+
+func TestReportUsecase_RequiresManager(t *testing.T) {
+	u := NewReportUsecase(new(mockReportRepo))
+	cashier := staffCtx(domain.RoleCashier)
+
+	_, err := u.MenuItemCosts(cashier)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	now := time.Now()
+	_, err = u.MarginReport(cashier, now.AddDate(0, 0, -7), now)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
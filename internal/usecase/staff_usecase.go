@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+var (
+	ErrInvalidUsername  = errors.New("username must be 3-64 characters of letters, digits, '.', '_' or '-'")
+	ErrInvalidStaffName = errors.New("staff name is required")
+	ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+	ErrPasswordTooLong  = errors.New("password must be at most 72 bytes")
+	ErrInvalidRole      = errors.New("role must be cashier, barista or manager")
+	ErrInvalidPIN       = errors.New("PIN must be 4 to 6 digits")
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,64}$`)
+	pinPattern      = regexp.MustCompile(`^[0-9]{4,6}$`)
+)
+
+type staffUsecase struct {
+	staffRepo domain.StaffRepository
+}
+
+func NewStaffUsecase(staffRepo domain.StaffRepository) domain.StaffUsecase {
+	return &staffUsecase{staffRepo: staffRepo}
+}
+
+func (u *staffUsecase) Create(ctx context.Context, staff *domain.Staff, password string) error {
+	if err := domain.Authorize(ctx, domain.PermStaffManage); err != nil {
+		return err
+	}
+	return u.create(ctx, staff, password)
+}
+
+func (u *staffUsecase) create(ctx context.Context, staff *domain.Staff, password string) error {
+	staff.Username = normalizeUsername(staff.Username)
+	staff.Name = strings.TrimSpace(staff.Name)
+	if !usernamePattern.MatchString(staff.Username) {
+		return ErrInvalidUsername
+	}
+	if staff.Name == "" {
+		return ErrInvalidStaffName
+	}
+	if staff.Role == "" {
+		staff.Role = domain.RoleCashier
+	}
+	if !domain.IsValidRole(staff.Role) {
+		return ErrInvalidRole
+	}
+	if len(password) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	// bcrypt ignores everything past 72 bytes.
+	if len(password) > 72 {
+		return ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	staff.ID = uuid.New()
+	staff.PasswordHash = string(hash)
+	staff.Active = true
+	staff.CreatedAt = now
+	staff.UpdatedAt = now
+	return u.staffRepo.Create(ctx, staff)
+}
+
+func (u *staffUsecase) List(ctx context.Context) ([]domain.Staff, error) {
+	if err := domain.Authorize(ctx, domain.PermStaffManage); err != nil {
+		return nil, err
+	}
+	return u.staffRepo.List(ctx)
+}
+
+func (u *staffUsecase) SetPIN(ctx context.Context, id uuid.UUID, pin string) error {
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok || (identity.StaffID != id && !identity.Can(domain.PermStaffManage)) {
+		return domain.ErrForbidden
+	}
+	if !pinPattern.MatchString(pin) {
+		return ErrInvalidPIN
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	err = u.staffRepo.SetPIN(ctx, id, string(hash), time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *staffUsecase) EnsureAdmin(ctx context.Context, username, password string) error {
+	count, err := u.staffRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return u.create(ctx, &domain.Staff{Username: username, Name: username, Role: domain.RoleManager}, password)
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockStaffRepo struct{ mock.Mock }
+
+func (m *mockStaffRepo) Create(ctx context.Context, staff *domain.Staff) error {
+	args := m.Called(ctx, staff)
+	return args.Error(0)
+}
+func (m *mockStaffRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Staff, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Staff), args.Error(1)
+}
+func (m *mockStaffRepo) GetByUsername(ctx context.Context, username string) (*domain.Staff, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Staff), args.Error(1)
+}
+func (m *mockStaffRepo) List(ctx context.Context) ([]domain.Staff, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Staff), args.Error(1)
+}
+func (m *mockStaffRepo) Count(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+func (m *mockStaffRepo) SetPIN(ctx context.Context, id uuid.UUID, pinHash string, updatedAt time.Time) error {
+	args := m.Called(ctx, id, pinHash, updatedAt)
+	return args.Error(0)
+}
+func (m *mockStaffRepo) ClaimPINAttempt(ctx context.Context, id uuid.UUID, maxAttempts int, now, lockUntil time.Time) (bool, error) {
+	args := m.Called(ctx, id, maxAttempts, now, lockUntil)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockStaffRepo) ResetPINFailures(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestStaffUsecase_Create_HashesPassword(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewStaffUsecase(staffRepo)
+
+	staffRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Staff")).Return(nil)
+
+	staff := &domain.Staff{Username: " Sam ", Name: "Sam"}
+	err := u.Create(managerCtx(), staff, "correct horse")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sam", staff.Username)
+	assert.True(t, staff.Active)
+	assert.Equal(t, domain.RoleCashier, staff.Role)
+	assert.NotEqual(t, "correct horse", staff.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(staff.PasswordHash), []byte("correct horse")))
+}
+
+func TestStaffUsecase_Create_ValidationErrors(t *testing.T) {
+	u := NewStaffUsecase(new(mockStaffRepo))
+	ctx := managerCtx()
+
+	assert.ErrorIs(t, u.Create(ctx, &domain.Staff{Username: "a b", Name: "Sam"}, "password1"), ErrInvalidUsername)
+	assert.ErrorIs(t, u.Create(ctx, &domain.Staff{Username: "sam"}, "password1"), ErrInvalidStaffName)
+	assert.ErrorIs(t, u.Create(ctx, &domain.Staff{Username: "sam", Name: "Sam"}, "short"), ErrPasswordTooShort)
+	assert.ErrorIs(t, u.Create(ctx, &domain.Staff{Username: "sam", Name: "Sam"}, strings.Repeat("x", 73)), ErrPasswordTooLong)
+	assert.ErrorIs(t, u.Create(ctx, &domain.Staff{Username: "sam", Name: "Sam", Role: "owner"}, "password1"), ErrInvalidRole)
+	assert.ErrorIs(t, u.Create(staffCtx(domain.RoleCashier), &domain.Staff{Username: "sam", Name: "Sam"}, "password1"), domain.ErrForbidden)
+}
+
+func TestStaffUsecase_EnsureAdmin_OnlyWhenEmpty(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewStaffUsecase(staffRepo)
+
+	staffRepo.On("Count", mock.Anything).Return(1, nil).Once()
+	assert.NoError(t, u.EnsureAdmin(context.Background(), "admin", "password1"))
+	staffRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	staffRepo.On("Count", mock.Anything).Return(0, nil).Once()
+	staffRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Staff) bool {
+		return s.Username == "admin" && s.Role == domain.RoleManager
+	})).Return(nil).Once()
+	assert.NoError(t, u.EnsureAdmin(context.Background(), "admin", "password1"))
+	staffRepo.AssertExpectations(t)
+}
+
+func TestStaffUsecase_SetPIN(t *testing.T) {
+	staffRepo := new(mockStaffRepo)
+	u := NewStaffUsecase(staffRepo)
+	cashier := &domain.Identity{StaffID: uuid.New(), Username: "sam", Role: domain.RoleCashier}
+	ctx := domain.WithIdentity(context.Background(), cashier)
+
+	staffRepo.On("SetPIN", mock.Anything, cashier.StaffID, mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("4821")) == nil
+	}), mock.AnythingOfType("time.Time")).Return(nil)
+
+	assert.NoError(t, u.SetPIN(ctx, cashier.StaffID, "4821"))
+	assert.ErrorIs(t, u.SetPIN(ctx, cashier.StaffID, "12a4"), ErrInvalidPIN)
+	assert.ErrorIs(t, u.SetPIN(ctx, cashier.StaffID, "1234567"), ErrInvalidPIN)
+	// Only managers can set someone else's PIN.
+	assert.ErrorIs(t, u.SetPIN(ctx, uuid.New(), "4821"), domain.ErrForbidden)
+	staffRepo.AssertExpectations(t)
+}
+
+func staffCtx(role string) context.Context {
+	return domain.WithIdentity(context.Background(), &domain.Identity{StaffID: uuid.New(), Username: role, Role: role})
+}
+
+func managerCtx() context.Context {
+	return staffCtx(domain.RoleManager)
+}
@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidStampProgramName = errors.New("stamp program name is required")
+	ErrInvalidStampsRequired   = errors.New("stamps required must be greater than zero")
+	ErrEmptyStampCategories    = errors.New("stamp program must cover at least one category")
+)
+
+type stampUsecase struct {
+	stampRepo    domain.StampRepository
+	customerRepo domain.CustomerRepository
+	menuRepo     domain.MenuItemRepository
+}
+
+func NewStampUsecase(stampRepo domain.StampRepository, customerRepo domain.CustomerRepository, menuRepo domain.MenuItemRepository) domain.StampUsecase {
+	return &stampUsecase{
+		stampRepo:    stampRepo,
+		customerRepo: customerRepo,
+		menuRepo:     menuRepo,
+	}
+}
+
+func (u *stampUsecase) CreateProgram(ctx context.Context, program *domain.StampProgram) error {
+	if err := domain.Authorize(ctx, domain.PermLoyaltyManage); err != nil {
+		return err
+	}
+	if err := normalizeStampProgram(program); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	program.ID = uuid.New()
+	program.CreatedAt = now
+	program.UpdatedAt = now
+	return u.stampRepo.CreateProgram(ctx, program)
+}
+
+func (u *stampUsecase) ListPrograms(ctx context.Context) ([]domain.StampProgram, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	return u.stampRepo.ListPrograms(ctx, false)
+}
+
+func (u *stampUsecase) UpdateProgram(ctx context.Context, program *domain.StampProgram) error {
+	if err := domain.Authorize(ctx, domain.PermLoyaltyManage); err != nil {
+		return err
+	}
+	if err := normalizeStampProgram(program); err != nil {
+		return err
+	}
+
+	existing, err := u.stampRepo.GetProgram(ctx, program.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return domain.ErrNotFound
+	}
+
+	program.CreatedAt = existing.CreatedAt
+	program.UpdatedAt = time.Now()
+	err = u.stampRepo.UpdateProgram(ctx, program)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *stampUsecase) GetCards(ctx context.Context, customerID uuid.UUID) ([]domain.StampCard, error) {
+	if err := domain.Authorize(ctx, domain.PermCustomersRead); err != nil {
+		return nil, err
+	}
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	programs, err := u.stampRepo.ListPrograms(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := u.stampRepo.Balances(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]domain.StampCard, 0, len(programs))
+	for _, program := range programs {
+		stamps := balances[program.ID]
+		cards = append(cards, domain.StampCard{
+			ProgramID:      program.ID,
+			ProgramName:    program.Name,
+			Stamps:         stamps,
+			StampsRequired: program.StampsRequired,
+			Full:           stamps >= program.StampsRequired,
+		})
+	}
+	return cards, nil
+}
+
+// ApplyRewards makes the cheapest qualifying unit free for each full card. The
+// free unit becomes its own zero-price line tagged with the program.
+func (u *stampUsecase) ApplyRewards(ctx context.Context, order *domain.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	programs, err := u.stampRepo.ListPrograms(ctx, true)
+	if err != nil || len(programs) == 0 {
+		return err
+	}
+	balances, err := u.stampRepo.Balances(ctx, *order.CustomerID)
+	if err != nil {
+		return err
+	}
+	categories, err := u.itemCategories(ctx, order.Items)
+	if err != nil {
+		return err
+	}
+
+	for _, program := range programs {
+		if balances[program.ID] < program.StampsRequired {
+			continue
+		}
+
+		cheapest := -1
+		for i, item := range order.Items {
+			if item.StampProgramID != nil || !item.UnitPrice.IsPositive() || !programCovers(program, categories[item.MenuItemID]) {
+				continue
+			}
+			if cheapest < 0 || item.UnitPrice.LessThan(order.Items[cheapest].UnitPrice) {
+				cheapest = i
+			}
+		}
+		if cheapest < 0 {
+			continue
+		}
+
+		programID := program.ID
+		line := &order.Items[cheapest]
+		if line.Quantity == 1 {
+			line.UnitPrice = decimal.Zero
+			line.LineTotal = decimal.Zero
+			line.StampProgramID = &programID
+			continue
+		}
+		line.Quantity--
+		line.LineTotal = line.UnitPrice.Mul(decimal.NewFromInt(int64(line.Quantity)))
+		order.Items = append(order.Items, domain.OrderItem{
+			ID:             uuid.New(),
+			OrderID:        order.ID,
+			MenuItemID:     line.MenuItemID,
+			Quantity:       1,
+			UnitPrice:      decimal.Zero,
+			LineTotal:      decimal.Zero,
+			UnitCost:       line.UnitCost,
+			StampProgramID: &programID,
+		})
+	}
+	return nil
+}
+
+// RedeemRewards empties the card behind every free line of the order.
+func (u *stampUsecase) RedeemRewards(ctx context.Context, order *domain.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	orderID := order.ID
+	for _, item := range order.Items {
+		if item.StampProgramID == nil {
+			continue
+		}
+		program, err := u.stampRepo.GetProgram(ctx, *item.StampProgramID)
+		if err != nil {
+			return err
+		}
+		if program == nil {
+			return domain.ErrNotFound
+		}
+
+		err = u.stampRepo.Redeem(ctx, &domain.StampEntry{
+			ID:         uuid.New(),
+			ProgramID:  program.ID,
+			CustomerID: *order.CustomerID,
+			OrderID:    &orderID,
+			Type:       domain.StampEntryRedeem,
+			Stamps:     -program.StampsRequired,
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StampOrder adds one stamp per qualifying unit bought. Free lines earn none.
+func (u *stampUsecase) StampOrder(ctx context.Context, order *domain.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	programs, err := u.stampRepo.ListPrograms(ctx, true)
+	if err != nil || len(programs) == 0 {
+		return err
+	}
+	categories, err := u.itemCategories(ctx, order.Items)
+	if err != nil {
+		return err
+	}
+
+	orderID := order.ID
+	for _, program := range programs {
+		stamps := 0
+		for _, item := range order.Items {
+			if item.StampProgramID == nil && item.VoidedAt == nil && programCovers(program, categories[item.MenuItemID]) {
+				stamps += int(item.Units().IntPart())
+			}
+		}
+		if stamps == 0 {
+			continue
+		}
+
+		err := u.stampRepo.AddEntry(ctx, &domain.StampEntry{
+			ID:         uuid.New(),
+			ProgramID:  program.ID,
+			CustomerID: *order.CustomerID,
+			OrderID:    &orderID,
+			Type:       domain.StampEntryStamp,
+			Stamps:     stamps,
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReverseLine takes back one stamp per unit of the voided line from each
+// program that stamped the order for it. Free reward lines earned nothing.
+func (u *stampUsecase) ReverseLine(ctx context.Context, order *domain.Order, itemID uuid.UUID) ([]domain.StampEntry, error) {
+	if order.CustomerID == nil {
+		return nil, nil
+	}
+	var item *domain.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			item = &order.Items[i]
+		}
+	}
+	if item == nil || item.StampProgramID != nil {
+		return nil, nil
+	}
+
+	entries, err := u.stampRepo.ListOrderEntries(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	earned := make(map[uuid.UUID]int)
+	for _, entry := range entries {
+		switch entry.Type {
+		case domain.StampEntryReversal:
+			return nil, nil
+		case domain.StampEntryStamp, domain.StampEntryVoid:
+			earned[entry.ProgramID] += entry.Stamps
+		}
+	}
+	if len(earned) == 0 {
+		return nil, nil
+	}
+
+	// Programs deactivated since the order was stamped still give stamps back.
+	programs, err := u.stampRepo.ListPrograms(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := u.itemCategories(ctx, []domain.OrderItem{*item})
+	if err != nil {
+		return nil, err
+	}
+
+	units := int(item.Units().IntPart())
+	orderID := order.ID
+	var voids []domain.StampEntry
+	for _, program := range programs {
+		if earned[program.ID] <= 0 || !programCovers(program, categories[item.MenuItemID]) {
+			continue
+		}
+		stamps := units
+		if stamps > earned[program.ID] {
+			stamps = earned[program.ID]
+		}
+		if stamps == 0 {
+			continue
+		}
+		voids = append(voids, domain.StampEntry{
+			ID:          uuid.New(),
+			ProgramID:   program.ID,
+			CustomerID:  *order.CustomerID,
+			OrderID:     &orderID,
+			OrderItemID: &itemID,
+			Type:        domain.StampEntryVoid,
+			Stamps:      -stamps,
+			CreatedAt:   time.Now(),
+		})
+	}
+	return voids, nil
+}
+
+// ReverseOrder offsets the stamps an order earned or redeemed, per program.
+func (u *stampUsecase) ReverseOrder(ctx context.Context, orderID uuid.UUID) error {
+	entries, err := u.stampRepo.ListOrderEntries(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	net := make(map[uuid.UUID]int)
+	reversed := make(map[uuid.UUID]bool)
+	customers := make(map[uuid.UUID]uuid.UUID)
+	var programIDs []uuid.UUID
+	for _, entry := range entries {
+		if _, ok := customers[entry.ProgramID]; !ok {
+			programIDs = append(programIDs, entry.ProgramID)
+			customers[entry.ProgramID] = entry.CustomerID
+		}
+		if entry.Type == domain.StampEntryReversal {
+			reversed[entry.ProgramID] = true
+		}
+		net[entry.ProgramID] += entry.Stamps
+	}
+
+	for _, programID := range programIDs {
+		if reversed[programID] || net[programID] == 0 {
+			continue
+		}
+		err := u.stampRepo.AddEntry(ctx, &domain.StampEntry{
+			ID:         uuid.New(),
+			ProgramID:  programID,
+			CustomerID: customers[programID],
+			OrderID:    &orderID,
+			Type:       domain.StampEntryReversal,
+			Stamps:     -net[programID],
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemCategories looks up the menu category of every item on an order.
+func (u *stampUsecase) itemCategories(ctx context.Context, items []domain.OrderItem) (map[uuid.UUID]string, error) {
+	categories := make(map[uuid.UUID]string, len(items))
+	for _, item := range items {
+		if _, ok := categories[item.MenuItemID]; ok {
+			continue
+		}
+		menuItem, err := u.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return nil, err
+		}
+		if menuItem != nil {
+			categories[item.MenuItemID] = menuItem.Category
+		}
+	}
+	return categories, nil
+}
+
+func programCovers(program domain.StampProgram, category string) bool {
+	for _, c := range program.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeStampProgram(program *domain.StampProgram) error {
+	program.Name = strings.TrimSpace(program.Name)
+	if program.Name == "" {
+		return ErrInvalidStampProgramName
+	}
+	if program.StampsRequired <= 0 {
+		return ErrInvalidStampsRequired
+	}
+
+	categories := make([]string, 0, len(program.Categories))
+	for _, category := range program.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		return ErrEmptyStampCategories
+	}
+	program.Categories = categories
+	return nil
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStampRepo struct{ mock.Mock }
+
+func (m *mockStampRepo) CreateProgram(ctx context.Context, program *domain.StampProgram) error {
+	args := m.Called(ctx, program)
+	return args.Error(0)
+}
+func (m *mockStampRepo) GetProgram(ctx context.Context, id uuid.UUID) (*domain.StampProgram, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StampProgram), args.Error(1)
+}
+func (m *mockStampRepo) ListPrograms(ctx context.Context, activeOnly bool) ([]domain.StampProgram, error) {
+	args := m.Called(ctx, activeOnly)
+	return args.Get(0).([]domain.StampProgram), args.Error(1)
+}
+func (m *mockStampRepo) UpdateProgram(ctx context.Context, program *domain.StampProgram) error {
+	args := m.Called(ctx, program)
+	return args.Error(0)
+}
+func (m *mockStampRepo) Balances(ctx context.Context, customerID uuid.UUID) (map[uuid.UUID]int, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(map[uuid.UUID]int), args.Error(1)
+}
+func (m *mockStampRepo) ListOrderEntries(ctx context.Context, orderID uuid.UUID) ([]domain.StampEntry, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.StampEntry), args.Error(1)
+}
+func (m *mockStampRepo) AddEntry(ctx context.Context, entry *domain.StampEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+func (m *mockStampRepo) Redeem(ctx context.Context, entry *domain.StampEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func TestStampUsecase_CreateProgram_Validation(t *testing.T) {
+	u := NewStampUsecase(new(mockStampRepo), new(mockCustomerRepo), new(mockMenuRepository))
+
+	err := u.CreateProgram(managerCtx(), &domain.StampProgram{StampsRequired: 9, Categories: []string{"Coffee"}})
+	assert.ErrorIs(t, err, ErrInvalidStampProgramName)
+
+	err = u.CreateProgram(managerCtx(), &domain.StampProgram{Name: "Coffee card", Categories: []string{"Coffee"}})
+	assert.ErrorIs(t, err, ErrInvalidStampsRequired)
+
+	err = u.CreateProgram(managerCtx(), &domain.StampProgram{Name: "Coffee card", StampsRequired: 9, Categories: []string{" "}})
+	assert.ErrorIs(t, err, ErrEmptyStampCategories)
+}
+
+func TestStampUsecase_ApplyRewards_FreesCheapestQualifyingUnit(t *testing.T) {
+	stampRepo := new(mockStampRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)
+
+	customerID := uuid.New()
+	programID := uuid.New()
+	latteID := uuid.New()
+	espressoID := uuid.New()
+	cakeID := uuid.New()
+	stampRepo.On("ListPrograms", mock.Anything, true).Return([]domain.StampProgram{
+		{ID: programID, Name: "Hot coffee", Categories: []string{"Hot Coffee"}, StampsRequired: 9, Active: true},
+	}, nil)
+	stampRepo.On("Balances", mock.Anything, customerID).Return(map[uuid.UUID]int{programID: 9}, nil)
+	menuRepo.On("GetByID", mock.Anything, latteID).Return(&domain.MenuItem{ID: latteID, Category: "Hot Coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, espressoID).Return(&domain.MenuItem{ID: espressoID, Category: "hot coffee"}, nil)
+	menuRepo.On("GetByID", mock.Anything, cakeID).Return(&domain.MenuItem{ID: cakeID, Category: "Bakery"}, nil)
+
+	order := &domain.Order{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Items: []domain.OrderItem{
+			{MenuItemID: latteID, Quantity: 1, UnitPrice: decimal.NewFromFloat(4.50), LineTotal: decimal.NewFromFloat(4.50)},
+			{MenuItemID: espressoID, Quantity: 2, UnitPrice: decimal.NewFromFloat(3), LineTotal: decimal.NewFromFloat(6)},
+			{MenuItemID: cakeID, Quantity: 1, UnitPrice: decimal.NewFromFloat(2), LineTotal: decimal.NewFromFloat(2)},
+		},
+	}
+	err := u.ApplyRewards(context.Background(), order)
+
+	assert.NoError(t, err)
+	assert.Len(t, order.Items, 4)
+	assert.Equal(t, 1, order.Items[1].Quantity)
+	assert.Equal(t, "3", order.Items[1].LineTotal.String())
+	free := order.Items[3]
+	assert.Equal(t, espressoID, free.MenuItemID)
+	assert.Equal(t, 1, free.Quantity)
+	assert.True(t, free.LineTotal.IsZero())
+	assert.Equal(t, programID, *free.StampProgramID)
+}
+
+func TestStampUsecase_StampOrder_SkipsFreeLines(t *testing.T) {
+	stampRepo := new(mockStampRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)
+
+	customerID := uuid.New()
+	programID := uuid.New()
+	latteID := uuid.New()
+	stampRepo.On("ListPrograms", mock.Anything, true).Return([]domain.StampProgram{
+		{ID: programID, Categories: []string{"Hot Coffee"}, StampsRequired: 9},
+	}, nil)
+	menuRepo.On("GetByID", mock.Anything, latteID).Return(&domain.MenuItem{ID: latteID, Category: "Hot Coffee"}, nil)
+	stampRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.StampEntry) bool {
+		return e.Type == domain.StampEntryStamp && e.Stamps == 2 && e.ProgramID == programID
+	})).Return(nil)
+
+	err := u.StampOrder(context.Background(), &domain.Order{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Items: []domain.OrderItem{
+			{MenuItemID: latteID, Quantity: 2},
+			{MenuItemID: latteID, Quantity: 1, StampProgramID: &programID},
+		},
+	})
+
+	assert.NoError(t, err)
+	stampRepo.AssertExpectations(t)
+}
+
+func TestStampUsecase_ReverseLine(t *testing.T) {
+	stampRepo := new(mockStampRepo)
+	menuRepo := new(mockMenuRepository)
+	u := NewStampUsecase(stampRepo, new(mockCustomerRepo), menuRepo)
+
+	customerID, programID, otherID, latteID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	order := &domain.Order{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Items: []domain.OrderItem{
+			{ID: uuid.New(), MenuItemID: latteID, Quantity: 3},
+			{ID: uuid.New(), MenuItemID: latteID, Quantity: 1, StampProgramID: &programID},
+		},
+	}
+	// The program was switched off after the order was stamped.
+	stampRepo.On("ListPrograms", mock.Anything, false).Return([]domain.StampProgram{
+		{ID: programID, Categories: []string{"Hot Coffee"}, StampsRequired: 9},
+		{ID: otherID, Categories: []string{"Bakery"}, StampsRequired: 5, Active: true},
+	}, nil)
+	menuRepo.On("GetByID", mock.Anything, latteID).Return(&domain.MenuItem{ID: latteID, Category: "Hot Coffee"}, nil)
+	stampRepo.On("ListOrderEntries", mock.Anything, order.ID).Return([]domain.StampEntry{
+		{ProgramID: programID, Type: domain.StampEntryRedeem, Stamps: -9},
+		{ProgramID: programID, Type: domain.StampEntryStamp, Stamps: 5},
+		{ProgramID: programID, Type: domain.StampEntryVoid, Stamps: -3},
+		{ProgramID: otherID, Type: domain.StampEntryStamp, Stamps: 1},
+	}, nil)
+
+	entries, err := u.ReverseLine(context.Background(), order, order.Items[0].ID)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, programID, entries[0].ProgramID)
+		assert.Equal(t, domain.StampEntryVoid, entries[0].Type)
+		assert.Equal(t, -2, entries[0].Stamps)
+		assert.Equal(t, order.Items[0].ID, *entries[0].OrderItemID)
+	}
+
+	// The free reward line never earned a stamp.
+	entries, err = u.ReverseLine(context.Background(), order, order.Items[1].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStampUsecase_ReverseOrder(t *testing.T) {
+	stampRepo := new(mockStampRepo)
+	u := NewStampUsecase(stampRepo, new(mockCustomerRepo), new(mockMenuRepository))
+
+	orderID := uuid.New()
+	customerID := uuid.New()
+	programID := uuid.New()
+	stampRepo.On("ListOrderEntries", mock.Anything, orderID).Return([]domain.StampEntry{
+		{ProgramID: programID, CustomerID: customerID, Type: domain.StampEntryRedeem, Stamps: -9},
+		{ProgramID: programID, CustomerID: customerID, Type: domain.StampEntryStamp, Stamps: 1},
+	}, nil)
+	stampRepo.On("AddEntry", mock.Anything, mock.MatchedBy(func(e *domain.StampEntry) bool {
+		return e.Type == domain.StampEntryReversal && e.Stamps == 8 && e.CustomerID == customerID
+	})).Return(nil)
+
+	err := u.ReverseOrder(context.Background(), orderID)
+
+	assert.NoError(t, err)
+	stampRepo.AssertExpectations(t)
+}
+
+func TestStampUsecase_ProgramsRequireManager(t *testing.T) {
+	u := NewStampUsecase(new(mockStampRepo), new(mockCustomerRepo), new(mockMenuRepository))
+	cashier := staffCtx(domain.RoleCashier)
+	program := &domain.StampProgram{Name: "Coffee card", StampsRequired: 9, Categories: []string{"Coffee"}}
+
+	assert.ErrorIs(t, u.CreateProgram(cashier, program), domain.ErrForbidden)
+	assert.ErrorIs(t, u.UpdateProgram(cashier, program), domain.ErrForbidden)
+	_, err := u.ListPrograms(context.Background())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = u.GetCards(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
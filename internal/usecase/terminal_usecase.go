@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	deviceTokenPrefix  = "term_"
+	sessionTokenPrefix = "pos_"
+)
+
+var ErrInvalidTerminalName = errors.New("terminal name is required")
+
+// dummyPINHash plays the same role for PIN logins as dummyPasswordHash does for
+// password logins.
+var dummyPINHash, _ = bcrypt.GenerateFromPassword([]byte("000000"), bcrypt.DefaultCost)
+
+type TerminalConfig struct {
+	// SessionIdleTimeout ends a session that has not been used for this long.
+	SessionIdleTimeout time.Duration
+	// SessionMaxAge ends a session this long after the PIN login regardless of use.
+	SessionMaxAge  time.Duration
+	PINMaxAttempts int
+	PINLockout     time.Duration
+}
+
+type terminalUsecase struct {
+	terminalRepo domain.TerminalRepository
+	pins         *pinVerifier
+	config       TerminalConfig
+	now          func() time.Time
+}
+
+func NewTerminalUsecase(terminalRepo domain.TerminalRepository, staffRepo domain.StaffRepository, config TerminalConfig) domain.TerminalUsecase {
+	return &terminalUsecase{
+		terminalRepo: terminalRepo,
+		pins:         newPINVerifier(staffRepo, config.PINMaxAttempts, config.PINLockout),
+		config:       config,
+		now:          time.Now,
+	}
+}
+
+func (u *terminalUsecase) Register(ctx context.Context, terminal *domain.Terminal) error {
+	if err := domain.Authorize(ctx, domain.PermTerminalsManage); err != nil {
+		return err
+	}
+	terminal.Name = strings.TrimSpace(terminal.Name)
+	if terminal.Name == "" {
+		return ErrInvalidTerminalName
+	}
+
+	token, hash, err := newOpaqueToken(deviceTokenPrefix)
+	if err != nil {
+		return err
+	}
+	now := u.now()
+	terminal.ID = uuid.New()
+	terminal.TokenHash = hash
+	terminal.Active = true
+	terminal.CreatedAt = now
+	terminal.UpdatedAt = now
+	if err := u.terminalRepo.Create(ctx, terminal); err != nil {
+		return err
+	}
+	terminal.DeviceToken = token
+	return nil
+}
+
+func (u *terminalUsecase) List(ctx context.Context) ([]domain.Terminal, error) {
+	if err := domain.Authorize(ctx, domain.PermTerminalsManage); err != nil {
+		return nil, err
+	}
+	return u.terminalRepo.List(ctx)
+}
+
+func (u *terminalUsecase) Deactivate(ctx context.Context, id uuid.UUID) error {
+	if err := domain.Authorize(ctx, domain.PermTerminalsManage); err != nil {
+		return err
+	}
+	err := u.terminalRepo.Deactivate(ctx, id, u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (u *terminalUsecase) PINLogin(ctx context.Context, deviceToken, username, pin string) (*domain.TerminalLogin, error) {
+	terminal, err := u.terminalRepo.GetByTokenHash(ctx, hashToken(deviceToken))
+	if err != nil {
+		return nil, err
+	}
+	if terminal == nil || !terminal.Active {
+		return nil, domain.ErrInvalidTerminal
+	}
+
+	staff, err := u.pins.verify(ctx, username, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	token, hash, err := newOpaqueToken(sessionTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	session := &domain.TerminalSession{
+		ID:         uuid.New(),
+		TerminalID: terminal.ID,
+		StaffID:    staff.ID,
+		TokenHash:  hash,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(u.config.SessionMaxAge),
+		CreatedAt:  now,
+	}
+	if err := u.terminalRepo.StartSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &domain.TerminalLogin{
+		SessionToken: token,
+		ExpiresAt:    session.ExpiresAt,
+		TokenType:    "Bearer",
+		Staff: &domain.Identity{
+			StaffID:    staff.ID,
+			Username:   staff.Username,
+			Role:       staff.Role,
+			TerminalID: &terminal.ID,
+		},
+	}, nil
+}
+
+func (u *terminalUsecase) Logout(ctx context.Context, sessionToken string) error {
+	session, err := u.terminalRepo.GetSessionByTokenHash(ctx, hashToken(sessionToken))
+	if err != nil {
+		return err
+	}
+	if session == nil || session.EndedAt != nil {
+		return nil
+	}
+	return u.terminalRepo.EndSession(ctx, session.ID, u.now())
+}
+
+func (u *terminalUsecase) Authenticate(ctx context.Context, token string) (*domain.Identity, error) {
+	if !strings.HasPrefix(token, sessionTokenPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+	session, err := u.terminalRepo.GetSessionByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.EndedAt != nil || !session.StaffActive || !session.TerminalActive {
+		return nil, domain.ErrInvalidToken
+	}
+
+	now := u.now()
+	if !now.Before(session.ExpiresAt) || now.Sub(session.LastSeenAt) >= u.config.SessionIdleTimeout {
+		if err := u.terminalRepo.EndSession(ctx, session.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+	if err := u.terminalRepo.TouchSession(ctx, session.ID, now); err != nil {
+		return nil, err
+	}
+
+	return &domain.Identity{
+		StaffID:    session.StaffID,
+		Username:   session.Username,
+		Role:       session.Role,
+		TerminalID: &session.TerminalID,
+	}, nil
+}
+
+// pinVerifier checks staff PINs, locking a PIN after too many wrong guesses.
+// Terminal logins and manager overrides share it, so both count towards the
+// same lockout.
+type pinVerifier struct {
+	staffRepo   domain.StaffRepository
+	maxAttempts int
+	lockout     time.Duration
+	now         func() time.Time
+}
+
+func newPINVerifier(staffRepo domain.StaffRepository, maxAttempts int, lockout time.Duration) *pinVerifier {
+	return &pinVerifier{staffRepo: staffRepo, maxAttempts: maxAttempts, lockout: lockout, now: time.Now}
+}
+
+func (v *pinVerifier) verify(ctx context.Context, username, pin string) (*domain.Staff, error) {
+	staff, err := v.staffRepo.GetByUsername(ctx, normalizeUsername(username))
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil || !staff.Active || staff.PINHash == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPINHash, []byte(pin))
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	now := v.now()
+	if staff.PINLockedUntil != nil && now.Before(*staff.PINLockedUntil) {
+		return nil, domain.ErrPINLocked
+	}
+	// The row read above may be stale, so the attempt is claimed against the
+	// current lockout before the PIN is compared, and given back on success.
+	last, err := v.staffRepo.ClaimPINAttempt(ctx, staff.ID, v.maxAttempts, now, now.Add(v.lockout))
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*staff.PINHash), []byte(pin)); err != nil {
+		if last {
+			return nil, domain.ErrPINLocked
+		}
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err := v.staffRepo.ResetPINFailures(ctx, staff.ID); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// newOpaqueToken returns a random bearer token and the hash to store for it.
+func newOpaqueToken(prefix string) (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := prefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken is a plain SHA-256: the tokens are random, so there is nothing for a
+// slow hash to protect, and it lets them be looked up by hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"coffee-shop-pos/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockTerminalRepo struct{ mock.Mock }
+
+func (m *mockTerminalRepo) Create(ctx context.Context, terminal *domain.Terminal) error {
+	args := m.Called(ctx, terminal)
+	return args.Error(0)
+}
+func (m *mockTerminalRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Terminal, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Terminal), args.Error(1)
+}
+func (m *mockTerminalRepo) List(ctx context.Context) ([]domain.Terminal, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Terminal), args.Error(1)
+}
+func (m *mockTerminalRepo) Deactivate(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+func (m *mockTerminalRepo) StartSession(ctx context.Context, session *domain.TerminalSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+func (m *mockTerminalRepo) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*domain.TerminalSession, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TerminalSession), args.Error(1)
+}
+func (m *mockTerminalRepo) TouchSession(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+func (m *mockTerminalRepo) EndSession(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func testTerminalConfig() TerminalConfig {
+	return TerminalConfig{
+		SessionIdleTimeout: 10 * time.Minute,
+		SessionMaxAge:      12 * time.Hour,
+		PINMaxAttempts:     5,
+		PINLockout:         15 * time.Minute,
+	}
+}
+
+func testPINStaff(t *testing.T, pin string) *domain.Staff {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	assert.NoError(t, err)
+	pinHash := string(hash)
+	return &domain.Staff{ID: uuid.New(), Username: "sam", Role: domain.RoleBarista, Active: true, PINHash: &pinHash}
+}
+
+func TestTerminalUsecase_Register_ReturnsTokenOnce(t *testing.T) {
+	terminalRepo := new(mockTerminalRepo)
+	u := NewTerminalUsecase(terminalRepo, new(mockStaffRepo), testTerminalConfig())
+
+	terminalRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Terminal")).Return(nil)
+
+	terminal := &domain.Terminal{Name: " Front counter "}
+	assert.NoError(t, u.Register(managerCtx(), terminal))
+	assert.Equal(t, "Front counter", terminal.Name)
+	assert.Contains(t, terminal.DeviceToken, deviceTokenPrefix)
+	assert.Equal(t, hashToken(terminal.DeviceToken), terminal.TokenHash)
+
+	assert.ErrorIs(t, u.Register(staffCtx(domain.RoleCashier), &domain.Terminal{Name: "Bar"}), domain.ErrForbidden)
+}
+
+func TestTerminalUsecase_PINLogin_StartsSession(t *testing.T) {
+	terminalRepo := new(mockTerminalRepo)
+	staffRepo := new(mockStaffRepo)
+	u := NewTerminalUsecase(terminalRepo, staffRepo, testTerminalConfig())
+	terminal := &domain.Terminal{ID: uuid.New(), Active: true}
+	staff := testPINStaff(t, "4821")
+	staff.PINFailedAttempts = 2
+
+	terminalRepo.On("GetByTokenHash", mock.Anything, hashToken("term_device")).Return(terminal, nil)
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(staff, nil)
+	staffRepo.On("ClaimPINAttempt", mock.Anything, staff.ID, 5, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(false, nil)
+	staffRepo.On("ResetPINFailures", mock.Anything, staff.ID).Return(nil)
+	terminalRepo.On("StartSession", mock.Anything, mock.MatchedBy(func(s *domain.TerminalSession) bool {
+		return s.TerminalID == terminal.ID && s.StaffID == staff.ID
+	})).Return(nil)
+
+	login, err := u.PINLogin(context.Background(), "term_device", "Sam", "4821")
+	assert.NoError(t, err)
+	assert.Contains(t, login.SessionToken, sessionTokenPrefix)
+	assert.Equal(t, terminal.ID, *login.Staff.TerminalID)
+	assert.Equal(t, domain.RoleBarista, login.Staff.Role)
+	staffRepo.AssertExpectations(t)
+	terminalRepo.AssertExpectations(t)
+}
+
+func TestTerminalUsecase_PINLogin_Failures(t *testing.T) {
+	terminalRepo := new(mockTerminalRepo)
+	staffRepo := new(mockStaffRepo)
+	u := NewTerminalUsecase(terminalRepo, staffRepo, testTerminalConfig())
+	terminal := &domain.Terminal{ID: uuid.New(), Active: true}
+	staff := testPINStaff(t, "4821")
+
+	terminalRepo.On("GetByTokenHash", mock.Anything, hashToken("term_unknown")).Return(nil, nil)
+	terminalRepo.On("GetByTokenHash", mock.Anything, hashToken("term_device")).Return(terminal, nil)
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(staff, nil)
+	staffRepo.On("ClaimPINAttempt", mock.Anything, staff.ID, 5, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	staffRepo.On("ClaimPINAttempt", mock.Anything, staff.ID, 5, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	_, err := u.PINLogin(context.Background(), "term_unknown", "sam", "4821")
+	assert.ErrorIs(t, err, domain.ErrInvalidTerminal)
+	_, err = u.PINLogin(context.Background(), "term_device", "sam", "0000")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	// The attempt that reaches the limit locks the PIN.
+	_, err = u.PINLogin(context.Background(), "term_device", "sam", "0000")
+	assert.ErrorIs(t, err, domain.ErrPINLocked)
+
+	// While locked even the right PIN is refused without being checked.
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	staff.PINLockedUntil = &lockedUntil
+	_, err = u.PINLogin(context.Background(), "term_device", "sam", "4821")
+	assert.ErrorIs(t, err, domain.ErrPINLocked)
+	terminalRepo.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything)
+}
+
+// pinLockStaffRepo keeps the attempt counter in memory, claiming attempts the
+// way the repository does, so concurrent guesses can be raced against it.
+type pinLockStaffRepo struct {
+	*mockStaffRepo
+	mu       sync.Mutex
+	attempts int
+	locked   bool
+}
+
+func (r *pinLockStaffRepo) ClaimPINAttempt(ctx context.Context, id uuid.UUID, maxAttempts int, now, lockUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return false, domain.ErrPINLocked
+	}
+	r.attempts++
+	r.locked = r.attempts >= maxAttempts
+	return r.locked, nil
+}
+
+func TestTerminalUsecase_PINLogin_ConcurrentGuesses(t *testing.T) {
+	terminalRepo := new(mockTerminalRepo)
+	staffRepo := &pinLockStaffRepo{mockStaffRepo: new(mockStaffRepo)}
+	u := NewTerminalUsecase(terminalRepo, staffRepo, testTerminalConfig())
+	terminal := &domain.Terminal{ID: uuid.New(), Active: true}
+	staff := testPINStaff(t, "4821")
+
+	terminalRepo.On("GetByTokenHash", mock.Anything, hashToken("term_device")).Return(terminal, nil)
+	// Every guess reads the same unlocked row, as concurrent requests would.
+	staffRepo.On("GetByUsername", mock.Anything, "sam").Return(staff, nil)
+
+	const guesses = 20
+	errs := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := u.PINLogin(context.Background(), "term_device", "sam", "0000")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	invalid, locked := 0, 0
+	for err := range errs {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			invalid++
+		case errors.Is(err, domain.ErrPINLocked):
+			locked++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// Only the allowed attempts are compared; the one reaching the limit and
+	// everything after it is refused as locked.
+	assert.Equal(t, 4, invalid)
+	assert.Equal(t, guesses-4, locked)
+
+	// The right PIN is refused too once the guesses have locked it.
+	_, err := u.PINLogin(context.Background(), "term_device", "sam", "4821")
+	assert.ErrorIs(t, err, domain.ErrPINLocked)
+	terminalRepo.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything)
+}
+
+func TestTerminalUsecase_Authenticate_Expiry(t *testing.T) {
+	terminalRepo := new(mockTerminalRepo)
+	u := NewTerminalUsecase(terminalRepo, new(mockStaffRepo), testTerminalConfig()).(*terminalUsecase)
+	now := time.Now()
+	active := &domain.TerminalSession{
+		ID: uuid.New(), TerminalID: uuid.New(), StaffID: uuid.New(), Username: "sam", Role: domain.RoleCashier,
+		LastSeenAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour), StaffActive: true, TerminalActive: true,
+	}
+	idle := *active
+	idle.ID = uuid.New()
+	idle.LastSeenAt = now.Add(-11 * time.Minute)
+
+	terminalRepo.On("GetSessionByTokenHash", mock.Anything, hashToken("pos_active")).Return(active, nil)
+	terminalRepo.On("GetSessionByTokenHash", mock.Anything, hashToken("pos_idle")).Return(&idle, nil)
+	terminalRepo.On("TouchSession", mock.Anything, active.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	terminalRepo.On("EndSession", mock.Anything, idle.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	identity, err := u.Authenticate(context.Background(), "pos_active")
+	assert.NoError(t, err)
+	assert.Equal(t, active.StaffID, identity.StaffID)
+	assert.Equal(t, active.TerminalID, *identity.TerminalID)
+
+	_, err = u.Authenticate(context.Background(), "pos_idle")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	// JWTs are left to the auth usecase without touching the database.
+	_, err = u.Authenticate(context.Background(), "eyJhbGciOiJIUzI1NiJ9.e30.sig")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	terminalRepo.AssertExpectations(t)
+}
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS ingredients (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    unit VARCHAR(20) NOT NULL,
+    unit_cost DECIMAL(12, 4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0),
+    baseline_quantity DECIMAL(12, 3) NOT NULL DEFAULT 0,
+    baseline_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_items (
+    menu_item_id UUID NOT NULL,
+    ingredient_id UUID NOT NULL,
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (menu_item_id, ingredient_id),
+    CONSTRAINT fk_recipe_items_menu_item FOREIGN KEY (menu_item_id) REFERENCES menu_items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_recipe_items_ingredient FOREIGN KEY (ingredient_id) REFERENCES ingredients(id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY,
+    ingredient_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(12, 4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stock_movements_ingredient FOREIGN KEY (ingredient_id) REFERENCES ingredients(id),
+    CONSTRAINT stock_movements_type_check CHECK (type IN ('receipt', 'waste'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_ingredient_occurred ON stock_movements (ingredient_id, occurred_at);
+
+CREATE TABLE IF NOT EXISTS stock_counts (
+    id UUID PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note TEXT NOT NULL DEFAULT '',
+    counted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT stock_counts_status_check CHECK (status IN ('open', 'applied'))
+);
+
+CREATE TABLE IF NOT EXISTS stock_count_lines (
+    stock_count_id UUID NOT NULL,
+    ingredient_id UUID NOT NULL,
+    counted_quantity DECIMAL(12, 3) NOT NULL CHECK (counted_quantity >= 0),
+    PRIMARY KEY (stock_count_id, ingredient_id),
+    CONSTRAINT fk_stock_count_lines_count FOREIGN KEY (stock_count_id) REFERENCES stock_counts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_count_lines_ingredient FOREIGN KEY (ingredient_id) REFERENCES ingredients(id)
+);
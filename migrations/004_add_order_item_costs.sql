@@ -0,0 +1 @@
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_cost DECIMAL(12, 4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0);
@@ -0,0 +1,18 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS redeemed_points BIGINT NOT NULL DEFAULT 0 CHECK (redeemed_points >= 0);
+
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id UUID PRIMARY KEY,
+    customer_id UUID NOT NULL,
+    order_id UUID,
+    type VARCHAR(20) NOT NULL,
+    points BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_loyalty_ledger_customer FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE,
+    CONSTRAINT loyalty_ledger_type_check CHECK (type IN ('earn', 'redeem', 'reversal'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_customer ON loyalty_ledger (customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_order ON loyalty_ledger (order_id);
+-- An order earns, redeems and is reversed at most once.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_order_type ON loyalty_ledger (order_id, type) WHERE order_id IS NOT NULL;
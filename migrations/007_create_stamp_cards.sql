@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS stamp_programs (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    categories TEXT[] NOT NULL,
+    stamps_required INTEGER NOT NULL CHECK (stamps_required > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS stamp_ledger (
+    id UUID PRIMARY KEY,
+    program_id UUID NOT NULL,
+    customer_id UUID NOT NULL,
+    order_id UUID,
+    type VARCHAR(20) NOT NULL,
+    stamps INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stamp_ledger_program FOREIGN KEY (program_id) REFERENCES stamp_programs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stamp_ledger_customer FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE,
+    CONSTRAINT stamp_ledger_type_check CHECK (type IN ('stamp', 'redeem', 'reversal'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_stamp_ledger_customer ON stamp_ledger (customer_id, program_id);
+CREATE INDEX IF NOT EXISTS idx_stamp_ledger_order ON stamp_ledger (order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stamp_ledger_order_type ON stamp_ledger (program_id, order_id, type) WHERE order_id IS NOT NULL;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS stamp_program_id UUID REFERENCES stamp_programs(id) ON DELETE SET NULL;
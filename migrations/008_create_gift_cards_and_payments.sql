@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id UUID PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    initial_value DECIMAL(10, 2) NOT NULL CHECK (initial_value > 0),
+    balance DECIMAL(10, 2) NOT NULL CHECK (balance >= 0),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    issued_order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_cards_issued_order ON gift_cards (issued_order_id);
+
+-- Ledger rows are never updated or deleted; the card balance always equals
+-- the sum of its transactions.
+CREATE TABLE IF NOT EXISTS gift_card_transactions (
+    id UUID PRIMARY KEY,
+    gift_card_id UUID NOT NULL REFERENCES gift_cards(id) ON DELETE RESTRICT,
+    order_id UUID,
+    type VARCHAR(20) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    balance_after DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT gift_card_transactions_type_check CHECK (type IN ('issue', 'reload', 'redeem', 'refund', 'void'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_card ON gift_card_transactions (gift_card_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_order ON gift_card_transactions (order_id);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    tender VARCHAR(20) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    gift_card_id UUID REFERENCES gift_cards(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payments_tender_check CHECK (tender IN ('cash', 'card', 'gift_card'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order ON payments (order_id);
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS gift_card_code VARCHAR(32) NOT NULL DEFAULT '';
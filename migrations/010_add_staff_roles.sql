@@ -0,0 +1,4 @@
+-- Accounts created before roles existed could do everything, so they become
+-- managers; new accounts default to the least privileged role.
+ALTER TABLE staff ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'manager';
+ALTER TABLE staff ALTER COLUMN role SET DEFAULT 'cashier';
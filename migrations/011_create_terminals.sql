@@ -0,0 +1,32 @@
+ALTER TABLE staff ADD COLUMN IF NOT EXISTS pin_hash VARCHAR(255);
+ALTER TABLE staff ADD COLUMN IF NOT EXISTS pin_failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE staff ADD COLUMN IF NOT EXISTS pin_locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS terminals (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS terminal_sessions (
+    id UUID PRIMARY KEY,
+    terminal_id UUID NOT NULL REFERENCES terminals(id),
+    staff_id UUID NOT NULL REFERENCES staff(id),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one open session per terminal.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_terminal_sessions_open
+    ON terminal_sessions(terminal_id) WHERE ended_at IS NULL;
+
+-- Orders record who rang them up and where.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cashier_id UUID REFERENCES staff(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS terminal_id UUID REFERENCES terminals(id);
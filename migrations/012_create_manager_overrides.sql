@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS approval_tokens (
+    id UUID PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    manager_id UUID NOT NULL REFERENCES staff(id),
+    action VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS approvals (
+    id UUID PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    actor_id UUID REFERENCES staff(id),
+    approver_id UUID NOT NULL REFERENCES staff(id),
+    method VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_approvals_entity ON approvals(entity_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_action_created ON approvals(action, created_at);
+
+-- Discount typed in by staff, on top of any loyalty redemption.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS manual_discount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (manual_discount >= 0);
@@ -0,0 +1,4 @@
+-- Bumped on every write so clients can make changes conditional on the
+-- version they read (ETag / If-Match).
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
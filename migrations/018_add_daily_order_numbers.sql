@@ -0,0 +1,21 @@
+-- Order numbers restart every business day per store (A-001, A-002, ...).
+-- The reference stays unique across all days and stores.
+CREATE TABLE IF NOT EXISTS order_number_counters (
+    store_code VARCHAR(10) NOT NULL,
+    business_date DATE NOT NULL,
+    last_number INT NOT NULL,
+    PRIMARY KEY (store_code, business_date)
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS reference VARCHAR(50);
+UPDATE orders SET reference = order_number WHERE reference IS NULL;
+ALTER TABLE orders ALTER COLUMN reference SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_reference ON orders(reference);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS store_code VARCHAR(10) NOT NULL DEFAULT 'A';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS business_date DATE;
+UPDATE orders SET business_date = created_at::date WHERE business_date IS NULL;
+ALTER TABLE orders ALTER COLUMN business_date SET NOT NULL;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_order_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_daily_number ON orders(store_code, business_date, order_number);
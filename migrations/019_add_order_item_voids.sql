@@ -0,0 +1,21 @@
+-- A voided line stays on the order but no longer counts towards its totals,
+-- stock usage or sales.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS void_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS voided_by UUID REFERENCES staff(id);
+
+-- Money given back against a payment. Gift card refunds are also credited to
+-- the card's ledger.
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES order_items(id) ON DELETE SET NULL,
+    tender VARCHAR(20) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    gift_card_id UUID REFERENCES gift_cards(id) ON DELETE RESTRICT,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds (order_id, created_at);
@@ -0,0 +1,9 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS order_type VARCHAR(20) NOT NULL DEFAULT 'takeaway';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS table_number VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS call_out_name VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS service_charge DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (service_charge >= 0);
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_order_type_check;
+ALTER TABLE orders ADD CONSTRAINT orders_order_type_check CHECK (order_type IN ('dine_in', 'takeaway', 'delivery'));
+
+CREATE INDEX IF NOT EXISTS idx_orders_order_type ON orders (order_type, created_at);
@@ -0,0 +1,6 @@
+-- An open tab is a pending order parked under a name or table that keeps
+-- taking rounds until it is paid.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tab_name VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tab_opened_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_orders_open_tabs ON orders (tab_opened_at) WHERE tab_opened_at IS NOT NULL AND status = 'pending';
@@ -0,0 +1,12 @@
+-- A bill split into separate orders is closed as 'split'; the orders paid
+-- instead point back at it.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS parent_order_id UUID REFERENCES orders(id) ON DELETE RESTRICT;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'cancelled', 'completed', 'split'));
+
+CREATE INDEX IF NOT EXISTS idx_orders_parent_order ON orders (parent_order_id) WHERE parent_order_id IS NOT NULL;
+
+-- The part of a line's quantity an order pays for when one item is shared
+-- between split orders. NULL means the whole line.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS share DECIMAL(7, 6) CHECK (share > 0 AND share <= 1);
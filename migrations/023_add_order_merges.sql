@@ -0,0 +1,8 @@
+-- Orders merged into another one are closed as 'merged' and point at the
+-- order that took over their lines.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES orders(id) ON DELETE RESTRICT;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'cancelled', 'completed', 'split', 'merged'));
+
+CREATE INDEX IF NOT EXISTS idx_orders_merged_into ON orders (merged_into_id) WHERE merged_into_id IS NOT NULL;
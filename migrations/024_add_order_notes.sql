@@ -0,0 +1,4 @@
+-- Free-text special instructions ("extra hot", "no lid") for the whole order
+-- and for each line.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS note VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS note VARCHAR(200) NOT NULL DEFAULT '';
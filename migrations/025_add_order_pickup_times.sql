@@ -0,0 +1,7 @@
+-- Pre-orders are picked up at pickup_at and held out of the preparation
+-- queue until released_at, a lead time before pickup.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS pickup_at TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS released_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_orders_pickup_at ON orders (pickup_at) WHERE pickup_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_held ON orders (pickup_at) WHERE pickup_at IS NOT NULL AND released_at IS NULL;
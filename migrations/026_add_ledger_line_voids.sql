@@ -0,0 +1,15 @@
+-- Voiding a line takes back the points and stamps it earned. A line is voided
+-- at most once, so void entries are unique per line rather than per order.
+ALTER TABLE loyalty_ledger ADD COLUMN IF NOT EXISTS order_item_id UUID;
+ALTER TABLE loyalty_ledger DROP CONSTRAINT IF EXISTS loyalty_ledger_type_check;
+ALTER TABLE loyalty_ledger ADD CONSTRAINT loyalty_ledger_type_check CHECK (type IN ('earn', 'redeem', 'reversal', 'void'));
+DROP INDEX IF EXISTS idx_loyalty_ledger_order_type;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_order_type ON loyalty_ledger (order_id, type) WHERE order_id IS NOT NULL AND type <> 'void';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_order_item ON loyalty_ledger (order_item_id) WHERE type = 'void';
+
+ALTER TABLE stamp_ledger ADD COLUMN IF NOT EXISTS order_item_id UUID;
+ALTER TABLE stamp_ledger DROP CONSTRAINT IF EXISTS stamp_ledger_type_check;
+ALTER TABLE stamp_ledger ADD CONSTRAINT stamp_ledger_type_check CHECK (type IN ('stamp', 'redeem', 'reversal', 'void'));
+DROP INDEX IF EXISTS idx_stamp_ledger_order_type;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stamp_ledger_order_type ON stamp_ledger (program_id, order_id, type) WHERE order_id IS NOT NULL AND type <> 'void';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stamp_ledger_order_item ON stamp_ledger (program_id, order_item_id) WHERE type = 'void';
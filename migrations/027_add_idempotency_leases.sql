@@ -0,0 +1,6 @@
+-- A request in progress holds its key only until locked_until, after which a
+-- retry may take it over under a new lease_id. headers keeps the response
+-- headers replayed with the stored body.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS lease_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT 'epoch';
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS headers JSONB;